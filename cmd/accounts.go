@@ -3,11 +3,14 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/julienpequegnot/ghmon/internal/account"
 	"github.com/julienpequegnot/ghmon/internal/config"
 	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -18,8 +21,11 @@ var accountsCmd = &cobra.Command{
 	RunE:  runAccounts,
 }
 
+var accountsTags []string
+
 func init() {
 	rootCmd.AddCommand(accountsCmd)
+	accountsCmd.Flags().StringSliceVar(&accountsTags, "tag", nil, "Only list accounts with any of these tags")
 }
 
 func runAccounts(cmd *cobra.Command, args []string) error {
@@ -30,9 +36,32 @@ func runAccounts(cmd *cobra.Command, args []string) error {
 	defer db.Close()
 
 	accountRepo := account.NewRepository(db)
-	accounts, err := accountRepo.List()
+
+	var accounts []account.Account
+	if len(accountsTags) > 0 {
+		accounts, err = taggedAccounts(accountRepo, accountsTags)
+		if err != nil {
+			return err
+		}
+	} else {
+		accounts, err = accountRepo.List()
+		if err != nil {
+			return fmt.Errorf("failed to list accounts: %w", err)
+		}
+	}
+
+	return printAccounts(accountRepo, accounts)
+}
+
+// printAccounts lists accounts with their tags
+func printAccounts(accountRepo *account.Repository, accounts []account.Account) error {
+	tags, err := accountRepo.TagsByAccount()
 	if err != nil {
-		return fmt.Errorf("failed to list accounts: %w", err)
+		return fmt.Errorf("failed to load tags: %w", err)
+	}
+
+	if structuredOutput() {
+		return writeOutput(report.BuildAccounts(accounts, tags))
 	}
 
 	if len(accounts) == 0 {
@@ -43,6 +72,7 @@ func runAccounts(cmd *cobra.Command, args []string) error {
 
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
 	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	tagStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
 	fmt.Printf("\n%s (%d)\n\n", titleStyle.Render("MONITORED ACCOUNTS"), len(accounts))
@@ -56,6 +86,15 @@ func runAccounts(cmd *cobra.Command, args []string) error {
 		if acc.Name != "" && acc.Name != acc.Username {
 			fmt.Printf(" %s", dimStyle.Render("("+acc.Name+")"))
 		}
+		if acc.IsOrganization() {
+			fmt.Printf(" %s", dimStyle.Render("[org]"))
+		}
+		if acc.IsMuted(time.Now()) {
+			fmt.Printf(" %s", dimStyle.Render("[muted "+mutedUntil(*acc.MutedUntil)+"]"))
+		}
+		if len(tags[acc.ID]) > 0 {
+			fmt.Printf(" %s", tagStyle.Render("["+strings.Join(tags[acc.ID], ", ")+"]"))
+		}
 		fmt.Println()
 
 		if acc.Bio != "" {
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/github"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var accountsRefreshCmd = &cobra.Command{
+	Use:   "refresh [user...]",
+	Short: "Update account profiles from GitHub",
+	Long: `Pulls each account's GitHub profile again and updates its name, avatar, bio,
+follower and following counts, and type. Without usernames every monitored
+account is refreshed, one API call each.`,
+	RunE: runAccountsRefresh,
+}
+
+var accountsRefreshTags []string
+
+func init() {
+	accountsCmd.AddCommand(accountsRefreshCmd)
+	accountsRefreshCmd.Flags().StringSliceVar(&accountsRefreshTags, "tag", nil, "Only refresh accounts with any of these tags")
+}
+
+func runAccountsRefresh(cmd *cobra.Command, args []string) error {
+	client, err := importClient()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+
+	var accounts []account.Account
+	switch {
+	case len(args) > 0:
+		for _, username := range args {
+			acc, err := accountRepo.Get(username)
+			if err != nil || acc.RemovedAt != nil {
+				return fmt.Errorf("account '%s' is not being monitored", username)
+			}
+			accounts = append(accounts, *acc)
+		}
+	case len(accountsRefreshTags) > 0:
+		accounts, err = taggedAccounts(accountRepo, accountsRefreshTags)
+		if err != nil {
+			return err
+		}
+	default:
+		accounts, err = accountRepo.List()
+		if err != nil {
+			return fmt.Errorf("failed to list accounts: %w", err)
+		}
+	}
+
+	if len(accounts) == 0 {
+		notice("No accounts to refresh.")
+		return nil
+	}
+
+	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	if !structuredOutput() {
+		fmt.Printf("Refreshing %d accounts...\n\n", len(accounts))
+	}
+
+	results := make([]report.ProfileRefresh, 0, len(accounts))
+	updated, failed := 0, 0
+	for _, acc := range accounts {
+		client.WaitForRateLimit()
+
+		result := report.ProfileRefresh{Username: acc.Username, Changes: []report.ProfileChange{}}
+		user, err := client.GetUser(acc.Username)
+		if err == nil {
+			profile := profileFromUser(user)
+			changes := acc.Profile().Diff(profile)
+			if len(changes) > 0 {
+				err = accountRepo.UpdateProfile(acc.ID, profile)
+			}
+			if err == nil {
+				result = report.BuildProfileRefresh(acc.Username, changes)
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		} else if len(result.Changes) > 0 {
+			updated++
+		}
+		results = append(results, result)
+
+		if structuredOutput() {
+			continue
+		}
+		switch {
+		case result.Error != "":
+			fmt.Printf("  %s %s\n", userStyle.Render(acc.Username), errorStyle.Render("failed: "+result.Error))
+		case len(result.Changes) == 0:
+			fmt.Printf("  %s %s\n", userStyle.Render(acc.Username), dimStyle.Render("unchanged"))
+		default:
+			fmt.Printf("  %s\n", userStyle.Render(acc.Username))
+			for _, c := range result.Changes {
+				fmt.Printf("    %s\n", describeProfileChange(c))
+			}
+		}
+	}
+
+	if structuredOutput() {
+		if err := writeOutput(results); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("\nUpdated %d of %d accounts.\n", updated, len(accounts))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to refresh %d accounts", failed)
+	}
+	return nil
+}
+
+// profileFromUser is the part of a GitHub user ghmon stores
+func profileFromUser(user *github.User) account.Profile {
+	return account.Profile{
+		Type:      user.Type,
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+		Bio:       user.Bio,
+		Followers: user.Followers,
+		Following: user.Following,
+	}
+}
+
+// describeProfileChange renders a change as 'field: from → to', leaving out
+// values too long to read on one line
+func describeProfileChange(c report.ProfileChange) string {
+	if c.Field == "avatar_url" {
+		return "avatar changed"
+	}
+	value := func(s string) string {
+		if s == "" {
+			return "(empty)"
+		}
+		s = strings.Join(strings.Fields(s), " ")
+		if len(s) > 40 {
+			s = s[:37] + "..."
+		}
+		return `"` + s + `"`
+	}
+	switch c.Field {
+	case "followers", "following", "type":
+		return fmt.Sprintf("%s: %s → %s", c.Field, c.From, c.To)
+	}
+	return fmt.Sprintf("%s: %s → %s", c.Field, value(c.From), value(c.To))
+}
@@ -39,11 +39,20 @@ func runAdd(cmd *cobra.Command, args []string) error {
 
 	accountRepo := account.NewRepository(db)
 
-	if accountRepo.Exists(username) {
-		return fmt.Errorf("account '%s' is already being monitored", username)
+	if existing, err := accountRepo.Get(username); err == nil {
+		if existing.RemovedAt == nil {
+			return fmt.Errorf("account '%s' is already being monitored", username)
+		}
+		// Removed by 'sync --prune --keep-history'; pick up where it left off
+		if err := accountRepo.Reactivate(existing.ID, account.SourceManual); err != nil {
+			return fmt.Errorf("failed to add account: %w", err)
+		}
+		fmt.Printf("Resumed monitoring %s; their earlier activity was kept.\n", username)
+		return nil
 	}
 
 	var name, avatarURL, bio string
+	accountType := account.TypeUser
 	if cfg.GitHub.Token != "" {
 		client := github.NewClient(cfg.GitHub.Token)
 		user, err := client.GetUser(username)
@@ -53,15 +62,29 @@ func runAdd(cmd *cobra.Command, args []string) error {
 			name = user.Name
 			avatarURL = user.AvatarURL
 			bio = user.Bio
+			if user.Type == account.TypeOrganization {
+				accountType = account.TypeOrganization
+			}
 		}
 	}
 
-	_, err = accountRepo.Add(username, name, avatarURL, bio)
+	err = db.WithTx(func(tx *database.Tx) error {
+		accountRepo := accountRepo.WithTx(tx)
+		acc, err := accountRepo.Add(username, name, avatarURL, bio)
+		if err != nil {
+			return err
+		}
+		return accountRepo.SetType(acc.ID, accountType)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to add account: %w", err)
 	}
 
-	fmt.Printf("Added %s to monitored accounts.\n", username)
+	if accountType == account.TypeOrganization {
+		fmt.Printf("Added organization %s to monitored accounts.\n", username)
+	} else {
+		fmt.Printf("Added %s to monitored accounts.\n", username)
+	}
 	fmt.Println("Run 'ghmon fetch' to pull their activity.")
 
 	return nil
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/archive"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Export or import a portable copy of the database",
+	Long: `Moves ghmon data between machines or shares a dataset with teammates.
+
+An archive is a tar.gz of versioned JSONL files: accounts, commits, repos,
+stars and digests. Records refer to accounts by username, so an archive can
+be merged into any database.`,
+}
+
+var archiveExportCmd = &cobra.Command{
+	Use:   "export <file.tar.gz>",
+	Short: "Write all accounts and activity to an archive",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runArchiveExport,
+}
+
+var archiveImportCmd = &cobra.Command{
+	Use:   "import <file.tar.gz>",
+	Short: "Merge an archive into the database",
+	Long: `Merges an archive into the database. Records that already exist are
+skipped, so importing the same archive twice is harmless.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runArchiveImport,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archiveExportCmd)
+	archiveCmd.AddCommand(archiveImportCmd)
+}
+
+func runArchiveExport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// Write to a temporary file so a failed export never leaves a truncated archive
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	manifest, err := archive.Export(db, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to export archive: %w", err)
+	}
+
+	fmt.Printf("Exported to %s (archive v%d, schema v%d)\n", path, manifest.Version, manifest.SchemaVersion)
+	for _, table := range archive.Tables() {
+		fmt.Printf("  %-10s %d\n", table, manifest.Counts[table])
+	}
+	return nil
+}
+
+func runArchiveImport(cmd *cobra.Command, args []string) error {
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	result, err := archive.Import(db, file)
+	if err != nil {
+		return fmt.Errorf("failed to import archive: %w", err)
+	}
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("Imported %s %s\n", args[0],
+		dimStyle.Render(fmt.Sprintf("(archive v%d, created %s)", result.Manifest.Version, result.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"))))
+	for _, t := range result.Tables {
+		fmt.Printf("  %-10s %d new %s\n", t.Table, t.Inserted,
+			dimStyle.Render(fmt.Sprintf("(%d already present)", t.Read-t.Inserted)))
+	}
+	return nil
+}
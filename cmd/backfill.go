@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+	"github.com/julienpequegnot/ghmon/internal/backfill"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill [username...]",
+	Short: "Pull historical activity older than the events API allows",
+	Long: `Backfills repositories, stars and commits since a given date.
+
+The events API only covers the last 90 days (and at most 300 events), so
+backfill walks each account's repository list, starred list and the commits
+of their own repositories instead. Progress is recorded per account, so an
+interrupted backfill picks up where it left off when run again with the
+same --since date.`,
+	SilenceUsage: true,
+	RunE:         runBackfill,
+}
+
+var (
+	backfillSince   string
+	backfillRestart bool
+)
+
+func init() {
+	rootCmd.AddCommand(backfillCmd)
+	backfillCmd.Flags().StringVar(&backfillSince, "since", "", "Backfill activity since this date (YYYY-MM-DD)")
+	backfillCmd.Flags().BoolVar(&backfillRestart, "restart", false, "Ignore recorded progress and start over")
+	backfillCmd.MarkFlagRequired("since")
+}
+
+func runBackfill(cmd *cobra.Command, args []string) error {
+	since, err := time.ParseInLocation(backfill.SinceFormat, backfillSince, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid --since date '%s' (expected YYYY-MM-DD)", backfillSince)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.GitHub.Token == "" {
+		return fmt.Errorf("GitHub token not set. Add your token to %s", config.ConfigPath())
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+	accounts, err := accountRepo.ListByStaleness()
+	if err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	accounts, err = selectAccounts(accounts, args, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(accounts) == 0 {
+		fmt.Println("No accounts to backfill. Run 'ghmon sync' or 'ghmon add <username>' first.")
+		return nil
+	}
+
+	// Backfill spends the same rate limit as fetch, so they don't run at once
+	lock, err := acquireFetchLock()
+	if err != nil {
+		return err
+	}
+	defer releaseFetchLock(lock)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	b := &backfiller{
+		db:         db,
+		client:     github.NewClient(cfg.GitHub.Token),
+		progress:   backfill.NewProgress(db),
+		commitRepo: activity.NewCommitRepository(db),
+		repoRepo:   activity.NewRepoRepository(db),
+		starRepo:   activity.NewStarRepository(db),
+		since:      since,
+	}
+
+	fmt.Printf("Backfilling %d accounts since %s...\n\n", len(accounts), since.Format("Jan 2, 2006"))
+
+	failed := 0
+	for _, acc := range accounts {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if backfillRestart {
+			if err := b.progress.Reset(acc.ID, since); err != nil {
+				return fmt.Errorf("failed to reset backfill progress for %s: %w", acc.Username, err)
+			}
+		}
+
+		commits, repos, stars, err := b.account(ctx, &acc)
+		fmt.Printf("  %s: %d commits, %d repos, %d stars\n", acc.Username, commits, repos, stars)
+		if err != nil {
+			failed++
+			fmt.Printf("    error: %v\n", err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("backfill interrupted; run the same command again to resume")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("backfill failed for %d of %d accounts; run again to retry", failed, len(accounts))
+	}
+
+	fmt.Println("\nBackfill complete.")
+	return nil
+}
+
+// backfiller pulls historical data for one account at a time, skipping
+// tasks that a previous run already completed
+type backfiller struct {
+	db         *database.DB
+	client     *github.Client
+	progress   *backfill.Progress
+	commitRepo *activity.CommitRepository
+	repoRepo   *activity.RepoRepository
+	starRepo   *activity.StarRepository
+	since      time.Time
+}
+
+func (b *backfiller) account(ctx context.Context, acc *account.Account) (commits, repos, stars int, err error) {
+	// The repo list is always re-read: it is cheap and drives the commit tasks
+	userRepos, err := b.client.GetAllUserRepos(acc.Username)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("repos: %w", err)
+	}
+	err = b.db.WithTx(func(tx *database.Tx) error {
+		repos = 0
+		repoRepo := b.repoRepo.WithTx(tx)
+		for _, repo := range userRepos {
+			if repo.CreatedAt.Before(b.since) {
+				continue
+			}
+			inserted, err := repoRepo.Add(acc.ID, repo.Name, repo.FullName, repo.Description, repo.Language, repo.Stars, repo.CreatedAt)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				repos++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("saving repos: %w", err)
+	}
+
+	if !acc.IsOrganization() && !b.progress.IsDone(acc.ID, b.since, backfill.StarsTask) {
+		starred, err := b.client.GetUserStarredSince(acc.Username, b.since)
+		if err != nil {
+			return commits, repos, stars, fmt.Errorf("stars: %w", err)
+		}
+		err = b.db.WithTx(func(tx *database.Tx) error {
+			stars = 0
+			starRepo := b.starRepo.WithTx(tx)
+			for _, star := range starred {
+				inserted, err := starRepo.Add(acc.ID, star.FullName, star.Description, star.Language, star.Stars, star.StarredAt)
+				if err != nil {
+					return err
+				}
+				if inserted {
+					stars++
+				}
+			}
+			return b.progress.WithTx(tx).MarkDone(acc.ID, b.since, backfill.StarsTask, stars)
+		})
+		if err != nil {
+			return commits, repos, 0, fmt.Errorf("saving stars: %w", err)
+		}
+	}
+
+	for _, repo := range userRepos {
+		if ctx.Err() != nil {
+			return commits, repos, stars, nil
+		}
+		// Forks mostly carry upstream history; untouched repos have nothing new
+		if repo.Fork || repo.PushedAt.Before(b.since) {
+			continue
+		}
+
+		task := backfill.CommitsTask + repo.FullName
+		if b.progress.IsDone(acc.ID, b.since, task) {
+			continue
+		}
+
+		repoCommits, err := b.client.GetRepoCommits(repo.FullName, acc.Username, b.since)
+		if err != nil && !github.IsStatus(err, http.StatusConflict) {
+			// 409 means the repository is empty
+			return commits, repos, stars, fmt.Errorf("commits for %s: %w", repo.FullName, err)
+		}
+
+		// Commits and the task marker are written together, so a resumed
+		// backfill never skips a repository whose commits were not saved
+		added := 0
+		err = b.db.WithTx(func(tx *database.Tx) error {
+			added = 0
+			commitRepo := b.commitRepo.WithTx(tx)
+			for _, c := range repoCommits {
+				author := analysis.CommitAuthor{Name: c.Commit.Author.Name, Email: c.Commit.Author.Email}
+				inserted, err := commitRepo.AddAuthored(acc.ID, repo.FullName, c.SHA, c.Commit.Message, author, c.Commit.Author.Date)
+				if err != nil {
+					return err
+				}
+				if inserted {
+					added++
+				}
+			}
+			return b.progress.WithTx(tx).MarkDone(acc.ID, b.since, task, added)
+		})
+		if err != nil {
+			return commits, repos, stars, fmt.Errorf("saving commits for %s: %w", repo.FullName, err)
+		}
+		commits += added
+	}
+
+	return commits, repos, stars, nil
+}
@@ -2,60 +2,325 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/fetchrun"
+	"github.com/julienpequegnot/ghmon/internal/github"
+	"github.com/julienpequegnot/ghmon/internal/retention"
+	"github.com/julienpequegnot/ghmon/internal/schedule"
+	"github.com/julienpequegnot/ghmon/internal/watch"
 	"github.com/spf13/cobra"
 )
 
 var daemonCmd = &cobra.Command{
 	Use:   "daemon",
 	Short: "Run in daemon mode with scheduled fetching",
-	Long:  `Runs ghmon in the background, fetching activity on a schedule.`,
-	RunE:  runDaemon,
+	Long: `Runs ghmon in the background, fetching activity on an adaptive schedule.
+
+Each account gets its own next-due time based on its recent activity: busy
+accounts are fetched every --min-interval, dormant accounts every
+--max-interval. Fetches are spread across the GitHub rate-limit window
+instead of bursting all at once. Watched repositories are fetched every
+--min-interval, after the due accounts.`,
+	RunE: runDaemon,
 }
 
 var (
-	daemonInterval int
+	daemonInterval    int
+	daemonMinInterval time.Duration
+	daemonMaxInterval time.Duration
 )
 
+// daemonPruneInterval is how often the daemon applies the retention policy
+const daemonPruneInterval = 24 * time.Hour
+
+// daemonMaxSleep bounds how long the daemon sleeps between scheduling passes,
+// so newly added accounts are picked up promptly
+const daemonMaxSleep = 15 * time.Minute
+
 func init() {
 	rootCmd.AddCommand(daemonCmd)
-	daemonCmd.Flags().IntVar(&daemonInterval, "interval", 60, "Fetch interval in minutes")
+	daemonCmd.Flags().IntVar(&daemonInterval, "interval", 0, "Fetch interval in minutes")
+	daemonCmd.Flags().MarkDeprecated("interval", "scheduling is now adaptive; it sets --min-interval, use that and --max-interval instead")
+	daemonCmd.Flags().DurationVar(&daemonMinInterval, "min-interval", 30*time.Minute, "Fetch interval for the most active accounts")
+	daemonCmd.Flags().DurationVar(&daemonMaxInterval, "max-interval", 24*time.Hour, "Fetch interval for dormant accounts")
 }
 
 func runDaemon(cmd *cobra.Command, args []string) error {
-	interval := time.Duration(daemonInterval) * time.Minute
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
-	fmt.Printf("Starting daemon mode (fetch every %v)\n", interval)
-	fmt.Println("Press Ctrl+C to stop")
+	if cfg.GitHub.Token == "" {
+		return fmt.Errorf("GitHub token not set. Add your token to %s", config.ConfigPath())
+	}
 
-	// Run initial fetch
-	fmt.Println("\nRunning initial fetch...")
-	if err := runFetch(cmd, args); err != nil {
-		fmt.Printf("Initial fetch error: %v\n", err)
+	policy := schedule.DefaultPolicy()
+	policy.MinInterval = daemonMinInterval
+	policy.MaxInterval = daemonMaxInterval
+	if cmd.Flags().Changed("interval") {
+		// The old fixed interval is the closest thing to the busiest accounts' one
+		if cmd.Flags().Changed("min-interval") {
+			return fmt.Errorf("--interval is deprecated; use --min-interval alone")
+		}
+		if daemonInterval <= 0 {
+			return fmt.Errorf("--interval must be a positive number of minutes")
+		}
+		policy.MinInterval = time.Duration(daemonInterval) * time.Minute
+	}
+	if policy.MinInterval > policy.MaxInterval {
+		return fmt.Errorf("--min-interval (%v) must not exceed --max-interval (%v)", policy.MinInterval, policy.MaxInterval)
 	}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	fmt.Printf("Starting daemon mode (busy accounts every %v, dormant every %v)\n", policy.MinInterval, policy.MaxInterval)
+	fmt.Println("Press Ctrl+C to stop")
 
 	// Handle graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// One client for the daemon's lifetime so rate limit state carries over
+	client := github.NewClient(cfg.GitHub.Token)
+	// Failed accounts and watched repositories keep their old last_fetched;
+	// back them off in memory
+	retryAfter := make(map[int64]time.Time)
+	watchedRetryAfter := make(map[int64]time.Time)
+	var lastPrune time.Time
 
 	for {
-		select {
-		case <-ticker.C:
-			fmt.Printf("\n[%s] Running scheduled fetch...\n", time.Now().Format("15:04:05"))
-			if err := runFetch(cmd, args); err != nil {
-				fmt.Printf("Fetch error: %v\n", err)
+		next, err := runScheduledFetch(ctx, cfg, client, policy, retryAfter, watchedRetryAfter)
+		if err != nil {
+			fmt.Printf("Fetch error: %v\n", err)
+		}
+
+		if cfg.Retention.Enabled() && time.Since(lastPrune) >= daemonPruneInterval {
+			if err := runDaemonPrune(cfg); err != nil {
+				fmt.Printf("Prune error: %v\n", err)
 			}
-		case <-sigChan:
+			lastPrune = time.Now()
+		}
+
+		wait := daemonMaxSleep
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		if wait < time.Minute {
+			wait = time.Minute
+		}
+		if wait > daemonMaxSleep {
+			wait = daemonMaxSleep
+		}
+
+		select {
+		case <-ctx.Done():
 			fmt.Println("\nShutting down daemon...")
 			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runScheduledFetch fetches every account and watched repository that is
+// currently due and returns when the next one will become due
+func runScheduledFetch(
+	ctx context.Context,
+	cfg *config.Config,
+	client *github.Client,
+	policy schedule.Policy,
+	retryAfter map[int64]time.Time,
+	watchedRetryAfter map[int64]time.Time,
+) (time.Time, error) {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	entries, err := planSchedule(db, policy)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	var due []account.Account
+	for _, e := range schedule.Due(entries, now) {
+		if until, ok := retryAfter[e.Account.ID]; ok && now.Before(until) {
+			continue
 		}
+		due = append(due, e.Account)
+	}
+
+	watched, err := watch.NewRepository(db).List()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to list watched repositories: %w", err)
+	}
+	dueWatched, nextWatched := watchedDue(watched, policy.MinInterval, watchedRetryAfter, now)
+
+	if len(due) == 0 && len(dueWatched) == 0 {
+		return earliest(nextDue(entries, retryAfter), nextWatched), nil
+	}
+
+	lock, err := acquireFetchLock()
+	if err != nil {
+		// Most likely a manual fetch; try again shortly
+		fmt.Printf("[%s] Skipping scheduled fetch: %v\n", now.Format("15:04:05"), err)
+		return now.Add(time.Minute), nil
 	}
+	defer releaseFetchLock(lock)
+	classifyPending(db)
+
+	f := newFetcher(db, client, fetchEndpoints{events: true, repos: true, stars: true}, cfg.Fetch.Concurrency)
+
+	if len(due) > 0 {
+		fmt.Printf("\n[%s] Fetching %d due accounts...\n", now.Format("15:04:05"), len(due))
+
+		first := true
+		f.pace = func() bool {
+			wait := time.Duration(0)
+			if !first {
+				rl := client.RateLimit()
+				wait = schedule.Spacing(rl.Remaining, rl.Reset, requestsPerAccount, time.Now())
+			}
+			first = false
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(wait):
+				return true
+			}
+		}
+		f.onResult = func(worker int, result *fetchrun.AccountResult) {
+			if result.Failed() {
+				retryAfter[result.AccountID] = time.Now().Add(policy.MinInterval)
+			} else {
+				delete(retryAfter, result.AccountID)
+			}
+		}
+
+		run, err := f.run(due)
+		if err != nil {
+			return time.Time{}, err
+		}
+		fmt.Printf("[%s] Fetched %d new commits, %d new repos, %d new stars (%d failed)\n",
+			time.Now().Format("15:04:05"), f.summary.commits, f.summary.repos, f.summary.stars, run.AccountsFailed)
+
+		// Re-plan with the updated last_fetched times
+		if entries, err = planSchedule(db, policy); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	if len(dueWatched) > 0 && ctx.Err() == nil {
+		fmt.Printf("\n[%s] Fetching %d watched repositories...\n", time.Now().Format("15:04:05"), len(dueWatched))
+
+		failed := 0
+		retryAt := time.Now().Add(policy.MinInterval)
+		for i, result := range f.runWatched(dueWatched) {
+			if result.Failed() {
+				watchedRetryAfter[dueWatched[i].ID] = retryAt
+				failed++
+			} else {
+				delete(watchedRetryAfter, dueWatched[i].ID)
+			}
+		}
+		fmt.Printf("[%s] Fetched %d new events in watched repositories (%d failed)\n",
+			time.Now().Format("15:04:05"), f.summary.watched, failed)
+		nextWatched = earliest(nextWatched, retryAt)
+	}
+
+	return earliest(nextDue(entries, retryAfter), nextWatched), nil
+}
+
+// nextDue is when the next account becomes due, counting failed accounts'
+// retry times
+func nextDue(entries []schedule.Entry, retryAfter map[int64]time.Time) time.Time {
+	next := schedule.NextWake(entries, time.Now())
+	for _, until := range retryAfter {
+		next = earliest(next, until)
+	}
+	return next
+}
+
+// watchedDue returns the watched repositories not fetched within interval,
+// and when the earliest of the others becomes due
+func watchedDue(repos []watch.Repo, interval time.Duration, retryAfter map[int64]time.Time, now time.Time) ([]watch.Repo, time.Time) {
+	var due []watch.Repo
+	var next time.Time
+	for _, repo := range repos {
+		at := now
+		if repo.LastFetched != nil {
+			at = repo.LastFetched.Add(interval)
+		}
+		if until, ok := retryAfter[repo.ID]; ok && until.After(at) {
+			at = until
+		}
+		if at.After(now) {
+			next = earliest(next, at)
+			continue
+		}
+		due = append(due, repo)
+	}
+	return due, next
+}
+
+// earliest returns the earlier of two times, treating zero as unset
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// runDaemonPrune applies the retention policy and logs a one-line summary
+func runDaemonPrune(cfg *config.Config) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	result, err := retention.NewPruner(db).Prune(retentionRules(cfg.Retention), time.Now(), false)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[%s] Pruned %d old rows, reclaimed %s\n",
+		time.Now().Format("15:04:05"), result.Rows(), formatBytes(result.Reclaimed()))
+	return nil
+}
+
+// planSchedule computes each account's next due time from its activity over
+// the policy's lookback window
+func planSchedule(db *database.DB, policy schedule.Policy) ([]schedule.Entry, error) {
+	accountRepo := account.NewRepository(db)
+	accounts, err := accountRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	since := time.Now().Add(-policy.Lookback)
+	counts := make(map[int64]int)
+	for _, counter := range []func(time.Time, ...int64) (map[int64]int, error){
+		activity.NewCommitRepository(db).CountByAccount,
+		activity.NewRepoRepository(db).CountByAccount,
+		activity.NewStarRepository(db).CountByAccount,
+	} {
+		m, err := counter(since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recent activity: %w", err)
+		}
+		for id, n := range m {
+			counts[id] += n
+		}
+	}
+
+	return policy.Plan(accounts, counts), nil
 }
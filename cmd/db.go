@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database maintenance",
+	Long:  `Commands for inspecting and maintaining the ghmon database.`,
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Long: `Applies pending schema migrations, backing up the database first.
+
+Migrations also run automatically whenever ghmon opens the database; use
+--status to see which migrations have been applied without changing anything.`,
+	RunE: runDBMigrate,
+}
+
+var dbMigrateStatus bool
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbMigrateCmd.Flags().BoolVar(&dbMigrateStatus, "status", false, "Show applied and pending migrations without applying them")
+}
+
+func runDBMigrate(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if dbMigrateStatus {
+		return printMigrationStatus(db)
+	}
+
+	result, err := db.Migrate()
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	for _, m := range result.Skipped {
+		fmt.Printf("  skipped %04d_%s (needs SQLite with %s)\n", m.Version, m.Name, m.Requires)
+	}
+	if len(result.Applied) == 0 {
+		fmt.Printf("Database is up to date (schema version %d).\n", result.To)
+		return nil
+	}
+
+	if result.BackupPath != "" {
+		fmt.Printf("Backed up database to %s\n", result.BackupPath)
+	}
+	for _, m := range result.Applied {
+		fmt.Printf("  applied %04d_%s\n", m.Version, m.Name)
+	}
+	fmt.Printf("Migrated schema from version %d to %d.\n", result.From, result.To)
+	return nil
+}
+
+func printMigrationStatus(db *database.DB) error {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	pendingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("\n%s\n\n", titleStyle.Render("SCHEMA MIGRATIONS"))
+
+	pending := 0
+	for _, s := range statuses {
+		name := fmt.Sprintf("%04d_%s", s.Version, s.Name)
+		if s.AppliedAt != nil {
+			fmt.Printf("  %-30s %s\n", name, dimStyle.Render("applied "+s.AppliedAt.Local().Format("2006-01-02 15:04")))
+		} else if s.Unsupported {
+			pending++
+			fmt.Printf("  %-30s %s\n", name, pendingStyle.Render("pending, needs SQLite with "+s.Requires))
+		} else {
+			pending++
+			fmt.Printf("  %-30s %s\n", name, pendingStyle.Render("pending"))
+		}
+	}
+
+	fmt.Printf("\n%d applied, %d pending\n\n", len(statuses)-pending, pending)
+	return nil
+}
@@ -3,18 +3,22 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"sort"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/julienpequegnot/ghmon/internal/account"
 	"github.com/julienpequegnot/ghmon/internal/activity"
-	"github.com/julienpequegnot/ghmon/internal/analysis"
 	"github.com/julienpequegnot/ghmon/internal/config"
 	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/digest"
+	"github.com/julienpequegnot/ghmon/internal/ignore"
 	"github.com/julienpequegnot/ghmon/internal/llm"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/julienpequegnot/ghmon/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -26,14 +30,20 @@ var digestCmd = &cobra.Command{
 }
 
 var (
-	digestDays  int
-	digestSmart bool
+	digestDays   int
+	digestSmart  bool
+	digestNoSave bool
+	digestTags   []string
+	digestAll    bool
 )
 
 func init() {
 	rootCmd.AddCommand(digestCmd)
 	digestCmd.Flags().IntVar(&digestDays, "days", 7, "Number of days to include in digest")
 	digestCmd.Flags().BoolVar(&digestSmart, "smart", false, "Use LLM for intelligent analysis")
+	digestCmd.Flags().BoolVar(&digestNoSave, "no-save", false, "Don't save the digest for 'ghmon digests'")
+	digestCmd.Flags().StringSliceVar(&digestTags, "tag", nil, "Only include accounts with any of these tags")
+	digestCmd.Flags().BoolVar(&digestAll, "include-automated", false, "Rank by every commit, including bot, dependency, merge and release commits")
 }
 
 func runDigest(cmd *cobra.Command, args []string) error {
@@ -44,23 +54,151 @@ func runDigest(cmd *cobra.Command, args []string) error {
 	defer db.Close()
 
 	since := time.Now().AddDate(0, 0, -digestDays)
-	endDate := time.Now()
+	store, err := filteredStore(db, true)
+	if err != nil {
+		return err
+	}
+	d, err := loadDigest(db, store, digestTags, since, time.Now(), digestAll)
+	if err != nil {
+		return err
+	}
+
+	var smartErr error
+	if digestSmart {
+		d.SmartAnalysis, smartErr = generateSmartAnalysis(store, d)
+	}
+
+	if !digestNoSave {
+		saveDigest(db, d, "digest")
+	}
+
+	if structuredOutput() {
+		if smartErr != nil {
+			fmt.Fprintf(os.Stderr, "LLM analysis unavailable: %v\n", smartErr)
+		}
+		return writeOutput(d)
+	}
+
+	printDigest(d, smartErr)
+	return nil
+}
+
+// filteredStore returns the store digests, exports and show read from, which
+// leaves out activity matching the ignore rules and, with hideMuted, muted
+// accounts
+func filteredStore(db *database.DB, hideMuted bool) (*storage.Store, error) {
+	// Digests work without a config file; the ignore file still applies
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = nil
+	}
+	m, err := ignore.Load(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignore rules: %w", err)
+	}
+	return storage.Filtered(storage.NewSQLite(db), m, hideMuted), nil
+}
+
+// loadDigest loads the digest for every account, or only for the accounts
+// carrying any of tags
+func loadDigest(db *database.DB, store *storage.Store, tags []string, since, end time.Time, includeAutomated bool) (*report.Digest, error) {
+	if len(tags) == 0 {
+		return report.LoadDigest(store, since, end, includeAutomated), nil
+	}
+
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := taggedAccounts(account.NewRepository(db), tags)
+	if err != nil {
+		return nil, err
+	}
+	return report.LoadTaggedDigest(store, tags, accounts, since, end, includeAutomated), nil
+}
+
+// saveDigest records a generated digest so it can be browsed and compared
+// later with 'ghmon digests'. Failing to save never fails the command.
+func saveDigest(db *database.DB, d *report.Digest, source string) {
+	data, err := json.Marshal(d)
+	if err == nil {
+		err = digest.NewRepository(db).Save(&digest.Record{
+			PeriodStart:   d.PeriodStart,
+			PeriodEnd:     d.PeriodEnd,
+			Content:       report.RenderMarkdown(d, time.Now()),
+			Data:          string(data),
+			SmartAnalysis: d.SmartAnalysis,
+			Source:        source,
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save digest: %v\n", err)
+	}
+}
+
+// generateSmartAnalysis asks the configured LLM to summarize focus areas
+func generateSmartAnalysis(store *storage.Store, d *report.Digest) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("could not load config: %w", err)
+	}
+
+	var topLanguages []string
+	for _, l := range d.Languages {
+		topLanguages = append(topLanguages, l.Language)
+	}
+	var trendingNames []string
+	for _, t := range d.Trending {
+		trendingNames = append(trendingNames, t.FullName)
+	}
+
+	// Rank within the digest's accounts, which leaves out organizations and,
+	// with --tag, untagged accounts
+	inDigest := make(map[string]bool)
+	for _, a := range d.MostActive {
+		inDigest[a.Username] = true
+	}
+	all, _ := store.Commits.GetUserActivity(d.PeriodStart, store.Accounts.Count())
+	var userActivities []activity.UserCommitActivity
+	for _, ua := range all {
+		if inDigest[ua.Username] && len(userActivities) < 5 {
+			userActivities = append(userActivities, ua)
+		}
+	}
+
+	var llmUsers []llm.UserActivity
+	for _, ua := range userActivities {
+		llmUsers = append(llmUsers, llm.UserActivity{
+			Username: ua.Username,
+			Commits:  ua.Count,
+			Repos:    ua.Repos,
+		})
+	}
 
-	accountRepo := account.NewRepository(db)
-	commitRepo := activity.NewCommitRepository(db)
-	repoRepo := activity.NewRepoRepository(db)
-	starRepo := activity.NewStarRepository(db)
+	mostActive := ""
+	if len(userActivities) > 0 {
+		mostActive = userActivities[0].Username
+	}
 
-	accounts, _ := accountRepo.List()
-	accountMap := make(map[int64]*account.Account)
-	for i := range accounts {
-		accountMap[accounts[i].ID] = &accounts[i]
+	digestData := llm.DigestData{
+		TotalCommits:   d.Summary.Commits,
+		TotalRepos:     d.Summary.NewRepos,
+		TotalStars:     d.Summary.Stars,
+		TopLanguages:   topLanguages,
+		TrendingRepos:  trendingNames,
+		MostActiveUser: mostActive,
+		ActiveUsers:    llmUsers,
 	}
 
-	commitCounts, _ := commitRepo.CountByAccount(since)
-	newRepos, _ := repoRepo.GetNewSince(since)
-	recentStars, _ := starRepo.GetSince(since)
+	prompt := llm.GenerateDigestPrompt(digestData)
+	client := llm.NewClient("http://localhost:11434", cfg.APIs.LLMModel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return client.Generate(ctx, prompt)
+}
 
+func printDigest(d *report.Digest, smartErr error) {
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
 	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
 	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
@@ -69,56 +207,47 @@ func runDigest(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("\n%s (%s - %s)\n",
 		titleStyle.Render("GITHUB DIGEST"),
-		since.Format("Jan 2"),
-		endDate.Format("Jan 2, 2006"))
+		d.PeriodStart.Format("Jan 2"),
+		d.PeriodEnd.Format("Jan 2, 2006"))
+	if len(d.Tags) > 0 {
+		fmt.Printf("%s\n", dimStyle.Render("Tags: "+strings.Join(d.Tags, ", ")))
+	}
 	fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 
-	totalCommits := 0
-	for _, c := range commitCounts {
-		totalCommits += c
+	commits := fmt.Sprintf("%d commits", d.Summary.Commits)
+	if automated := d.Summary.Commits - d.Summary.HumanCommits; automated > 0 {
+		commits += fmt.Sprintf(" (%d automated)", automated)
 	}
+	fmt.Printf("\n📊 Summary: %d accounts · %s · %d new repos · %d stars\n\n",
+		d.Summary.Accounts, commits, d.Summary.NewRepos, d.Summary.Stars)
 
-	fmt.Printf("\n📊 Summary: %d accounts · %d commits · %d new repos · %d stars\n\n",
-		len(accounts), totalCommits, len(newRepos), len(recentStars))
-
-	if len(commitCounts) > 0 {
+	if len(d.MostActive) > 0 {
 		fmt.Printf("%s\n", sectionStyle.Render("🔥 Most Active"))
 
-		type accountCommits struct {
-			username string
-			count    int
-		}
-		var sorted []accountCommits
-		for accID, count := range commitCounts {
-			if acc, ok := accountMap[accID]; ok {
-				sorted = append(sorted, accountCommits{acc.Username, count})
-			}
-		}
-		sort.Slice(sorted, func(i, j int) bool {
-			return sorted[i].count > sorted[j].count
-		})
-
 		limit := 5
-		if len(sorted) < limit {
-			limit = len(sorted)
+		if len(d.MostActive) < limit {
+			limit = len(d.MostActive)
 		}
 		for i := 0; i < limit; i++ {
-			fmt.Printf("  %-20s %d commits\n",
-				userStyle.Render(sorted[i].username),
-				sorted[i].count)
+			a := d.MostActive[i]
+			fmt.Printf("  %-20s %d commits", userStyle.Render(a.Username), a.Ranked(d.IncludeAutomated))
+			if automated := a.Commits - a.HumanCommits; !d.IncludeAutomated && automated > 0 {
+				fmt.Printf(" %s", dimStyle.Render(fmt.Sprintf("(+%d automated)", automated)))
+			}
+			fmt.Println()
 		}
 		fmt.Println()
 	}
 
-	if len(newRepos) > 0 {
+	if len(d.NewRepos) > 0 {
 		fmt.Printf("%s\n", sectionStyle.Render("🆕 New Repositories"))
 
 		limit := 5
-		if len(newRepos) < limit {
-			limit = len(newRepos)
+		if len(d.NewRepos) < limit {
+			limit = len(d.NewRepos)
 		}
 		for i := 0; i < limit; i++ {
-			repo := newRepos[i]
+			repo := d.NewRepos[i]
 			desc := repo.Description
 			if len(desc) > 50 {
 				desc = desc[:47] + "..."
@@ -132,159 +261,87 @@ func runDigest(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
-	if len(recentStars) > 0 {
-		fmt.Printf("%s\n", sectionStyle.Render("⭐ Recent Stars"))
-
-		starCounts := make(map[string][]string)
-		for _, s := range recentStars {
-			if acc, ok := accountMap[s.AccountID]; ok {
-				starCounts[s.RepoFullName] = append(starCounts[s.RepoFullName], acc.Username)
+	if len(d.Organizations) > 0 {
+		fmt.Printf("%s\n", sectionStyle.Render("🏢 Organizations"))
+		for _, o := range d.Organizations {
+			fmt.Printf("  %-20s %s\n", userStyle.Render(o.Login),
+				dimStyle.Render(fmt.Sprintf("%d commits · %d new repos", o.Commits, len(o.NewRepos))))
+			for _, repo := range o.NewRepos {
+				fmt.Printf("    %s\n", repoStyle.Render("+ "+repo.FullName))
 			}
 		}
+		fmt.Println()
+	}
 
-		type repoStars struct {
-			repo  string
-			users []string
-		}
-		var sorted []repoStars
-		for repo, users := range starCounts {
-			sorted = append(sorted, repoStars{repo, users})
+	if len(d.WatchedRepos) > 0 {
+		fmt.Printf("%s\n", sectionStyle.Render("👀 Watched Repositories"))
+		for _, w := range d.WatchedRepos {
+			fmt.Printf("  %s %s\n", repoStyle.Render(w.FullName),
+				dimStyle.Render(fmt.Sprintf("★ %d (%+d)", w.Stars, w.StarsGained)))
+			fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("%d commits in %d pushes · %d PRs opened · %d merged · %d issues opened",
+				w.Commits, w.Pushes, w.PullRequestsOpened, w.PullRequestsMerged, w.IssuesOpened)))
+			if len(w.Releases) > 0 {
+				fmt.Printf("    %s\n", dimStyle.Render("Released "+strings.Join(w.Releases, ", ")))
+			}
 		}
-		sort.Slice(sorted, func(i, j int) bool {
-			return len(sorted[i].users) > len(sorted[j].users)
-		})
+		fmt.Println()
+	}
+
+	if len(d.RecentStars) > 0 {
+		fmt.Printf("%s\n", sectionStyle.Render("⭐ Recent Stars"))
 
 		limit := 5
-		if len(sorted) < limit {
-			limit = len(sorted)
+		if len(d.RecentStars) < limit {
+			limit = len(d.RecentStars)
 		}
 		for i := 0; i < limit; i++ {
-			item := sorted[i]
-			fmt.Printf("  %s\n", repoStyle.Render(item.repo))
-			if len(item.users) > 1 {
-				fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("★ by %v", item.users)))
+			item := d.RecentStars[i]
+			fmt.Printf("  %s\n", repoStyle.Render(item.FullName))
+			if len(item.StarredBy) > 1 {
+				fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("★ by %v", item.StarredBy)))
 			} else {
-				fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("★ by %s", item.users[0])))
+				fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("★ by %s", item.StarredBy[0])))
 			}
 		}
 		fmt.Println()
 	}
 
 	// Trending repos (starred by multiple accounts)
-	trendingRepos, _ := starRepo.GetTrendingRepos(since, 2)
-	if len(trendingRepos) > 0 {
+	if len(d.Trending) > 0 {
 		fmt.Printf("%s\n", sectionStyle.Render("🔥 Trending (starred by multiple follows)"))
-		for _, t := range trendingRepos {
-			fmt.Printf("  %s\n", repoStyle.Render(t.RepoFullName))
+		for _, t := range d.Trending {
+			fmt.Printf("  %s\n", repoStyle.Render(t.FullName))
 			fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("★ by %s", strings.Join(t.StarredBy, ", "))))
 		}
 		fmt.Println()
 	}
 
-	languages := make(map[string]int)
-	for _, repo := range newRepos {
-		if repo.Language != "" {
-			languages[repo.Language]++
-		}
-	}
-	for _, star := range recentStars {
-		if star.RepoLanguage != "" {
-			languages[star.RepoLanguage]++
-		}
-	}
-
-	if len(languages) > 0 {
+	if len(d.Languages) > 0 {
 		fmt.Printf("%s\n", sectionStyle.Render("🏷️ Languages"))
 
-		type langCount struct {
-			lang  string
-			count int
-		}
-		var sorted []langCount
-		total := 0
-		for lang, count := range languages {
-			sorted = append(sorted, langCount{lang, count})
-			total += count
-		}
-		sort.Slice(sorted, func(i, j int) bool {
-			return sorted[i].count > sorted[j].count
-		})
-
 		var parts []string
-		limit := 5
-		if len(sorted) < limit {
-			limit = len(sorted)
-		}
-		for i := 0; i < limit; i++ {
-			pct := float64(sorted[i].count) / float64(total) * 100
-			parts = append(parts, fmt.Sprintf("%s (%.0f%%)", sorted[i].lang, pct))
+		for _, l := range d.Languages {
+			parts = append(parts, fmt.Sprintf("%s (%.0f%%)", l.Language, l.Percentage))
 		}
 		fmt.Printf("  %s\n\n", dimStyle.Render(joinStrings(parts, " · ")))
 	}
 
 	// Smart analysis with LLM
 	if digestSmart {
-		cfg, err := config.Load()
-		if err != nil {
-			fmt.Printf("%s\n", dimStyle.Render("Note: Could not load config for LLM analysis"))
+		fmt.Printf("%s\n", sectionStyle.Render("💡 Focus Areas (AI-generated)"))
+		if smartErr != nil {
+			fmt.Printf("  %s\n\n", dimStyle.Render(fmt.Sprintf("LLM analysis unavailable: %v", smartErr)))
 		} else {
-			fmt.Printf("%s\n", sectionStyle.Render("💡 Focus Areas (AI-generated)"))
-
-			// Prepare data for LLM
-			langStats := analysis.AnalyzeLanguages(newRepos, recentStars)
-			var trendingNames []string
-			for _, t := range trendingRepos {
-				trendingNames = append(trendingNames, t.RepoFullName)
-			}
-
-			userActivities, _ := commitRepo.GetUserActivity(since, 5)
-			var llmUsers []llm.UserActivity
-			for _, ua := range userActivities {
-				llmUsers = append(llmUsers, llm.UserActivity{
-					Username: ua.Username,
-					Commits:  ua.Count,
-					Repos:    ua.Repos,
-				})
-			}
-
-			mostActive := ""
-			if len(userActivities) > 0 {
-				mostActive = userActivities[0].Username
-			}
-
-			digestData := llm.DigestData{
-				TotalCommits:   totalCommits,
-				TotalRepos:     len(newRepos),
-				TotalStars:     len(recentStars),
-				TopLanguages:   analysis.GetTopLanguageNames(langStats),
-				TrendingRepos:  trendingNames,
-				MostActiveUser: mostActive,
-				ActiveUsers:    llmUsers,
-			}
-
-			prompt := llm.GenerateDigestPrompt(digestData)
-			client := llm.NewClient("http://localhost:11434", cfg.APIs.LLMModel)
-
-			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-			response, err := client.Generate(ctx, prompt)
-			cancel()
-
-			if err != nil {
-				fmt.Printf("  %s\n\n", dimStyle.Render(fmt.Sprintf("LLM analysis unavailable: %v", err)))
-			} else {
-				// Print each line of the response
-				for _, line := range strings.Split(response, "\n") {
-					line = strings.TrimSpace(line)
-					if line != "" {
-						fmt.Printf("  %s\n", line)
-					}
+			// Print each line of the response
+			for _, line := range strings.Split(d.SmartAnalysis, "\n") {
+				line = strings.TrimSpace(line)
+				if line != "" {
+					fmt.Printf("  %s\n", line)
 				}
-				fmt.Println()
 			}
+			fmt.Println()
 		}
 	}
-
-	return nil
 }
 
 func joinStrings(strs []string, sep string) string {
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/digest"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var digestsCmd = &cobra.Command{
+	Use:   "digests",
+	Short: "Browse and compare saved digests",
+	Long:  `Every digest generated by 'ghmon digest' or 'ghmon export' is saved; these commands list, show and compare them.`,
+}
+
+var digestsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved digests",
+	Args:  cobra.NoArgs,
+	RunE:  runDigestsList,
+}
+
+var digestsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a saved digest as markdown",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDigestsShow,
+}
+
+var digestsDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "Compare two saved digests",
+	Long:  `Shows how digest <b> differs from digest <a>: summary totals, per-account commits, language mix and trending repositories.`,
+	Args:  cobra.ExactArgs(2),
+	RunE:  runDigestsDiff,
+}
+
+var digestsLimit int
+
+func init() {
+	rootCmd.AddCommand(digestsCmd)
+	digestsCmd.AddCommand(digestsListCmd)
+	digestsCmd.AddCommand(digestsShowCmd)
+	digestsCmd.AddCommand(digestsDiffCmd)
+	digestsListCmd.Flags().IntVar(&digestsLimit, "limit", 20, "Number of digests to list")
+}
+
+func runDigestsList(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	records, err := digest.NewRepository(db).List(digestsLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list digests: %w", err)
+	}
+
+	if structuredOutput() {
+		return writeOutput(report.BuildSavedDigests(records))
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No saved digests yet. Run 'ghmon digest' first.")
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("\n%s\n\n", titleStyle.Render("SAVED DIGESTS"))
+	for _, r := range records {
+		smart := ""
+		if r.SmartAnalysis != "" {
+			smart = " · smart"
+		}
+		fmt.Printf("  #%-5d %s - %s %s\n",
+			r.ID,
+			r.PeriodStart.Local().Format("Jan 2"),
+			r.PeriodEnd.Local().Format("Jan 2, 2006"),
+			dimStyle.Render(fmt.Sprintf("(%s, %s%s)", r.Source, r.CreatedAt.Local().Format("2006-01-02 15:04"), smart)))
+	}
+	fmt.Println()
+	return nil
+}
+
+func runDigestsShow(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	rec, err := loadSavedDigest(digest.NewRepository(db), args[0])
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		d, err := decodeSavedDigest(rec)
+		if err != nil {
+			return err
+		}
+		return writeOutput(d)
+	}
+
+	fmt.Print(rec.Content)
+	return nil
+}
+
+func runDigestsDiff(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	repo := digest.NewRepository(db)
+	var digests [2]*report.Digest
+	var ids [2]int64
+	for i, arg := range args {
+		rec, err := loadSavedDigest(repo, arg)
+		if err != nil {
+			return err
+		}
+		if digests[i], err = decodeSavedDigest(rec); err != nil {
+			return err
+		}
+		ids[i] = rec.ID
+	}
+
+	diff := report.DiffDigests(digests[0], digests[1])
+	diff.From.ID, diff.To.ID = ids[0], ids[1]
+
+	if structuredOutput() {
+		return writeOutput(diff)
+	}
+
+	printDigestDiff(diff)
+	return nil
+}
+
+func loadSavedDigest(repo *digest.Repository, arg string) (*digest.Record, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest id '%s'", arg)
+	}
+	rec, err := repo.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("digest %d not found", id)
+	}
+	return rec, nil
+}
+
+func decodeSavedDigest(rec *digest.Record) (*report.Digest, error) {
+	if rec.Data == "" {
+		return nil, fmt.Errorf("digest %d has no structured data", rec.ID)
+	}
+	var d report.Digest
+	if err := json.Unmarshal([]byte(rec.Data), &d); err != nil {
+		return nil, fmt.Errorf("failed to decode digest %d: %w", rec.ID, err)
+	}
+	return &d, nil
+}
+
+func printDigestDiff(diff *report.DigestDiff) {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	repoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	upStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	downStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	delta := func(n float64, format string) string {
+		s := fmt.Sprintf("%+"+format, n)
+		switch {
+		case n > 0:
+			return upStyle.Render(s)
+		case n < 0:
+			return downStyle.Render(s)
+		}
+		return dimStyle.Render(s)
+	}
+	period := func(p report.DigestPeriod) string {
+		return fmt.Sprintf("#%d (%s - %s)", p.ID, p.PeriodStart.Local().Format("Jan 2"), p.PeriodEnd.Local().Format("Jan 2, 2006"))
+	}
+
+	fmt.Printf("\n%s\n", titleStyle.Render("DIGEST DIFF"))
+	fmt.Printf("%s\n\n", dimStyle.Render(period(diff.From)+" → "+period(diff.To)))
+
+	fmt.Printf("%s\n", sectionStyle.Render("📊 Summary"))
+	for _, row := range []struct {
+		label  string
+		change report.Change
+	}{
+		{"accounts", diff.Summary.Accounts},
+		{"commits", diff.Summary.Commits},
+		{"new repos", diff.Summary.NewRepos},
+		{"stars", diff.Summary.Stars},
+	} {
+		fmt.Printf("  %-12s %5d → %-5d %s\n", row.label, row.change.From, row.change.To, delta(float64(row.change.Delta), ".0f"))
+	}
+	fmt.Println()
+
+	var moved []report.ActiveChange
+	for _, a := range diff.MostActive {
+		if a.Delta != 0 {
+			moved = append(moved, a)
+		}
+	}
+	if len(moved) > 0 {
+		fmt.Printf("%s\n", sectionStyle.Render("🔥 Commits by account"))
+		limit := 10
+		if len(moved) < limit {
+			limit = len(moved)
+		}
+		for _, a := range moved[:limit] {
+			fmt.Printf("  %-20s %5d → %-5d %s\n", userStyle.Render(a.Username), a.From, a.To, delta(float64(a.Delta), ".0f"))
+		}
+		if len(moved) > limit {
+			fmt.Printf("  %s\n", dimStyle.Render(fmt.Sprintf("... and %d more", len(moved)-limit)))
+		}
+		fmt.Println()
+	}
+
+	if len(diff.Languages) > 0 {
+		fmt.Printf("%s\n", sectionStyle.Render("🏷️ Languages"))
+		for _, l := range diff.Languages {
+			fmt.Printf("  %-12s %4.0f%% → %-4.0f%% %s\n", l.Language, l.From, l.To, delta(l.Delta, ".0f"))
+		}
+		fmt.Println()
+	}
+
+	if len(diff.TrendingAdded) > 0 || len(diff.TrendingRemoved) > 0 {
+		fmt.Printf("%s\n", sectionStyle.Render("🔥 Trending"))
+		for _, name := range diff.TrendingAdded {
+			fmt.Printf("  %s %s\n", upStyle.Render("+"), repoStyle.Render(name))
+		}
+		for _, name := range diff.TrendingRemoved {
+			fmt.Printf("  %s %s\n", downStyle.Render("-"), repoStyle.Render(name))
+		}
+		fmt.Println()
+	}
+}
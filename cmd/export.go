@@ -3,15 +3,11 @@ package cmd
 
 import (
 	"fmt"
-	"sort"
-	"strings"
 	"time"
 
-	"github.com/julienpequegnot/ghmon/internal/account"
-	"github.com/julienpequegnot/ghmon/internal/activity"
-	"github.com/julienpequegnot/ghmon/internal/analysis"
 	"github.com/julienpequegnot/ghmon/internal/config"
 	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -23,12 +19,18 @@ var exportCmd = &cobra.Command{
 }
 
 var (
-	exportDays int
+	exportDays   int
+	exportNoSave bool
+	exportTags   []string
+	exportAll    bool
 )
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
 	exportCmd.Flags().IntVar(&exportDays, "days", 7, "Number of days to include in export")
+	exportCmd.Flags().BoolVar(&exportNoSave, "no-save", false, "Don't save the digest for 'ghmon digests'")
+	exportCmd.Flags().StringSliceVar(&exportTags, "tag", nil, "Only include accounts with any of these tags")
+	exportCmd.Flags().BoolVar(&exportAll, "include-automated", false, "Rank by every commit, including bot, dependency, merge and release commits")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
@@ -39,124 +41,23 @@ func runExport(cmd *cobra.Command, args []string) error {
 	defer db.Close()
 
 	since := time.Now().AddDate(0, 0, -exportDays)
-	endDate := time.Now()
-
-	accountRepo := account.NewRepository(db)
-	commitRepo := activity.NewCommitRepository(db)
-	repoRepo := activity.NewRepoRepository(db)
-	starRepo := activity.NewStarRepository(db)
-
-	accounts, _ := accountRepo.List()
-	accountMap := make(map[int64]*account.Account)
-	for i := range accounts {
-		accountMap[accounts[i].ID] = &accounts[i]
-	}
-
-	commitCounts, _ := commitRepo.CountByAccount(since)
-	newRepos, _ := repoRepo.GetNewSince(since)
-	recentStars, _ := starRepo.GetSince(since)
-	trendingRepos, _ := starRepo.GetTrendingRepos(since, 2)
-
-	totalCommits := 0
-	for _, c := range commitCounts {
-		totalCommits += c
-	}
-
-	var sb strings.Builder
-
-	// Header
-	sb.WriteString(fmt.Sprintf("# GitHub Activity Digest\n\n"))
-	sb.WriteString(fmt.Sprintf("**Period:** %s - %s\n\n",
-		since.Format("January 2, 2006"),
-		endDate.Format("January 2, 2006")))
-
-	// Summary
-	sb.WriteString("## Summary\n\n")
-	sb.WriteString(fmt.Sprintf("- **Accounts monitored:** %d\n", len(accounts)))
-	sb.WriteString(fmt.Sprintf("- **Total commits:** %d\n", totalCommits))
-	sb.WriteString(fmt.Sprintf("- **New repositories:** %d\n", len(newRepos)))
-	sb.WriteString(fmt.Sprintf("- **Stars given:** %d\n\n", len(recentStars)))
-
-	// Most Active
-	if len(commitCounts) > 0 {
-		sb.WriteString("## Most Active\n\n")
-		sb.WriteString("| Developer | Commits |\n")
-		sb.WriteString("|-----------|--------:|\n")
-
-		type accountCommits struct {
-			username string
-			count    int
-		}
-		var sorted []accountCommits
-		for accID, count := range commitCounts {
-			if acc, ok := accountMap[accID]; ok {
-				sorted = append(sorted, accountCommits{acc.Username, count})
-			}
-		}
-		sort.Slice(sorted, func(i, j int) bool {
-			return sorted[i].count > sorted[j].count
-		})
-
-		limit := 10
-		if len(sorted) < limit {
-			limit = len(sorted)
-		}
-		for i := 0; i < limit; i++ {
-			sb.WriteString(fmt.Sprintf("| [%s](https://github.com/%s) | %d |\n",
-				sorted[i].username, sorted[i].username, sorted[i].count))
-		}
-		sb.WriteString("\n")
+	store, err := filteredStore(db, true)
+	if err != nil {
+		return err
 	}
-
-	// New Repositories
-	if len(newRepos) > 0 {
-		sb.WriteString("## New Repositories\n\n")
-
-		limit := 10
-		if len(newRepos) < limit {
-			limit = len(newRepos)
-		}
-		for i := 0; i < limit; i++ {
-			repo := newRepos[i]
-			desc := repo.Description
-			if desc == "" {
-				desc = "*No description*"
-			}
-			sb.WriteString(fmt.Sprintf("- [%s](https://github.com/%s) - %s\n",
-				repo.FullName, repo.FullName, desc))
-		}
-		sb.WriteString("\n")
+	d, err := loadDigest(db, store, exportTags, since, time.Now(), exportAll)
+	if err != nil {
+		return err
 	}
 
-	// Trending Repos
-	if len(trendingRepos) > 0 {
-		sb.WriteString("## Trending (Starred by Multiple Follows)\n\n")
-
-		for _, t := range trendingRepos {
-			sb.WriteString(fmt.Sprintf("- [%s](https://github.com/%s) - ★ by %s\n",
-				t.RepoFullName, t.RepoFullName, strings.Join(t.StarredBy, ", ")))
-		}
-		sb.WriteString("\n")
+	if !exportNoSave {
+		saveDigest(db, d, "export")
 	}
 
-	// Languages
-	langStats := analysis.AnalyzeLanguages(newRepos, recentStars)
-	if len(langStats) > 0 {
-		sb.WriteString("## Languages\n\n")
-		sb.WriteString("| Language | Activity |\n")
-		sb.WriteString("|----------|--------:|\n")
-
-		for _, lang := range langStats {
-			sb.WriteString(fmt.Sprintf("| %s | %.0f%% |\n", lang.Language, lang.Percentage))
-		}
-		sb.WriteString("\n")
+	if structuredOutput() {
+		return writeOutput(d)
 	}
 
-	// Footer
-	sb.WriteString("---\n\n")
-	sb.WriteString(fmt.Sprintf("*Generated by [ghmon](https://github.com/julienpequegnot/ghmon) on %s*\n",
-		time.Now().Format("2006-01-02 15:04")))
-
-	fmt.Print(sb.String())
+	fmt.Print(report.RenderMarkdown(d, time.Now()))
 	return nil
 }
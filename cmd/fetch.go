@@ -3,26 +3,154 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/julienpequegnot/ghmon/internal/account"
 	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/analysis"
 	"github.com/julienpequegnot/ghmon/internal/config"
 	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/fetchrun"
 	"github.com/julienpequegnot/ghmon/internal/github"
+	"github.com/julienpequegnot/ghmon/internal/lockfile"
+	"github.com/julienpequegnot/ghmon/internal/progress"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 var fetchCmd = &cobra.Command{
-	Use:   "fetch",
+	Use:   "fetch [username...]",
 	Short: "Fetch activity from monitored accounts",
-	Long:  `Downloads recent commits, new repos, and stars from all monitored accounts.`,
-	RunE:  runFetch,
+	Long: `Downloads recent commits, new repos, and stars from monitored accounts.
+
+By default every account is fetched. Pass usernames to fetch only those
+accounts, --tag to fetch only accounts with a tag, --stale-for to skip accounts fetched recently, --only to restrict
+which endpoints are called, and --limit to cap the number of accounts
+(stalest first).
+
+Repositories watched with 'ghmon watch' are fetched too, unless usernames,
+--tag, --stale-for or --limit select accounts.
+
+Each run is recorded with per-account results; use 'ghmon fetch history' to
+inspect past runs. Exits non-zero if any account failed to fetch.`,
+	SilenceUsage: true,
+	RunE:         runFetch,
+}
+
+var (
+	fetchStaleFor time.Duration
+	fetchOnly     []string
+	fetchLimit    int
+	fetchTags     []string
+
+	fetchNoProgress bool
+)
+
+// requestsPerAccount is the number of API calls a full fetch of one account makes
+const requestsPerAccount = 3
+
+// fetchEndpoints selects which GitHub endpoints are called for each account
+type fetchEndpoints struct {
+	events bool
+	repos  bool
+	stars  bool
+}
+
+func (e fetchEndpoints) all() bool {
+	return e.events && e.repos && e.stars
 }
 
 func init() {
 	rootCmd.AddCommand(fetchCmd)
+	fetchCmd.Flags().DurationVar(&fetchStaleFor, "stale-for", 0, "Only fetch accounts not fetched within this duration (e.g. 6h)")
+	fetchCmd.Flags().StringSliceVar(&fetchOnly, "only", nil, "Only fetch these endpoints: events, repos, stars")
+	fetchCmd.Flags().IntVar(&fetchLimit, "limit", 0, "Maximum number of accounts to fetch, stalest first")
+	fetchCmd.Flags().StringSliceVar(&fetchTags, "tag", nil, "Only fetch accounts with any of these tags")
+	fetchCmd.Flags().BoolVar(&fetchNoProgress, "no-progress", false, "Print one line per account instead of the live progress view")
+}
+
+func parseFetchEndpoints(only []string) (fetchEndpoints, error) {
+	if len(only) == 0 {
+		return fetchEndpoints{events: true, repos: true, stars: true}, nil
+	}
+
+	var e fetchEndpoints
+	for _, name := range only {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "events", "commits":
+			e.events = true
+		case "repos":
+			e.repos = true
+		case "stars":
+			e.stars = true
+		default:
+			return e, fmt.Errorf("unknown endpoint '%s' (expected events, repos or stars)", name)
+		}
+	}
+	return e, nil
+}
+
+// selectAccounts applies the username, staleness and limit selectors to
+// accounts, which must already be ordered stalest first
+func selectAccounts(accounts []account.Account, usernames []string, staleFor time.Duration, limit int) ([]account.Account, error) {
+	if len(usernames) > 0 {
+		wanted := make(map[string]bool)
+		for _, u := range usernames {
+			wanted[strings.ToLower(u)] = true
+		}
+
+		var filtered []account.Account
+		for _, acc := range accounts {
+			if wanted[strings.ToLower(acc.Username)] {
+				filtered = append(filtered, acc)
+				delete(wanted, strings.ToLower(acc.Username))
+			}
+		}
+		for _, u := range usernames {
+			if wanted[strings.ToLower(u)] {
+				return nil, fmt.Errorf("account '%s' is not being monitored", u)
+			}
+		}
+		accounts = filtered
+	}
+
+	if staleFor > 0 {
+		var stale []account.Account
+		for _, acc := range accounts {
+			if acc.IsStale(staleFor) {
+				stale = append(stale, acc)
+			}
+		}
+		accounts = stale
+	}
+
+	if limit > 0 && len(accounts) > limit {
+		accounts = accounts[:limit]
+	}
+
+	return accounts, nil
+}
+
+// keepAccounts returns the accounts that are also in keep, in their
+// original order
+func keepAccounts(accounts, keep []account.Account) []account.Account {
+	ids := make(map[int64]bool)
+	for _, a := range keep {
+		ids[a.ID] = true
+	}
+
+	var kept []account.Account
+	for _, a := range accounts {
+		if ids[a.ID] {
+			kept = append(kept, a)
+		}
+	}
+	return kept
 }
 
 func runFetch(cmd *cobra.Command, args []string) error {
@@ -41,123 +169,601 @@ func runFetch(cmd *cobra.Command, args []string) error {
 	}
 	defer db.Close()
 
+	endpoints, err := parseFetchEndpoints(fetchOnly)
+	if err != nil {
+		return err
+	}
+
 	accountRepo := account.NewRepository(db)
-	accounts, err := accountRepo.List()
+	accounts, err := accountRepo.ListByStaleness()
 	if err != nil {
 		return fmt.Errorf("failed to list accounts: %w", err)
 	}
 
-	if len(accounts) == 0 {
-		fmt.Println("No accounts to fetch. Run 'ghmon sync' or 'ghmon add <username>' first.")
+	// Watched repositories aren't accounts, so any account selection leaves them out
+	var watched []watch.Repo
+	selecting := len(args) > 0 || len(fetchTags) > 0 || fetchStaleFor > 0 || fetchLimit > 0
+	if !selecting {
+		if watched, err = watch.NewRepository(db).List(); err != nil {
+			return fmt.Errorf("failed to list watched repositories: %w", err)
+		}
+	}
+
+	if len(accounts) == 0 && len(watched) == 0 {
+		notice("No accounts to fetch. Run 'ghmon sync' or 'ghmon add <username>' first.")
+		return nil
+	}
+
+	if len(fetchTags) > 0 {
+		tagged, err := taggedAccounts(accountRepo, fetchTags)
+		if err != nil {
+			return err
+		}
+		accounts = keepAccounts(accounts, tagged)
+	}
+
+	accounts, err = selectAccounts(accounts, args, fetchStaleFor, fetchLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(accounts) == 0 && len(watched) == 0 {
+		notice("No accounts match the selection; everything is up to date.")
 		return nil
 	}
 
+	lock, err := acquireFetchLock()
+	if err != nil {
+		return err
+	}
+	defer releaseFetchLock(lock)
+	classifyPending(db)
+
 	client := github.NewClient(cfg.GitHub.Token)
-	commitRepo := activity.NewCommitRepository(db)
-	repoRepo := activity.NewRepoRepository(db)
-	starRepo := activity.NewStarRepository(db)
+	f := newFetcher(db, client, endpoints, cfg.Fetch.Concurrency)
 
-	fmt.Printf("Fetching activity for %d accounts...\n\n", len(accounts))
+	if structuredOutput() {
+		return runFetchStructured(f, client, accounts, watched)
+	}
 
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, cfg.Fetch.Concurrency)
+	var run *fetchrun.Run
+	if !fetchNoProgress && isTerminal(os.Stdout) {
+		run, err = runFetchWithProgress(f, client, accounts)
+	} else {
+		fmt.Printf("Fetching activity for %d accounts...\n\n", len(accounts))
+		run, err = f.run(accounts)
+	}
+	if err != nil {
+		return err
+	}
+
+	watchedFailed := 0
+	if len(watched) > 0 {
+		fmt.Printf("\nFetching %d watched repositories...\n\n", len(watched))
+		f.quiet = false
+		for _, result := range f.runWatched(watched) {
+			if result.Failed() {
+				watchedFailed++
+			}
+		}
+	}
+
+	f.summary.print()
+
+	// Show rate limit status
+	if rl := client.RateLimit(); rl.Remaining > 0 {
+		fmt.Printf("Rate limit: %d requests remaining (resets %s)\n",
+			rl.Remaining,
+			rl.Reset.Format("15:04"))
+	}
+
+	if run.AccountsFailed > 0 {
+		return fmt.Errorf("%d of %d accounts failed (run 'ghmon fetch history %d' for details)",
+			run.AccountsFailed, run.AccountsTotal, run.ID)
+	}
+	if watchedFailed > 0 {
+		return fmt.Errorf("%d of %d watched repositories failed", watchedFailed, len(watched))
+	}
+
+	fmt.Println("Run 'ghmon digest' to see the summary.")
+
+	return nil
+}
+
+// classifyPending classifies the commits stored before commits were
+// classified on insert, which count as neither human nor automated until
+// then. Once they are done it costs one indexed query.
+func classifyPending(db *database.DB) {
+	if _, err := activity.ClassifyPending(db); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// acquireFetchLock takes the lock shared with the daemon so two processes
+// never fetch (and spend rate limit on) the same accounts at once
+func acquireFetchLock() (*lockfile.Lock, error) {
+	lock, err := lockfile.TryAcquire(config.FetchLockPath())
+	if lockfile.IsHeld(err) {
+		return nil, fmt.Errorf("another fetch is already running (%v); wait for it to finish", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire fetch lock: %w", err)
+	}
+	return lock, nil
+}
+
+// releaseFetchLock releases the fetch lock, warning if that fails since the
+// command's own result matters more
+func releaseFetchLock(lock *lockfile.Lock) {
+	if err := lock.Release(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to release fetch lock: %v\n", err)
+	}
+}
+
+// fetcher fetches a set of accounts concurrently and records the run
+type fetcher struct {
+	db          *database.DB
+	client      *github.Client
+	accountRepo *account.Repository
+	commitRepo  *activity.CommitRepository
+	repoRepo    *activity.RepoRepository
+	starRepo    *activity.StarRepository
+	runRepo     *fetchrun.Repository
+	watchRepo   *watch.Repository
+	endpoints   fetchEndpoints
+	concurrency int
+
+	// pace is called before each account is dispatched and may block to
+	// spread requests out; returning false stops dispatching
+	pace func() bool
+	// onStart is called when a worker picks up an account
+	onStart func(worker int, acc account.Account)
+	// onResult is called with each account's result once it is recorded
+	onResult func(worker int, result *fetchrun.AccountResult)
+	// quiet suppresses the per-account result lines
+	quiet bool
+
+	// feed receives a change for every row actually inserted
+	feed    *activity.Feed
+	summary *fetchSummary
+
+	mu sync.Mutex
+}
+
+// fetchSummary tallies the changes published while fetching
+type fetchSummary struct {
+	mu       sync.Mutex
+	commits  int
+	repos    int
+	stars    int
+	newRepos []string
+	// watched counts new events in watched repositories
+	watched int
+}
+
+func (s *fetchSummary) record(c activity.Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch c.Kind {
+	case activity.NewCommit:
+		s.commits++
+	case activity.NewRepo:
+		s.repos++
+		s.newRepos = append(s.newRepos, c.Repo.FullName)
+	case activity.NewStar:
+		s.stars++
+	}
+}
+
+func (s *fetchSummary) print() {
+	fmt.Printf("\nFetch complete: %d new commits, %d new repos, %d new stars\n", s.commits, s.repos, s.stars)
+	if s.watched > 0 {
+		fmt.Printf("  %d new events in watched repositories\n", s.watched)
+	}
+
+	limit := 5
+	if len(s.newRepos) < limit {
+		limit = len(s.newRepos)
+	}
+	for i := 0; i < limit; i++ {
+		fmt.Printf("  + %s\n", s.newRepos[i])
+	}
+	if len(s.newRepos) > limit {
+		fmt.Printf("  ... and %d more\n", len(s.newRepos)-limit)
+	}
+}
 
-	var mu sync.Mutex
-	totalCommits := 0
-	totalRepos := 0
-	totalStars := 0
+func newFetcher(db *database.DB, client *github.Client, endpoints fetchEndpoints, concurrency int) *fetcher {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	f := &fetcher{
+		db:          db,
+		client:      client,
+		accountRepo: account.NewRepository(db),
+		commitRepo:  activity.NewCommitRepository(db),
+		repoRepo:    activity.NewRepoRepository(db),
+		starRepo:    activity.NewStarRepository(db),
+		runRepo:     fetchrun.NewRepository(db),
+		watchRepo:   watch.NewRepository(db),
+		endpoints:   endpoints,
+		concurrency: concurrency,
+		feed:        activity.NewFeed(),
+		summary:     &fetchSummary{},
+	}
+	f.commitRepo.SetFeed(f.feed)
+	f.repoRepo.SetFeed(f.feed)
+	f.starRepo.SetFeed(f.feed)
+	f.feed.Subscribe(f.summary.record)
+	return f
+}
+
+func (f *fetcher) run(accounts []account.Account) (*fetchrun.Run, error) {
+	run, err := f.runRepo.Start(len(accounts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record fetch run: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	// Each slot is a worker number, so progress can be reported per worker
+	slots := make(chan int, f.concurrency)
+	for i := 0; i < f.concurrency; i++ {
+		slots <- i
+	}
 
 	for _, acc := range accounts {
+		if f.pace != nil && !f.pace() {
+			break
+		}
+
 		wg.Add(1)
-		go func(acc account.Account) {
+		worker := <-slots
+		go func(worker int, acc account.Account) {
 			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+			defer func() { slots <- worker }()
+
+			if f.onStart != nil {
+				f.onStart(worker, acc)
+			}
 
 			// Check rate limit before fetching
-			client.WaitForRateLimit()
+			f.client.WaitForRateLimit()
+
+			result := f.fetchAccount(&acc)
 
-			commits, repos, stars := fetchAccountActivity(client, &acc, commitRepo, repoRepo, starRepo)
+			f.mu.Lock()
+			defer f.mu.Unlock()
 
-			mu.Lock()
-			totalCommits += commits
-			totalRepos += repos
-			totalStars += stars
-			mu.Unlock()
+			if err := f.runRepo.AddResult(run, result); err != nil {
+				fmt.Fprintf(os.Stderr, "  Warning: failed to record result for %s: %v\n", acc.Username, err)
+			}
 
-			accountRepo.UpdateLastFetched(acc.ID)
+			if !f.quiet {
+				fmt.Printf("  %s: %d new commits, %d new repos, %d new stars\n", acc.Username, result.Commits, result.Repos, result.Stars)
+				printEndpointErrors(result)
+			}
 
-			fmt.Printf("  %s: %d commits, %d repos, %d stars\n", acc.Username, commits, repos, stars)
-		}(acc)
+			if f.onResult != nil {
+				f.onResult(worker, result)
+			}
+		}(worker, acc)
 	}
 
 	wg.Wait()
 
-	fmt.Printf("\nFetch complete: %d commits, %d new repos, %d stars\n", totalCommits, totalRepos, totalStars)
+	if err := f.runRepo.Finish(run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record fetch run: %v\n", err)
+	}
 
-	// Show rate limit status
-	if client.RateLimitRemaining() > 0 {
-		fmt.Printf("Rate limit: %d requests remaining (resets %s)\n",
-			client.RateLimitRemaining(),
-			client.RateLimitReset().Format("15:04"))
+	return run, nil
+}
+
+// runFetchStructured runs the fetcher silently and writes the run as a
+// structured document, still exiting non-zero if any account failed
+func runFetchStructured(f *fetcher, client *github.Client, accounts []account.Account, watched []watch.Repo) error {
+	var results []fetchrun.AccountResult
+	f.quiet = true
+	f.onResult = func(worker int, result *fetchrun.AccountResult) {
+		results = append(results, *result)
 	}
 
-	fmt.Println("Run 'ghmon digest' to see the summary.")
+	run, err := f.run(accounts)
+	if err != nil {
+		return err
+	}
+
+	out := report.BuildFetchRun(run, results)
+	watchedFailed := 0
+	for _, result := range f.runWatched(watched) {
+		out.WatchedRepos = append(out.WatchedRepos, *result)
+		if result.Failed() {
+			watchedFailed++
+		}
+	}
+	if rl := client.RateLimit(); rl.Limit > 0 {
+		out.RateLimit = &report.RateLimit{
+			Remaining: rl.Remaining,
+			Limit:     rl.Limit,
+			Reset:     rl.Reset,
+		}
+	}
+	if err := writeOutput(out); err != nil {
+		return err
+	}
 
+	if run.AccountsFailed > 0 {
+		return fmt.Errorf("%d of %d accounts failed", run.AccountsFailed, run.AccountsTotal)
+	}
+	if watchedFailed > 0 {
+		return fmt.Errorf("%d of %d watched repositories failed", watchedFailed, len(watched))
+	}
 	return nil
 }
 
-func fetchAccountActivity(
-	client *github.Client,
-	acc *account.Account,
-	commitRepo *activity.CommitRepository,
-	repoRepo *activity.RepoRepository,
-	starRepo *activity.StarRepository,
-) (commits, repos, stars int) {
+// runFetchWithProgress runs the fetcher behind a live progress view, then
+// prints the accounts that failed once the view is closed
+func runFetchWithProgress(f *fetcher, client *github.Client, accounts []account.Account) (*fetchrun.Run, error) {
+	tracker := progress.NewTracker(len(accounts), f.concurrency)
+	display := progress.NewDisplay(tracker, os.Stdout, func() {
+		rl := client.RateLimit()
+		tracker.SetRateLimit(rl.Remaining, rl.Limit, rl.Reset)
+	})
+	// A printed wait notice would be drawn over by the view, so show it there
+	client.OnRateLimitWait(func(remaining int, wait time.Duration) {
+		tracker.SetWaiting(time.Now().Add(wait))
+	})
+	defer client.OnRateLimitWait(nil)
+
+	var failures []*fetchrun.AccountResult
+	f.quiet = true
+	f.onStart = func(worker int, acc account.Account) {
+		tracker.Start(worker, acc.Username)
+	}
+	f.onResult = func(worker int, result *fetchrun.AccountResult) {
+		tracker.Finish(worker, result.Failed())
+		if result.Failed() {
+			failures = append(failures, result)
+		}
+	}
+
+	display.Start()
+	run, err := f.run(accounts)
+	display.Stop()
+
+	for _, result := range failures {
+		fmt.Printf("  %s: failed\n", result.Username)
+		printEndpointErrors(result)
+	}
+	return run, err
+}
+
+func isTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
+
+func printEndpointErrors(result *fetchrun.AccountResult) {
+	if result.EventsError != "" {
+		fmt.Printf("    events: %s\n", result.EventsError)
+	}
+	if result.ReposError != "" {
+		fmt.Printf("    repos: %s\n", result.ReposError)
+	}
+	if result.StarsError != "" {
+		fmt.Printf("    stars: %s\n", result.StarsError)
+	}
+	if result.WriteError != "" {
+		fmt.Printf("    write: %s\n", result.WriteError)
+	}
+}
+
+// fetchAccount calls the GitHub API for one account, then writes everything
+// it got, plus last_fetched, in a single transaction so an account is never
+// left half-written
+func (f *fetcher) fetchAccount(acc *account.Account) *fetchrun.AccountResult {
+	result := &fetchrun.AccountResult{
+		AccountID: acc.ID,
+		Username:  acc.Username,
+	}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	var events []github.Event
+	var userRepos []github.Repo
+	var starred []github.StarredRepo
+	var err error
+
+	// Organizations have their own events and repos endpoints and never star
+	getEvents, getRepos := f.client.GetUserEvents, f.client.GetUserRepos
+	if acc.IsOrganization() {
+		getEvents, getRepos = f.client.GetOrgEvents, f.client.GetOrgRepos
+	}
 
 	// Fetch events (commits)
-	events, err := client.GetUserEvents(acc.Username)
-	if err == nil {
+	if f.endpoints.events {
+		result.Requests++
+		if events, err = getEvents(acc.Username); err != nil {
+			result.EventsError = err.Error()
+		}
+	}
+
+	// Fetch repos
+	if f.endpoints.repos {
+		result.Requests++
+		if userRepos, err = getRepos(acc.Username); err != nil {
+			result.ReposError = err.Error()
+		}
+	}
+
+	// Fetch starred repos
+	if f.endpoints.stars && !acc.IsOrganization() {
+		result.Requests++
+		if starred, err = f.client.GetUserStarred(acc.Username); err != nil {
+			result.StarsError = err.Error()
+		}
+	}
+
+	var commits, repos, stars int
+	err = f.db.WithTx(func(tx *database.Tx) error {
+		commits, repos, stars = 0, 0, 0
+		commitRepo := f.commitRepo.WithTx(tx)
+		repoRepo := f.repoRepo.WithTx(tx)
+		starRepo := f.starRepo.WithTx(tx)
+
 		for _, event := range events {
-			if event.Type == "PushEvent" {
-				payload, err := github.ParsePushPayload(event.Payload)
+			if event.Type != "PushEvent" {
+				continue
+			}
+			payload, err := github.ParsePushPayload(event.Payload)
+			if err != nil {
+				continue
+			}
+			for _, commit := range payload.Commits {
+				author := analysis.CommitAuthor{Name: commit.Author.Name, Email: commit.Author.Email}
+				inserted, err := commitRepo.AddAuthored(acc.ID, event.Repo.Name, commit.SHA, commit.Message, author, event.CreatedAt)
 				if err != nil {
-					continue
+					return err
 				}
-				for _, commit := range payload.Commits {
-					if commitRepo.Add(acc.ID, event.Repo.Name, commit.SHA, commit.Message, event.CreatedAt) == nil {
-						commits++
-					}
+				if inserted {
+					commits++
 				}
 			}
 		}
-	}
 
-	// Fetch repos
-	userRepos, err := client.GetUserRepos(acc.Username)
-	if err == nil {
 		cutoff := time.Now().AddDate(0, 0, -90)
 		for _, repo := range userRepos {
-			if repo.CreatedAt.After(cutoff) {
-				if repoRepo.Add(acc.ID, repo.Name, repo.FullName, repo.Description, repo.Language, repo.Stars, repo.CreatedAt) == nil {
-					repos++
-				}
+			if !repo.CreatedAt.After(cutoff) {
+				continue
+			}
+			inserted, err := repoRepo.Add(acc.ID, repo.Name, repo.FullName, repo.Description, repo.Language, repo.Stars, repo.CreatedAt)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				repos++
 			}
 		}
-	}
 
-	// Fetch starred repos
-	starred, err := client.GetUserStarred(acc.Username)
-	if err == nil {
-		cutoff := time.Now().AddDate(0, 0, -90)
 		for _, star := range starred {
-			if star.StarredAt.After(cutoff) {
-				if starRepo.Add(acc.ID, star.FullName, star.Description, star.Language, star.Stars, star.StarredAt) == nil {
-					stars++
+			if !star.StarredAt.After(cutoff) {
+				continue
+			}
+			inserted, err := starRepo.Add(acc.ID, star.FullName, star.Description, star.Language, star.Stars, star.StarredAt)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				stars++
+			}
+		}
+
+		// Partial fetches don't count towards staleness
+		if !result.Failed() && f.endpoints.all() {
+			return f.accountRepo.WithTx(tx).UpdateLastFetched(acc.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		result.WriteError = err.Error()
+		return result
+	}
+
+	result.Commits, result.Repos, result.Stars = commits, repos, stars
+	return result
+}
+
+// runWatched fetches the watched repositories one at a time, after the
+// accounts. They aren't part of the recorded fetch run.
+func (f *fetcher) runWatched(repos []watch.Repo) []*report.FetchWatchedResult {
+	var results []*report.FetchWatchedResult
+	for _, repo := range repos {
+		f.client.WaitForRateLimit()
+		result := f.fetchWatched(&repo)
+		results = append(results, result)
+
+		f.summary.mu.Lock()
+		f.summary.watched += result.NewEvents
+		f.summary.mu.Unlock()
+
+		if !f.quiet {
+			fmt.Printf("  %s: %d new events, %d stars (%+d)\n", result.FullName, result.NewEvents, result.Stars, result.StarsGained)
+			for _, endpoint := range []string{"events", "repos", "write"} {
+				if msg, ok := result.Errors[endpoint]; ok {
+					fmt.Printf("    %s: %s\n", endpoint, msg)
 				}
 			}
 		}
 	}
+	return results
+}
+
+// fetchWatched pulls a watched repository's events and details, then writes
+// them in a single transaction like fetchAccount
+func (f *fetcher) fetchWatched(repo *watch.Repo) *report.FetchWatchedResult {
+	result := &report.FetchWatchedResult{FullName: repo.FullName, Stars: repo.Stars}
+	fail := func(endpoint string, err error) {
+		if result.Errors == nil {
+			result.Errors = make(map[string]string)
+		}
+		result.Errors[endpoint] = err.Error()
+	}
+
+	var events []github.Event
+	var info *github.Repo
+	var err error
+
+	if f.endpoints.events {
+		result.Requests++
+		if events, err = f.client.GetRepoEvents(repo.FullName); err != nil {
+			fail("events", err)
+		}
+	}
 
-	return commits, repos, stars
+	// The repository itself carries the star count
+	if f.endpoints.repos {
+		result.Requests++
+		if info, err = f.client.GetRepo(repo.FullName); err != nil {
+			fail("repos", err)
+		}
+	}
+
+	added := 0
+	err = f.db.WithTx(func(tx *database.Tx) error {
+		added = 0
+		watchRepo := f.watchRepo.WithTx(tx)
+
+		for _, e := range events {
+			ev, ok := watch.EventFromGitHub(e)
+			if !ok {
+				continue
+			}
+			inserted, err := watchRepo.AddEvent(repo.ID, ev)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				added++
+			}
+		}
+
+		if info != nil {
+			if err := watchRepo.UpdateRepo(repo.ID, info.Description, info.Language, info.Stars, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		if !result.Failed() && f.endpoints.events && f.endpoints.repos {
+			return watchRepo.UpdateLastFetched(repo.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		fail("write", err)
+		return result
+	}
+
+	result.NewEvents = added
+	if info != nil {
+		result.Stars, result.StarsGained = info.Stars, info.Stars-repo.Stars
+	}
+	return result
 }
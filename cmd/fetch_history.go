@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/fetchrun"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var fetchHistoryCmd = &cobra.Command{
+	Use:   "history [run-id]",
+	Short: "Show past fetch runs",
+	Long:  `Lists recent fetch runs, or shows per-account results and errors for a single run.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runFetchHistory,
+}
+
+var fetchHistoryLimit int
+
+func init() {
+	fetchCmd.AddCommand(fetchHistoryCmd)
+	fetchHistoryCmd.Flags().IntVar(&fetchHistoryLimit, "limit", 10, "Number of runs to list")
+}
+
+func runFetchHistory(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	runRepo := fetchrun.NewRepository(db)
+
+	if len(args) == 1 {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid run id '%s'", args[0])
+		}
+		return showFetchRun(runRepo, id)
+	}
+
+	runs, err := runRepo.List(fetchHistoryLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list fetch runs: %w", err)
+	}
+
+	if structuredOutput() {
+		out := make([]*report.FetchRun, 0, len(runs))
+		for i := range runs {
+			out = append(out, report.BuildFetchRun(&runs[i], nil))
+		}
+		return writeOutput(out)
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("No fetch runs recorded yet. Run 'ghmon fetch' first.")
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("\n%s\n\n", titleStyle.Render("FETCH HISTORY"))
+
+	for _, run := range runs {
+		status := "running"
+		if run.FinishedAt != nil {
+			status = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+		}
+		failed := fmt.Sprintf("%d failed", run.AccountsFailed)
+		if run.AccountsFailed > 0 {
+			failed = errorStyle.Render(failed)
+		}
+		fmt.Printf("  #%-5d %s  %d accounts, %s · %d requests · %d items %s\n",
+			run.ID,
+			run.StartedAt.Local().Format("2006-01-02 15:04"),
+			run.AccountsTotal,
+			failed,
+			run.Requests,
+			run.Items,
+			dimStyle.Render("("+status+")"))
+	}
+
+	fmt.Println()
+	return nil
+}
+
+func showFetchRun(runRepo *fetchrun.Repository, id int64) error {
+	run, err := runRepo.Get(id)
+	if err != nil {
+		return fmt.Errorf("fetch run %d not found", id)
+	}
+
+	results, err := runRepo.Results(id)
+	if err != nil {
+		return fmt.Errorf("failed to load run results: %w", err)
+	}
+
+	if structuredOutput() {
+		return writeOutput(report.BuildFetchRun(run, results))
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("\n%s (%s)\n", titleStyle.Render(fmt.Sprintf("FETCH RUN #%d", run.ID)),
+		run.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("%s\n\n", dimStyle.Render(fmt.Sprintf("%d accounts · %d failed · %d requests · %d items",
+		run.AccountsTotal, run.AccountsFailed, run.Requests, run.Items)))
+
+	for _, res := range results {
+		fmt.Printf("  %-20s %d commits, %d repos, %d stars %s\n",
+			userStyle.Render(res.Username),
+			res.Commits, res.Repos, res.Stars,
+			dimStyle.Render(fmt.Sprintf("(%d requests, %v)", res.Requests, res.Duration.Round(time.Millisecond))))
+		if res.EventsError != "" {
+			fmt.Printf("    %s\n", errorStyle.Render("events: "+res.EventsError))
+		}
+		if res.ReposError != "" {
+			fmt.Printf("    %s\n", errorStyle.Render("repos: "+res.ReposError))
+		}
+		if res.StarsError != "" {
+			fmt.Printf("    %s\n", errorStyle.Render("stars: "+res.StarsError))
+		}
+		if res.WriteError != "" {
+			fmt.Printf("    %s\n", errorStyle.Render("write: "+res.WriteError))
+		}
+	}
+
+	fmt.Println()
+	return nil
+}
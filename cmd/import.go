@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/github"
+	"github.com/julienpequegnot/ghmon/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Add accounts from organizations, repositories and lists",
+	Long: `Adds accounts to monitor from another source than your following list.
+
+Each source lists the accounts it found, marking the ones already monitored,
+before adding the new ones; use --dry-run to only see the list. --tag tags
+every account the source found, including those already monitored. Imported
+accounts are never removed by 'ghmon sync --prune'.`,
+}
+
+var importOrgCmd = &cobra.Command{
+	Use:   "org <org>",
+	Short: "Import the members of an organization",
+	Long:  `Imports an organization's members. Only public members are listed unless your token belongs to a member.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportOrg,
+}
+
+var importContributorsCmd = &cobra.Command{
+	Use:   "contributors <owner/repo>",
+	Short: "Import the top contributors of a repository",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportContributors,
+}
+
+var importStargazersCmd = &cobra.Command{
+	Use:   "stargazers <owner/repo>",
+	Short: "Import stargazers of a repository with many followers",
+	Long: `Imports users who starred a repository and have at least --min-followers
+followers. Each stargazer's profile is looked up, costing one API request, so
+--max caps how many stargazers are checked.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportStargazers,
+}
+
+var importFollowingCmd = &cobra.Command{
+	Use:   "following <username>",
+	Short: "Import the accounts another user follows",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportFollowing,
+}
+
+var importFileCmd = &cobra.Command{
+	Use:   "file <path>",
+	Short: "Import usernames from a text or CSV file",
+	Long: `Imports usernames from a text file with one username per line, or a CSV
+file with the username in the first column. Blank lines, '#' comments and a
+"username" or "login" header row are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportFile,
+}
+
+var (
+	importTag    string
+	importDryRun bool
+
+	importTop          int
+	importMinFollowers int
+	importMax          int
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importOrgCmd)
+	importCmd.AddCommand(importContributorsCmd)
+	importCmd.AddCommand(importStargazersCmd)
+	importCmd.AddCommand(importFollowingCmd)
+	importCmd.AddCommand(importFileCmd)
+
+	importCmd.PersistentFlags().StringVar(&importTag, "tag", "", "Tag every account the source found")
+	importCmd.PersistentFlags().BoolVar(&importDryRun, "dry-run", false, "List the accounts without adding them")
+	importContributorsCmd.Flags().IntVar(&importTop, "top", 20, "Number of top contributors to import")
+	importStargazersCmd.Flags().IntVar(&importMinFollowers, "min-followers", 100, "Only import stargazers with at least this many followers")
+	importStargazersCmd.Flags().IntVar(&importMax, "max", 200, "Maximum number of stargazers to check")
+}
+
+// importClient returns a GitHub client for the sources that call the API
+func importClient() (*github.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config (run 'ghmon init' first): %w", err)
+	}
+	if cfg.GitHub.Token == "" {
+		return nil, fmt.Errorf("GitHub token not set. Add your token to %s", config.ConfigPath())
+	}
+	return github.NewClient(cfg.GitHub.Token), nil
+}
+
+func usersToCandidates(users []github.User) []importer.Candidate {
+	candidates := make([]importer.Candidate, 0, len(users))
+	for _, u := range users {
+		candidates = append(candidates, importer.Candidate{Username: u.Login})
+	}
+	return candidates
+}
+
+func runImportOrg(cmd *cobra.Command, args []string) error {
+	client, err := importClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Fetching members of %s...\n", args[0])
+	members, err := client.GetOrgMembers(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to fetch members: %w", err)
+	}
+	return importCandidates(fmt.Sprintf("members of %s", args[0]), usersToCandidates(members))
+}
+
+func runImportContributors(cmd *cobra.Command, args []string) error {
+	client, err := importClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Fetching top contributors of %s...\n", args[0])
+	// Fetch a few extra to make up for the bots skipped below
+	contributors, err := client.GetRepoContributors(args[0], importTop+10)
+	if err != nil {
+		return fmt.Errorf("failed to fetch contributors: %w", err)
+	}
+
+	var candidates []importer.Candidate
+	for _, c := range contributors {
+		if len(candidates) == importTop {
+			break
+		}
+		if c.Type != "User" {
+			continue
+		}
+		candidates = append(candidates, importer.Candidate{
+			Username: c.Login,
+			Detail:   fmt.Sprintf("%d contributions", c.Contributions),
+		})
+	}
+	return importCandidates(fmt.Sprintf("top contributors of %s", args[0]), candidates)
+}
+
+func runImportStargazers(cmd *cobra.Command, args []string) error {
+	client, err := importClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Fetching stargazers of %s...\n", args[0])
+	stargazers, err := client.GetStargazers(args[0], importMax)
+	if err != nil {
+		return fmt.Errorf("failed to fetch stargazers: %w", err)
+	}
+
+	fmt.Printf("Checking followers of %d stargazers...\n", len(stargazers))
+	var candidates []importer.Candidate
+	for _, s := range stargazers {
+		client.WaitForRateLimit()
+		user, err := client.GetUser(s.Login)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", s.Login, err)
+		}
+		if user.Followers >= importMinFollowers {
+			candidates = append(candidates, importer.Candidate{
+				Username: user.Login,
+				Detail:   fmt.Sprintf("%d followers", user.Followers),
+			})
+		}
+	}
+	return importCandidates(fmt.Sprintf("stargazers of %s with %d+ followers", args[0], importMinFollowers), candidates)
+}
+
+func runImportFollowing(cmd *cobra.Command, args []string) error {
+	client, err := importClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Fetching accounts %s follows...\n", args[0])
+	following, err := client.GetUserFollowing(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to fetch following: %w", err)
+	}
+	return importCandidates(fmt.Sprintf("accounts %s follows", args[0]), usersToCandidates(following))
+}
+
+func runImportFile(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	candidates, err := importer.ParseList(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+	return importCandidates(args[0], candidates)
+}
+
+// importCandidates previews what importing candidates would do, then adds
+// and tags them unless --dry-run is set
+func importCandidates(source string, candidates []importer.Candidate) error {
+	tag := ""
+	if importTag != "" {
+		t, err := account.NormalizeTag(importTag)
+		if err != nil {
+			return err
+		}
+		tag = t
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+	accounts, err := accountRepo.ListAll()
+	if err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	plan := importer.NewPlan(accounts, candidates)
+	printImportPlan(source, plan)
+
+	if plan.Total() == 0 {
+		return nil
+	}
+	if len(plan.New) == 0 && len(plan.Reactivate) == 0 && tag == "" {
+		fmt.Println("\nNothing to import.")
+		return nil
+	}
+	if importDryRun {
+		fmt.Println("\nDry run: nothing was changed.")
+		return nil
+	}
+
+	added, tagged := 0, 0
+	err = db.WithTx(func(tx *database.Tx) error {
+		accountRepo := accountRepo.WithTx(tx)
+		for _, c := range plan.New {
+			if _, err := accountRepo.AddFrom(account.SourceImport, c.Username, "", "", ""); err != nil {
+				return fmt.Errorf("failed to add %s: %w", c.Username, err)
+			}
+			added++
+		}
+		for _, c := range plan.Reactivate {
+			a, err := accountRepo.Get(c.Username)
+			if err != nil {
+				return fmt.Errorf("failed to re-add %s: %w", c.Username, err)
+			}
+			if err := accountRepo.Reactivate(a.ID, account.SourceImport); err != nil {
+				return fmt.Errorf("failed to re-add %s: %w", c.Username, err)
+			}
+			added++
+		}
+		if tag == "" {
+			return nil
+		}
+		for _, list := range [][]importer.Candidate{plan.New, plan.Reactivate, plan.Monitored} {
+			for _, c := range list {
+				ok, err := accountRepo.AddTag(c.Username, tag)
+				if err != nil {
+					return fmt.Errorf("failed to tag %s: %w", c.Username, err)
+				}
+				if ok {
+					tagged++
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nImport complete: %d added", added)
+	if tag != "" {
+		fmt.Printf(", %d newly tagged '%s'", tagged, tag)
+	}
+	fmt.Println()
+	if added > 0 {
+		fmt.Println("Run 'ghmon fetch' to pull their activity.")
+	}
+	return nil
+}
+
+func printImportPlan(source string, plan *importer.Plan) {
+	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	if plan.Total() == 0 {
+		fmt.Printf("\nNo accounts found (%s).\n", source)
+		return
+	}
+
+	fmt.Printf("\nFound %d accounts (%s):\n", plan.Total(), source)
+	line := func(style lipgloss.Style, prefix string, c importer.Candidate, note string) {
+		var details []string
+		if c.Detail != "" {
+			details = append(details, c.Detail)
+		}
+		if note != "" {
+			details = append(details, note)
+		}
+		fmt.Printf("  %s", style.Render(prefix+c.Username))
+		if len(details) > 0 {
+			fmt.Printf(" %s", dimStyle.Render("("+strings.Join(details, ", ")+")"))
+		}
+		fmt.Println()
+	}
+	for _, c := range plan.New {
+		line(addStyle, "+ ", c, "")
+	}
+	for _, c := range plan.Reactivate {
+		line(addStyle, "+ ", c, "history kept")
+	}
+	for _, c := range plan.Monitored {
+		line(dimStyle, "= ", c, "already monitored")
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var muteCmd = &cobra.Command{
+	Use:   "mute <username>",
+	Short: "Leave an account out of digests for a while",
+	Long: `Leaves an account out of digests, exports and 'show --tag' without removing it.
+The account keeps being fetched, so nothing is missed once the mute ends.
+
+Without --for the account stays muted until 'ghmon unmute'.`,
+	Example: `  ghmon mute torvalds --for 7d
+  ghmon mute dependabot`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMute,
+}
+
+var muteFor string
+
+func init() {
+	rootCmd.AddCommand(muteCmd)
+	muteCmd.Flags().StringVar(&muteFor, "for", "", "How long to mute for (e.g. 12h, 7d, 2w)")
+}
+
+func runMute(cmd *cobra.Command, args []string) error {
+	username := args[0]
+
+	until := account.MutedForever
+	if muteFor != "" {
+		d, err := parseMuteDuration(muteFor)
+		if err != nil {
+			return err
+		}
+		until = time.Now().Add(d)
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+
+	acc, err := accountRepo.Get(username)
+	if err != nil || acc.RemovedAt != nil {
+		return fmt.Errorf("account '%s' is not being monitored", username)
+	}
+
+	if err := accountRepo.Mute(acc.ID, until); err != nil {
+		return fmt.Errorf("failed to mute account: %w", err)
+	}
+
+	fmt.Printf("Muted %s %s.\n", acc.Username, mutedUntil(until))
+	return nil
+}
+
+// parseMuteDuration parses a Go duration, or a whole number of days (7d) or
+// weeks (2w)
+func parseMuteDuration(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	var d time.Duration
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s' (e.g. 12h, 7d, 2w)", s)
+		}
+		d = time.Duration(n) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid duration '%s' (e.g. 12h, 7d, 2w)", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("--for must be positive")
+	}
+	return d, nil
+}
+
+// mutedUntil describes when a mute ends
+func mutedUntil(until time.Time) string {
+	if !until.Before(account.MutedForever) {
+		return "until unmuted"
+	}
+	return "until " + until.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/retention"
+	"github.com/spf13/cobra"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete activity older than the retention policy",
+	Long: `Deletes rows older than the max ages in the retention section of the
+config, then vacuums the database and reports the space reclaimed. Every
+max age defaults to 0, which keeps rows forever, so nothing is pruned until
+one is set.
+
+Commits are rolled up into monthly per-repository counts before they are
+deleted, and pruned activity stays in the daily counts digests read, so
+long-range totals don't drop. The daemon runs a prune once a day.
+
+  retention:
+    commits_days: 365
+    repos_days: 0        # 0 keeps rows forever
+    stars_days: 365
+    digests_days: 0
+    fetch_runs_days: 90`,
+	RunE: runPrune,
+}
+
+var pruneDryRun bool
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be deleted without deleting anything")
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !cfg.Retention.Enabled() {
+		fmt.Printf("No retention policy configured. Add a retention section to %s (see 'ghmon prune --help').\n", config.ConfigPath())
+		return nil
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	result, err := retention.NewPruner(db).Prune(retentionRules(cfg.Retention), time.Now(), pruneDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to prune database: %w", err)
+	}
+
+	printPruneResult(result)
+	return nil
+}
+
+// retentionRules maps the config's per-table day counts to prune rules
+func retentionRules(cfg config.RetentionConfig) []retention.Rule {
+	day := 24 * time.Hour
+	return []retention.Rule{
+		{Table: "commits", MaxAge: time.Duration(cfg.CommitsDays) * day},
+		{Table: "repos", MaxAge: time.Duration(cfg.ReposDays) * day},
+		{Table: "stars", MaxAge: time.Duration(cfg.StarsDays) * day},
+		{Table: "digests", MaxAge: time.Duration(cfg.DigestsDays) * day},
+		{Table: "fetch_runs", MaxAge: time.Duration(cfg.FetchRunsDays) * day},
+	}
+}
+
+func printPruneResult(result *retention.Result) {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	title := "PRUNE"
+	if result.DryRun {
+		title = "PRUNE (DRY RUN)"
+	}
+	fmt.Printf("\n%s\n\n", titleStyle.Render(title))
+
+	for _, t := range result.Tables {
+		note := ""
+		if t.Table == "commits" && t.Rows > 0 {
+			note = dimStyle.Render(" (rolled up into monthly counts)")
+		}
+		fmt.Printf("  %-12s %6d rows older than %s%s\n", t.Table, t.Rows, t.Cutoff.Local().Format("2006-01-02"), note)
+	}
+	fmt.Println()
+
+	if result.DryRun {
+		fmt.Printf("Would delete %d rows. Run without --dry-run to prune.\n\n", result.Rows())
+		return
+	}
+
+	fmt.Printf("Deleted %d rows, reclaimed %s (%s → %s).\n\n",
+		result.Rows(), formatBytes(result.Reclaimed()), formatBytes(result.BytesBefore), formatBytes(result.BytesAfter))
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
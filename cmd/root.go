@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/julienpequegnot/ghmon/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +13,44 @@ var rootCmd = &cobra.Command{
 	Use:   "ghmon",
 	Short: "Monitor GitHub accounts you follow",
 	Long:  `ghmon tracks activity from GitHub accounts you follow and generates digests.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		format, err := report.ParseFormat(outputFlag)
+		if err != nil {
+			return err
+		}
+		output = format
+		return nil
+	},
+}
+
+var (
+	outputFlag string
+	// output is the validated --output format for the running command
+	output = report.Text
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "text", "Output format: text, json or yaml")
+}
+
+// structuredOutput reports whether results should be encoded instead of rendered as text
+func structuredOutput() bool {
+	return output != report.Text
+}
+
+// writeOutput encodes v to stdout in the selected structured format
+func writeOutput(v interface{}) error {
+	return report.Write(os.Stdout, output, v)
+}
+
+// notice prints an informational message, keeping it off stdout when stdout
+// carries structured output
+func notice(format string, a ...interface{}) {
+	if structuredOutput() {
+		fmt.Fprintf(os.Stderr, format+"\n", a...)
+		return
+	}
+	fmt.Printf(format+"\n", a...)
 }
 
 func Execute() {
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/julienpequegnot/ghmon/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search commit messages and repository descriptions",
+	Long: `Full-text search over commit messages, repository descriptions and the
+descriptions of starred repositories, newest first.
+
+The query supports SQLite full-text syntax: several words match activity
+containing all of them, "quoted phrases" match exactly, OR combines
+alternatives and a trailing * matches prefixes (e.g. 'uring*').`,
+	Example: `  ghmon search io_uring
+  ghmon search "memory allocator" --type commit --since 2025-01-01
+  ghmon search 'wasm OR webassembly' --user torvalds`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+var (
+	searchUser  string
+	searchSince string
+	searchTypes []string
+	searchLimit int
+)
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().StringVar(&searchUser, "user", "", "Only search this account's activity")
+	searchCmd.Flags().StringVar(&searchSince, "since", "", "Only search activity since this date (YYYY-MM-DD)")
+	searchCmd.Flags().StringSliceVar(&searchTypes, "type", nil, "Only search these types: commit, repo, star")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "Maximum number of results")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	q := search.Query{
+		Text:     strings.Join(args, " "),
+		Username: searchUser,
+		Limit:    searchLimit,
+	}
+
+	if searchSince != "" {
+		since, err := time.ParseInLocation("2006-01-02", searchSince, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --since date '%s' (expected YYYY-MM-DD)", searchSince)
+		}
+		q.Since = since
+	}
+
+	for _, t := range searchTypes {
+		kind, err := search.ParseKind(t)
+		if err != nil {
+			return err
+		}
+		q.Kinds = append(q.Kinds, kind)
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	hits, err := search.NewSearcher(db).Search(q)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return writeOutput(report.BuildSearchHits(hits))
+	}
+
+	if len(hits) == 0 {
+		fmt.Printf("No results for '%s'.\n", q.Text)
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	repoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	matchStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+
+	fmt.Printf("\n%s\n\n", titleStyle.Render(fmt.Sprintf("SEARCH: %s (%d results)", q.Text, len(hits))))
+
+	for i := range hits {
+		h := &hits[i]
+		ref := ""
+		if h.SHA != "" {
+			ref = " " + dimStyle.Render(shortSHA(h.SHA))
+		}
+		fmt.Printf("  %-6s %s %s%s %s\n",
+			dimStyle.Render(string(h.Kind)),
+			userStyle.Render(h.Username),
+			repoStyle.Render(h.Repo),
+			ref,
+			dimStyle.Render(h.Date.Local().Format("2006-01-02")))
+
+		snippet := strings.Join(strings.Fields(h.Highlight(func(s string) string { return matchStyle.Render(s) })), " ")
+		if snippet != "" {
+			fmt.Printf("         %s\n", snippet)
+		}
+	}
+
+	fmt.Println()
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
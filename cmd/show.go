@@ -7,29 +7,37 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/julienpequegnot/ghmon/internal/account"
-	"github.com/julienpequegnot/ghmon/internal/activity"
 	"github.com/julienpequegnot/ghmon/internal/config"
 	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/julienpequegnot/ghmon/internal/storage"
 	"github.com/spf13/cobra"
 )
 
 var showCmd = &cobra.Command{
-	Use:   "show <username>",
+	Use:   "show <username> | --tag <tag>",
 	Short: "Show activity for a specific user",
-	Long:  `Displays detailed activity for a monitored GitHub user.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runShow,
+	Long: `Displays detailed activity for a monitored GitHub user, or with --tag for
+each account carrying the tag in turn.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runShow,
 }
 
-var showDays int
+var (
+	showDays int
+	showTags []string
+)
 
 func init() {
 	rootCmd.AddCommand(showCmd)
 	showCmd.Flags().IntVar(&showDays, "days", 7, "Number of days to show")
+	showCmd.Flags().StringSliceVar(&showTags, "tag", nil, "Show every account with any of these tags")
 }
 
 func runShow(cmd *cobra.Command, args []string) error {
-	username := args[0]
+	if (len(args) == 1) == (len(showTags) > 0) {
+		return fmt.Errorf("specify either a username or --tag")
+	}
 
 	db, err := database.New(config.DBPath())
 	if err != nil {
@@ -37,86 +45,107 @@ func runShow(cmd *cobra.Command, args []string) error {
 	}
 	defer db.Close()
 
-	accountRepo := account.NewRepository(db)
-	acc, err := accountRepo.Get(username)
+	since := time.Now().AddDate(0, 0, -showDays)
+	// Asking for an account by name shows it even while muted
+	store, err := filteredStore(db, len(showTags) > 0)
 	if err != nil {
-		return fmt.Errorf("account '%s' not found. Run 'ghmon add %s' first.", username, username)
+		return err
 	}
 
-	since := time.Now().AddDate(0, 0, -showDays)
+	if len(showTags) > 0 {
+		return showTagged(db, store, since)
+	}
 
-	commitRepo := activity.NewCommitRepository(db)
-	repoRepo := activity.NewRepoRepository(db)
-	starRepo := activity.NewStarRepository(db)
+	u, err := report.LoadUserActivity(store, args[0], since, time.Now())
+	if err != nil {
+		return err
+	}
 
-	commits, _ := commitRepo.GetForAccount(acc.ID, since)
-	newRepos, _ := repoRepo.GetNewSince(since)
-	stars, _ := starRepo.GetSince(since)
+	if structuredOutput() {
+		return writeOutput(u)
+	}
 
-	var accountRepos []activity.Repo
-	for _, r := range newRepos {
-		if r.AccountID == acc.ID {
-			accountRepos = append(accountRepos, r)
-		}
+	printUserActivity(u, showDays)
+	return nil
+}
+
+// showTagged shows each tagged account's activity, one after another
+func showTagged(db *database.DB, store *storage.Store, since time.Time) error {
+	accounts, err := taggedAccounts(account.NewRepository(db), showTags)
+	if err != nil {
+		return err
 	}
-	var accountStars []activity.Star
-	for _, s := range stars {
-		if s.AccountID == acc.ID {
-			accountStars = append(accountStars, s)
+
+	users := make([]*report.UserActivity, 0, len(accounts))
+	for _, acc := range accounts {
+		u, err := report.LoadUserActivity(store, acc.Username, since, time.Now())
+		if err != nil {
+			return err
 		}
+		users = append(users, u)
+	}
+
+	if structuredOutput() {
+		return writeOutput(users)
 	}
 
+	for _, u := range users {
+		printUserActivity(u, showDays)
+	}
+	return nil
+}
+
+func printUserActivity(u *report.UserActivity, days int) {
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
 	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
 	repoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 	shaStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
-	name := acc.Name
+	name := u.Name
 	if name == "" {
-		name = acc.Username
+		name = u.Username
 	}
 	fmt.Printf("\n%s\n", titleStyle.Render(name))
-	if acc.Bio != "" {
-		fmt.Printf("%s\n", dimStyle.Render(acc.Bio))
+	if u.Bio != "" {
+		fmt.Printf("%s\n", dimStyle.Render(u.Bio))
 	}
 	fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 
 	fmt.Printf("\n📊 Last %d days: %d commits · %d new repos · %d stars\n\n",
-		showDays, len(commits), len(accountRepos), len(accountStars))
+		days, u.Summary.Commits, u.Summary.NewRepos, u.Summary.Stars)
 
-	if len(commits) > 0 {
+	if len(u.Commits) > 0 {
 		fmt.Printf("%s\n", sectionStyle.Render("📝 Recent Commits"))
 
-		repoCommits := make(map[string][]activity.Commit)
-		for _, c := range commits {
-			repoCommits[c.RepoName] = append(repoCommits[c.RepoName], c)
-		}
-
-		for repo, repoCs := range repoCommits {
-			fmt.Printf("  %s (%d commits)\n", repoStyle.Render(repo), len(repoCs))
+		for _, rc := range u.Commits {
+			fmt.Printf("  %s (%d commits)\n", repoStyle.Render(rc.Repo), len(rc.Commits))
 			limit := 3
-			if len(repoCs) < limit {
-				limit = len(repoCs)
+			if len(rc.Commits) < limit {
+				limit = len(rc.Commits)
 			}
 			for i := 0; i < limit; i++ {
-				c := repoCs[i]
+				c := rc.Commits[i]
 				msg := c.Message
 				if len(msg) > 50 {
 					msg = msg[:47] + "..."
 				}
-				fmt.Printf("    %s %s\n", shaStyle.Render(c.SHA[:7]), msg)
+				sha := c.SHA
+				if len(sha) > 7 {
+					sha = sha[:7]
+				}
+				fmt.Printf("    %s %s\n", shaStyle.Render(sha), msg)
 			}
-			if len(repoCs) > 3 {
-				fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("... and %d more", len(repoCs)-3)))
+			if len(rc.Commits) > 3 {
+				fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("... and %d more", len(rc.Commits)-3)))
 			}
 		}
 		fmt.Println()
 	}
 
-	if len(accountRepos) > 0 {
+	if len(u.NewRepos) > 0 {
 		fmt.Printf("%s\n", sectionStyle.Render("🆕 New Repositories"))
-		for _, repo := range accountRepos {
+		for _, repo := range u.NewRepos {
 			fmt.Printf("  %s\n", repoStyle.Render(repo.FullName))
 			if repo.Description != "" {
 				desc := repo.Description
@@ -132,24 +161,22 @@ func runShow(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
-	if len(accountStars) > 0 {
+	if len(u.Stars) > 0 {
 		fmt.Printf("%s\n", sectionStyle.Render("⭐ Starred Repos"))
 		limit := 10
-		if len(accountStars) < limit {
-			limit = len(accountStars)
+		if len(u.Stars) < limit {
+			limit = len(u.Stars)
 		}
 		for i := 0; i < limit; i++ {
-			s := accountStars[i]
-			fmt.Printf("  %s\n", repoStyle.Render(s.RepoFullName))
-			if s.RepoLanguage != "" {
-				fmt.Printf("    %s · %d ★\n", dimStyle.Render(s.RepoLanguage), s.RepoStars)
+			s := u.Stars[i]
+			fmt.Printf("  %s\n", repoStyle.Render(s.FullName))
+			if s.Language != "" {
+				fmt.Printf("    %s · %d ★\n", dimStyle.Render(s.Language), s.Stars)
 			}
 		}
-		if len(accountStars) > limit {
-			fmt.Printf("  %s\n", dimStyle.Render(fmt.Sprintf("... and %d more", len(accountStars)-limit)))
+		if len(u.Stars) > limit {
+			fmt.Printf("  %s\n", dimStyle.Render(fmt.Sprintf("... and %d more", len(u.Stars)-limit)))
 		}
 		fmt.Println()
 	}
-
-	return nil
 }
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/github"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/julienpequegnot/ghmon/internal/suggest"
+	"github.com/spf13/cobra"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest [user...]",
+	Short: "Suggest accounts to monitor",
+	Long: `Suggests accounts to monitor, scored from what the monitored accounts do:
+owners of repositories several of them starred, contributors to repositories
+they commit to, and accounts several of them follow, especially ones they
+started following recently.
+
+Who each account follows is pulled from GitHub and remembered, so later runs
+can tell new follows apart. --offline only uses what is already stored.
+
+With --add the suggestions shown are added to the monitored accounts, or only
+the ones named.`,
+	Example: `  ghmon suggest
+  ghmon suggest --add
+  ghmon suggest --add robpike ianlancetaylor`,
+	RunE: runSuggest,
+}
+
+var (
+	suggestDays    int
+	suggestLimit   int
+	suggestRepos   int
+	suggestOffline bool
+	suggestAdd     bool
+)
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+	suggestCmd.Flags().IntVar(&suggestDays, "days", 30, "Days of stars and commits to draw suggestions from")
+	suggestCmd.Flags().IntVar(&suggestLimit, "limit", 10, "Number of suggestions to show")
+	suggestCmd.Flags().IntVar(&suggestRepos, "repos", 5, "Number of repositories to look up contributors for")
+	suggestCmd.Flags().BoolVar(&suggestOffline, "offline", false, "Don't call the GitHub API; use stored data only")
+	suggestCmd.Flags().BoolVar(&suggestAdd, "add", false, "Add the suggestions shown, or the ones named")
+}
+
+func runSuggest(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 && !suggestAdd {
+		return fmt.Errorf("usernames can only be given with --add")
+	}
+
+	var client *github.Client
+	if !suggestOffline {
+		c, err := importClient()
+		if err != nil {
+			return fmt.Errorf("%w (or use --offline)", err)
+		}
+		client = c
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+	store, err := filteredStore(db, true)
+	if err != nil {
+		return err
+	}
+
+	since := time.Now().AddDate(0, 0, -suggestDays)
+	in := suggest.Input{Contributors: make(map[string][]suggest.Contributor)}
+	if in.Accounts, err = accountRepo.ListAll(); err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+	if in.Stars, err = store.Stars.GetSince(since); err != nil {
+		return fmt.Errorf("failed to load stars: %w", err)
+	}
+	if in.Commits, err = store.Commits.GetAllSince(since); err != nil {
+		return fmt.Errorf("failed to load commits: %w", err)
+	}
+
+	if client != nil {
+		accounts, err := store.Accounts.List()
+		if err != nil {
+			return fmt.Errorf("failed to list accounts: %w", err)
+		}
+		recordFollowing(client, accountRepo, accounts)
+
+		repos := suggest.SharedRepos(in.Commits, suggestRepos)
+		if len(repos) > 0 {
+			notice("Fetching contributors of %d repositories...", len(repos))
+		}
+		for _, repo := range repos {
+			client.WaitForRateLimit()
+			contributors, err := client.GetRepoContributors(repo, 30)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: couldn't fetch contributors of %s: %v\n", repo, err)
+				continue
+			}
+			for _, c := range contributors {
+				if c.Type == account.TypeUser {
+					in.Contributors[repo] = append(in.Contributors[repo], suggest.Contributor{Login: c.Login, Contributions: c.Contributions})
+				}
+			}
+		}
+	}
+
+	if in.Following, err = accountRepo.Following(); err != nil {
+		return err
+	}
+
+	suggestions := suggest.Rank(in)
+	if len(suggestions) > suggestLimit {
+		suggestions = suggestions[:suggestLimit]
+	}
+
+	if structuredOutput() {
+		if err := writeOutput(report.BuildSuggestions(suggestions)); err != nil {
+			return err
+		}
+	} else {
+		printSuggestions(suggestions)
+	}
+
+	if !suggestAdd {
+		return nil
+	}
+	return addSuggestions(db, client, suggestions, args)
+}
+
+// recordFollowing snapshots who each account follows. Failures only cost the
+// account's snapshot, so they are reported and skipped.
+func recordFollowing(client *github.Client, accountRepo *account.Repository, accounts []account.Account) {
+	notice("Fetching who %d accounts follow...", len(accounts))
+	now := time.Now()
+	for _, acc := range accounts {
+		if acc.IsOrganization() {
+			continue
+		}
+		client.WaitForRateLimit()
+		following, err := client.GetUserFollowing(acc.Username)
+		if err == nil {
+			logins := make([]string, 0, len(following))
+			for _, u := range following {
+				logins = append(logins, u.Login)
+			}
+			_, err = accountRepo.RecordFollowing(acc.ID, logins, now)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: couldn't fetch who %s follows: %v\n", acc.Username, err)
+		}
+	}
+}
+
+func printSuggestions(suggestions []suggest.Suggestion) {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	userStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	if len(suggestions) == 0 {
+		fmt.Println("No suggestions yet. Run 'ghmon fetch' to gather more activity first.")
+		return
+	}
+
+	fmt.Printf("\n%s (%d)\n\n", titleStyle.Render("SUGGESTED ACCOUNTS"), len(suggestions))
+	for _, s := range suggestions {
+		fmt.Printf("  %-20s %s\n", userStyle.Render(s.Username), dimStyle.Render(fmt.Sprintf("score %d", s.Score)))
+		for _, r := range s.Reasons {
+			fmt.Printf("    %s\n", dimStyle.Render("· "+r.Detail))
+		}
+	}
+	fmt.Println()
+	fmt.Println("Run 'ghmon suggest --add [user...]' to start monitoring them.")
+}
+
+// addSuggestions monitors the suggestions, or only the ones in usernames,
+// filling in their profiles when client is set
+func addSuggestions(db *database.DB, client *github.Client, suggestions []suggest.Suggestion, usernames []string) error {
+	suggested := make(map[string]string)
+	for _, s := range suggestions {
+		suggested[strings.ToLower(s.Username)] = s.Username
+	}
+
+	var logins []string
+	if len(usernames) == 0 {
+		for _, s := range suggestions {
+			logins = append(logins, s.Username)
+		}
+	}
+	for _, u := range usernames {
+		login, ok := suggested[strings.ToLower(u)]
+		if !ok {
+			return fmt.Errorf("'%s' is not among the suggestions shown", u)
+		}
+		logins = append(logins, login)
+	}
+	if len(logins) == 0 {
+		return nil
+	}
+
+	profiles := make(map[string]account.Profile)
+	if client != nil {
+		for _, login := range logins {
+			client.WaitForRateLimit()
+			if user, err := client.GetUser(login); err == nil {
+				profiles[login] = profileFromUser(user)
+			}
+		}
+	}
+
+	err := db.WithTx(func(tx *database.Tx) error {
+		accountRepo := account.NewRepository(db).WithTx(tx)
+		for _, login := range logins {
+			acc, err := accountRepo.AddFrom(account.SourceSuggest, login, "", "", "")
+			if err != nil {
+				return fmt.Errorf("failed to add %s: %w", login, err)
+			}
+			if p, ok := profiles[login]; ok {
+				if err := accountRepo.UpdateProfile(acc.ID, p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	notice("Added %d accounts. Run 'ghmon fetch' to pull their activity.", len(logins))
+	return nil
+}
@@ -3,7 +3,9 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/julienpequegnot/ghmon/internal/account"
 	"github.com/julienpequegnot/ghmon/internal/config"
 	"github.com/julienpequegnot/ghmon/internal/database"
@@ -13,16 +15,36 @@ import (
 
 var syncCmd = &cobra.Command{
 	Use:   "sync",
-	Short: "Import accounts from GitHub following",
-	Long:  `Syncs your monitored accounts with your GitHub following list.`,
-	RunE:  runSync,
+	Short: "Sync accounts with your GitHub following list",
+	Long: `Compares your monitored accounts with your GitHub following list, shows
+the difference and applies it.
+
+Followed accounts that are not monitored are added. With --prune, accounts
+that sync added and you no longer follow are removed; --keep-history stops
+monitoring them but keeps their activity for digests and search. Accounts
+added with 'ghmon add' are never pruned. Use --dry-run to only show the
+difference.`,
+	RunE: runSync,
 }
 
+var (
+	syncPrune       bool
+	syncKeepHistory bool
+	syncDryRun      bool
+)
+
 func init() {
 	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().BoolVar(&syncPrune, "prune", false, "Remove accounts you no longer follow")
+	syncCmd.Flags().BoolVar(&syncKeepHistory, "keep-history", false, "With --prune, stop monitoring but keep the accounts' activity")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would change without changing anything")
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
+	if syncKeepHistory && !syncPrune {
+		return fmt.Errorf("--keep-history only applies with --prune")
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config (run 'ghmon init' first): %w", err)
@@ -49,26 +71,115 @@ func runSync(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Found %d accounts you follow.\n", len(following))
 
 	accountRepo := account.NewRepository(db)
-	added := 0
-	skipped := 0
+	accounts, err := accountRepo.ListAll()
+	if err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
 
+	users := make(map[string]github.User)
+	logins := make([]string, 0, len(following))
 	for _, user := range following {
-		if accountRepo.Exists(user.Login) {
-			skipped++
-			continue
-		}
+		users[user.Login] = user
+		logins = append(logins, user.Login)
+	}
 
-		_, err := accountRepo.Add(user.Login, user.Name, user.AvatarURL, user.Bio)
-		if err != nil {
-			fmt.Printf("  Warning: failed to add %s: %v\n", user.Login, err)
-			continue
+	plan := account.PlanSync(accounts, logins)
+	printSyncPlan(plan)
+
+	if !plan.Changes(syncPrune) {
+		fmt.Println("\nAlready in sync.")
+		return nil
+	}
+	if syncDryRun {
+		fmt.Println("\nDry run: nothing was changed.")
+		return nil
+	}
+
+	added, removed := 0, 0
+	err = db.WithTx(func(tx *database.Tx) error {
+		accountRepo := accountRepo.WithTx(tx)
+		for _, login := range plan.Add {
+			user := users[login]
+			if _, err := accountRepo.AddFrom(account.SourceSync, login, user.Name, user.AvatarURL, user.Bio); err != nil {
+				return fmt.Errorf("failed to add %s: %w", login, err)
+			}
+			added++
+		}
+		for _, a := range plan.Reactivate {
+			if err := accountRepo.Reactivate(a.ID, account.SourceSync); err != nil {
+				return fmt.Errorf("failed to re-add %s: %w", a.Username, err)
+			}
+			added++
+		}
+		for _, a := range plan.Claim {
+			if err := accountRepo.SetSource(a.ID, account.SourceSync); err != nil {
+				return fmt.Errorf("failed to update %s: %w", a.Username, err)
+			}
+		}
+		if !syncPrune {
+			return nil
+		}
+		for _, a := range plan.Remove {
+			var err error
+			if syncKeepHistory {
+				err = accountRepo.Deactivate(a.ID)
+			} else {
+				err = accountRepo.Remove(a.Username)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to remove %s: %w", a.Username, err)
+			}
+			removed++
 		}
-		added++
-		fmt.Printf("  + %s\n", user.Login)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("\nSync complete: %d added, %d already tracked\n", added, skipped)
-	fmt.Println("Run 'ghmon fetch' to pull their activity.")
+	fmt.Printf("\nSync complete: %d added, %d removed, %d unchanged\n",
+		added, removed, len(plan.Unchanged)+len(plan.Claim))
+	if added > 0 {
+		fmt.Println("Run 'ghmon fetch' to pull their activity.")
+	}
 
 	return nil
 }
+
+func printSyncPlan(plan *account.SyncPlan) {
+	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	removeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	if n := len(plan.Add) + len(plan.Reactivate); n > 0 {
+		fmt.Printf("\nTo add (%d):\n", n)
+		for _, login := range plan.Add {
+			fmt.Printf("  %s\n", addStyle.Render("+ "+login))
+		}
+		for _, a := range plan.Reactivate {
+			fmt.Printf("  %s %s\n", addStyle.Render("+ "+a.Username), dimStyle.Render("(history kept)"))
+		}
+	}
+
+	if len(plan.Remove) > 0 {
+		if syncPrune {
+			fmt.Printf("\nTo remove (%d):\n", len(plan.Remove))
+		} else {
+			fmt.Printf("\nNo longer followed (%d), run with --prune to remove:\n", len(plan.Remove))
+		}
+		for _, a := range plan.Remove {
+			fmt.Printf("  %s\n", removeStyle.Render("- "+a.Username))
+		}
+	}
+
+	if len(plan.Keep) > 0 {
+		fmt.Printf("\nNot followed but kept, added manually (%d):\n", len(plan.Keep))
+		var names []string
+		for _, a := range plan.Keep {
+			names = append(names, a.Username)
+		}
+		fmt.Printf("  %s\n", dimStyle.Render(strings.Join(names, ", ")))
+	}
+
+	fmt.Printf("\nUnchanged: %d\n", len(plan.Unchanged)+len(plan.Claim))
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Group accounts with tags",
+	Long: `Tags group monitored accounts, e.g. by field. 'digest', 'export', 'show',
+'fetch' and 'accounts' accept --tag to limit them to the accounts carrying a tag.`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <tag> <username...>",
+	Short: "Tag accounts",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagAdd,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <tag> <username...>",
+	Short: "Remove a tag from accounts",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagRemove,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [tag]",
+	Short: "List tags, or the accounts carrying one",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTagList,
+}
+
+var tagImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Tag accounts from a YAML file",
+	Long: `Tags accounts from a YAML file mapping each tag to a list of usernames:
+
+  go:
+    - rsc
+    - bradfitz
+  ml: [karpathy]
+
+Existing tags are kept. Usernames that are not monitored are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTagImport,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+	tagCmd.AddCommand(tagImportCmd)
+}
+
+// taggedAccounts returns the accounts carrying any of tags. A tag no account
+// carries is an error, so a typo doesn't quietly produce an empty report.
+func taggedAccounts(accountRepo *account.Repository, tags []string) ([]account.Account, error) {
+	known, err := accountRepo.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	inUse := make(map[string]bool)
+	for _, t := range known {
+		inUse[t.Tag] = true
+	}
+	for _, tag := range tags {
+		t, err := account.NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !inUse[t] {
+			return nil, fmt.Errorf("no accounts are tagged '%s'. Run 'ghmon tag list' to see tags.", t)
+		}
+	}
+
+	accounts, err := accountRepo.ListByTags(tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tagged accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// normalizeTags lowercases and validates tags given on the command line
+func normalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		t, err := account.NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func runTagAdd(cmd *cobra.Command, args []string) error {
+	tag, err := account.NormalizeTag(args[0])
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+	for _, username := range args[1:] {
+		if !accountRepo.Exists(username) {
+			return fmt.Errorf("account '%s' is not being monitored", username)
+		}
+	}
+
+	for _, username := range args[1:] {
+		added, err := accountRepo.AddTag(username, tag)
+		if err != nil {
+			return fmt.Errorf("failed to tag %s: %w", username, err)
+		}
+		if added {
+			fmt.Printf("Tagged %s with '%s'.\n", username, tag)
+		} else {
+			fmt.Printf("%s is already tagged '%s'.\n", username, tag)
+		}
+	}
+	return nil
+}
+
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	tag, err := account.NormalizeTag(args[0])
+	if err != nil {
+		return err
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+	for _, username := range args[1:] {
+		removed, err := accountRepo.RemoveTag(username, tag)
+		if err != nil {
+			return fmt.Errorf("failed to untag %s: %w", username, err)
+		}
+		if removed {
+			fmt.Printf("Removed tag '%s' from %s.\n", tag, username)
+		} else {
+			fmt.Printf("%s is not tagged '%s'.\n", username, tag)
+		}
+	}
+	return nil
+}
+
+func runTagList(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+
+	if len(args) == 1 {
+		accounts, err := taggedAccounts(accountRepo, args)
+		if err != nil {
+			return err
+		}
+		return printAccounts(accountRepo, accounts)
+	}
+
+	tags, err := accountRepo.ListTags()
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	if structuredOutput() {
+		return writeOutput(report.BuildTags(tags))
+	}
+
+	if len(tags) == 0 {
+		fmt.Println("No tags yet. Run 'ghmon tag add <tag> <username...>' to create one.")
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	tagStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("\n%s\n\n", titleStyle.Render("TAGS"))
+	for _, t := range tags {
+		fmt.Printf("  %-20s %s\n", tagStyle.Render(t.Tag), dimStyle.Render(fmt.Sprintf("%d accounts", t.Accounts)))
+	}
+	fmt.Println()
+	return nil
+}
+
+func runTagImport(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read tag file: %w", err)
+	}
+
+	var groups map[string][]string
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return fmt.Errorf("failed to parse tag file (expected a map of tag to usernames): %w", err)
+	}
+
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		if _, err := account.NormalizeTag(tag); err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	added, existing := 0, 0
+	unknown := make(map[string]bool)
+	err = db.WithTx(func(tx *database.Tx) error {
+		accountRepo := account.NewRepository(db).WithTx(tx)
+		for _, tag := range tags {
+			for _, username := range groups[tag] {
+				if !accountRepo.Exists(username) {
+					unknown[username] = true
+					continue
+				}
+				ok, err := accountRepo.AddTag(username, tag)
+				if err != nil {
+					return fmt.Errorf("failed to tag %s: %w", username, err)
+				}
+				if ok {
+					added++
+				} else {
+					existing++
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d tags from %s: %d added, %d already present.\n", len(tags), args[0], added, existing)
+	if len(unknown) > 0 {
+		var names []string
+		for u := range unknown {
+			names = append(names, u)
+		}
+		sort.Strings(names)
+		fmt.Printf("Skipped %d accounts that are not monitored: %s\n", len(names), strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var unmuteCmd = &cobra.Command{
+	Use:   "unmute <username>",
+	Short: "Include a muted account in digests again",
+	Long:  `Ends a mute from 'ghmon mute' early.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUnmute,
+}
+
+func init() {
+	rootCmd.AddCommand(unmuteCmd)
+}
+
+func runUnmute(cmd *cobra.Command, args []string) error {
+	username := args[0]
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	accountRepo := account.NewRepository(db)
+
+	acc, err := accountRepo.Get(username)
+	if err != nil || acc.RemovedAt != nil {
+		return fmt.Errorf("account '%s' is not being monitored", username)
+	}
+	if !acc.IsMuted(time.Now()) {
+		fmt.Printf("%s is not muted.\n", acc.Username)
+		return nil
+	}
+
+	if err := accountRepo.Unmute(acc.ID); err != nil {
+		return fmt.Errorf("failed to unmute account: %w", err)
+	}
+
+	fmt.Printf("Unmuted %s.\n", acc.Username)
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var unwatchCmd = &cobra.Command{
+	Use:   "unwatch <owner/repo...>",
+	Short: "Stop watching repositories",
+	Long:  `Stops watching repositories and deletes their recorded events and star history.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runUnwatch,
+}
+
+func init() {
+	rootCmd.AddCommand(unwatchCmd)
+}
+
+func runUnwatch(cmd *cobra.Command, args []string) error {
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	watchRepo := watch.NewRepository(db)
+
+	var names []string
+	for _, arg := range args {
+		name, err := watch.ParseFullName(arg)
+		if err != nil {
+			return err
+		}
+		if !watchRepo.Exists(name) {
+			return fmt.Errorf("repository '%s' is not watched", name)
+		}
+		names = append(names, name)
+	}
+
+	err = db.WithTx(func(tx *database.Tx) error {
+		watchRepo := watchRepo.WithTx(tx)
+		for _, name := range names {
+			if err := watchRepo.Remove(name); err != nil {
+				return fmt.Errorf("failed to unwatch %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		fmt.Printf("Stopped watching %s.\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/github"
+	"github.com/julienpequegnot/ghmon/internal/report"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [owner/repo...]",
+	Short: "Watch repositories regardless of who contributes",
+	Long: `Watches repositories whatever accounts contribute to them. 'ghmon fetch'
+pulls their pushes, releases, pull requests, issues and star count, and
+digests and exports show them under "Watched repositories".
+
+Without arguments, lists the watched repositories. Use 'ghmon unwatch' to
+stop watching one.`,
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		name, err := watch.ParseFullName(arg)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config (run 'ghmon init' first): %w", err)
+	}
+
+	db, err := database.New(config.DBPath())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	watchRepo := watch.NewRepository(db)
+
+	if len(names) == 0 {
+		return printWatched(watchRepo)
+	}
+
+	for _, name := range names {
+		if watchRepo.Exists(name) {
+			return fmt.Errorf("repository '%s' is already watched", name)
+		}
+	}
+
+	var client *github.Client
+	if cfg.GitHub.Token != "" {
+		client = github.NewClient(cfg.GitHub.Token)
+	}
+
+	for _, name := range names {
+		var description, language string
+		var stars int
+		if client != nil {
+			repo, err := client.GetRepo(name)
+			if github.IsStatus(err, http.StatusNotFound) {
+				return fmt.Errorf("repository '%s' not found on GitHub", name)
+			}
+			if err != nil {
+				fmt.Printf("Warning: couldn't fetch repository info: %v\n", err)
+			} else {
+				// Keep GitHub's spelling of the name
+				name = repo.FullName
+				description, language, stars = repo.Description, repo.Language, repo.Stars
+			}
+		}
+
+		if _, err := watchRepo.Add(name, description, language, stars); err != nil {
+			return fmt.Errorf("failed to watch repository: %w", err)
+		}
+		fmt.Printf("Watching %s.\n", name)
+	}
+	fmt.Println("Run 'ghmon fetch' to pull their activity.")
+
+	return nil
+}
+
+func printWatched(watchRepo *watch.Repository) error {
+	repos, err := watchRepo.List()
+	if err != nil {
+		return fmt.Errorf("failed to list watched repositories: %w", err)
+	}
+
+	if structuredOutput() {
+		return writeOutput(report.BuildWatches(repos))
+	}
+
+	if len(repos) == 0 {
+		fmt.Println("No repositories watched yet. Run 'ghmon watch <owner/repo>' to watch one.")
+		return nil
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	repoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	fmt.Printf("\n%s (%d)\n\n", titleStyle.Render("WATCHED REPOSITORIES"), len(repos))
+
+	for _, r := range repos {
+		fmt.Printf("  %s %s\n", repoStyle.Render(r.FullName), dimStyle.Render(fmt.Sprintf("★ %d", r.Stars)))
+		if r.Description != "" {
+			desc := r.Description
+			if len(desc) > 60 {
+				desc = desc[:57] + "..."
+			}
+			fmt.Printf("    %s\n", dimStyle.Render(desc))
+		}
+	}
+
+	fmt.Println()
+	return nil
+}
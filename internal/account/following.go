@@ -0,0 +1,100 @@
+package account
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// Follow is an account a monitored account follows
+type Follow struct {
+	Login     string
+	FirstSeen time.Time
+	// New is set when the follow wasn't in the account's first snapshot, so
+	// the account started following Login since ghmon has been looking
+	New bool
+}
+
+// RecordFollowing replaces an account's following snapshot with logins,
+// keeping when each one was first seen. It returns how many logins are new
+// since the last snapshot.
+func (r *Repository) RecordFollowing(accountID int64, logins []string, at time.Time) (int, error) {
+	added := 0
+	err := r.inTx(func(ex database.Executor) error {
+		known := make(map[string]bool)
+		rows, err := ex.Query("SELECT login FROM account_following WHERE account_id = ?", accountID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var login string
+			if err := rows.Scan(&login); err != nil {
+				rows.Close()
+				return err
+			}
+			known[strings.ToLower(login)] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		current := make(map[string]bool)
+		for _, login := range logins {
+			key := strings.ToLower(login)
+			if current[key] {
+				continue
+			}
+			current[key] = true
+			if known[key] {
+				continue
+			}
+			if _, err := ex.Exec("INSERT INTO account_following (account_id, login, first_seen) VALUES (?, ?, ?)", accountID, login, at.UTC()); err != nil {
+				return err
+			}
+			added++
+		}
+
+		for login := range known {
+			if current[login] {
+				continue
+			}
+			if _, err := ex.Exec("DELETE FROM account_following WHERE account_id = ? AND login = ?", accountID, login); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record following: %w", err)
+	}
+	return added, nil
+}
+
+// Following returns the last following snapshot of every account that has
+// one, by account ID
+func (r *Repository) Following() (map[int64][]Follow, error) {
+	rows, err := r.db.Query(`
+		SELECT f.account_id, f.login, f.first_seen,
+			f.first_seen > (SELECT MIN(first_seen) FROM account_following WHERE account_id = f.account_id)
+		FROM account_following f
+		ORDER BY f.account_id, f.login
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load following: %w", err)
+	}
+	defer rows.Close()
+
+	following := make(map[int64][]Follow)
+	for rows.Next() {
+		var accountID int64
+		var f Follow
+		if err := rows.Scan(&accountID, &f.Login, &f.FirstSeen, &f.New); err != nil {
+			return nil, err
+		}
+		following[accountID] = append(following[accountID], f)
+	}
+	return following, rows.Err()
+}
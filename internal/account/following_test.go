@@ -0,0 +1,41 @@
+package account
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordFollowing(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	rsc, _ := repo.Add("rsc", "", "", "")
+	first := time.Now().Add(-48 * time.Hour)
+
+	if added, err := repo.RecordFollowing(rsc.ID, []string{"robpike", "ianlancetaylor", "RobPike"}, first); err != nil || added != 2 {
+		t.Fatalf("expected 2 follows recorded, got %d, %v", added, err)
+	}
+	if added, _ := repo.RecordFollowing(rsc.ID, []string{"robpike", "bradfitz"}, first.Add(24*time.Hour)); added != 1 {
+		t.Errorf("expected only bradfitz to be new, got %d", added)
+	}
+
+	following, err := repo.Following()
+	if err != nil {
+		t.Fatalf("failed to load following: %v", err)
+	}
+	follows := following[rsc.ID]
+	if len(follows) != 2 {
+		t.Fatalf("expected the unfollowed account to be dropped, got %+v", follows)
+	}
+	for _, f := range follows {
+		if f.New != (f.Login == "bradfitz") {
+			t.Errorf("expected only bradfitz to be a new follow, got %+v", f)
+		}
+	}
+
+	repo.Remove("rsc")
+	if following, _ := repo.Following(); len(following) != 0 {
+		t.Errorf("expected removing the account to drop its snapshot, got %+v", following)
+	}
+}
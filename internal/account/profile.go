@@ -0,0 +1,70 @@
+package account
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Profile is the part of an account copied from its GitHub profile
+type Profile struct {
+	Type      string
+	Name      string
+	AvatarURL string
+	Bio       string
+	Followers int
+	Following int
+}
+
+// ProfileChange is a profile field whose value changed
+type ProfileChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// Profile returns the account's stored profile
+func (a *Account) Profile() Profile {
+	return Profile{
+		Type:      a.Type,
+		Name:      a.Name,
+		AvatarURL: a.AvatarURL,
+		Bio:       a.Bio,
+		Followers: a.Followers,
+		Following: a.Following,
+	}
+}
+
+// Diff lists the fields that differ from p to other, in a fixed order. An
+// empty Type in other means it is unknown and never counts as a change.
+func (p Profile) Diff(other Profile) []ProfileChange {
+	var changes []ProfileChange
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, ProfileChange{Field: field, From: from, To: to})
+		}
+	}
+
+	if other.Type != "" {
+		add("type", p.Type, other.Type)
+	}
+	add("name", p.Name, other.Name)
+	add("avatar_url", p.AvatarURL, other.AvatarURL)
+	add("bio", p.Bio, other.Bio)
+	add("followers", strconv.Itoa(p.Followers), strconv.Itoa(other.Followers))
+	add("following", strconv.Itoa(p.Following), strconv.Itoa(other.Following))
+	return changes
+}
+
+// UpdateProfile stores a profile pulled from GitHub. An empty Type keeps the
+// stored one.
+func (r *Repository) UpdateProfile(id int64, p Profile) error {
+	_, err := r.db.Exec(`
+		UPDATE accounts
+		SET type = COALESCE(NULLIF(?, ''), type), name = ?, avatar_url = ?, bio = ?, followers = ?, following = ?
+		WHERE id = ?
+	`, p.Type, p.Name, p.AvatarURL, p.Bio, p.Followers, p.Following, id)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+	return nil
+}
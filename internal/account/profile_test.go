@@ -0,0 +1,42 @@
+package account
+
+import "testing"
+
+func TestProfileDiff(t *testing.T) {
+	old := Profile{Type: TypeUser, Name: "Russ", Bio: "Go", Followers: 0, Following: 3}
+	changes := old.Diff(Profile{Name: "Russ Cox", Bio: "Go", Followers: 12000, Following: 3})
+
+	if len(changes) != 2 {
+		t.Fatalf("expected name and followers to change, got %+v", changes)
+	}
+	if changes[0] != (ProfileChange{Field: "name", From: "Russ", To: "Russ Cox"}) {
+		t.Errorf("unexpected name change: %+v", changes[0])
+	}
+	if changes[1] != (ProfileChange{Field: "followers", From: "0", To: "12000"}) {
+		t.Errorf("unexpected followers change: %+v", changes[1])
+	}
+
+	if changes := old.Diff(Profile{Type: TypeOrganization, Name: "Russ", Bio: "Go", Following: 3}); len(changes) != 1 || changes[0].Field != "type" {
+		t.Errorf("expected a type change, got %+v", changes)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	acc, _ := repo.Add("golang", "", "", "")
+	repo.SetType(acc.ID, TypeOrganization)
+
+	err := repo.UpdateProfile(acc.ID, Profile{Name: "Go", AvatarURL: "https://example.com/go.png", Bio: "The Go language", Followers: 5000})
+	if err != nil {
+		t.Fatalf("failed to update profile: %v", err)
+	}
+
+	got, _ := repo.Get("golang")
+	want := Profile{Type: TypeOrganization, Name: "Go", AvatarURL: "https://example.com/go.png", Bio: "The Go language", Followers: 5000}
+	if got.Profile() != want {
+		t.Errorf("expected %+v, got %+v", want, got.Profile())
+	}
+}
@@ -8,6 +8,21 @@ import (
 	"github.com/julienpequegnot/ghmon/internal/database"
 )
 
+// Sources record how an account came to be monitored. Accounts added before
+// sources were recorded have an empty source.
+const (
+	SourceManual  = "manual"
+	SourceSync    = "sync"
+	SourceImport  = "import"
+	SourceSuggest = "suggest"
+)
+
+// Types are GitHub's account types
+const (
+	TypeUser         = "User"
+	TypeOrganization = "Organization"
+)
+
 type Account struct {
 	ID          int64
 	Username    string
@@ -18,20 +33,70 @@ type Account struct {
 	Following   int
 	AddedAt     time.Time
 	LastFetched *time.Time
+	Type        string
+	Source      string
+	// RemovedAt is set when the account stopped being monitored but its
+	// history was kept
+	RemovedAt *time.Time
+	// MutedUntil is set while the account is left out of digests
+	MutedUntil *time.Time
+}
+
+// MutedForever is the MutedUntil of an account muted until it is unmuted
+var MutedForever = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// accountColumns are the columns scanAccount reads, in order
+const accountColumns = `id, username, name, avatar_url, bio, followers, following, added_at, last_fetched, type, COALESCE(source, ''), removed_at, muted_until`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAccount(row scanner) (*Account, error) {
+	var a Account
+	if err := row.Scan(&a.ID, &a.Username, &a.Name, &a.AvatarURL, &a.Bio, &a.Followers, &a.Following, &a.AddedAt, &a.LastFetched, &a.Type, &a.Source, &a.RemovedAt, &a.MutedUntil); err != nil {
+		return nil, err
+	}
+	return &a, nil
 }
 
 type Repository struct {
-	db *database.DB
+	db database.Executor
+	// conn is set when the repository owns its connection, so multi-statement
+	// operations can open their own transaction
+	conn *database.DB
 }
 
 func NewRepository(db *database.DB) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: db, conn: db}
+}
+
+// WithTx returns a repository that reads and writes through tx
+func (r *Repository) WithTx(tx *database.Tx) *Repository {
+	return &Repository{db: tx}
 }
 
+// inTx runs fn in a new transaction, or directly on the current one when the
+// repository is already bound to a transaction
+func (r *Repository) inTx(fn func(ex database.Executor) error) error {
+	if r.conn == nil {
+		return fn(r.db)
+	}
+	return r.conn.WithTx(func(tx *database.Tx) error {
+		return fn(tx)
+	})
+}
+
+// Add adds an account the user asked to monitor
 func (r *Repository) Add(username, name, avatarURL, bio string) (*Account, error) {
+	return r.AddFrom(SourceManual, username, name, avatarURL, bio)
+}
+
+// AddFrom adds an account, recording where it came from
+func (r *Repository) AddFrom(source, username, name, avatarURL, bio string) (*Account, error) {
 	result, err := r.db.Exec(
-		`INSERT INTO accounts (username, name, avatar_url, bio) VALUES (?, ?, ?, ?)`,
-		username, name, avatarURL, bio,
+		`INSERT INTO accounts (username, name, avatar_url, bio, source) VALUES (?, ?, ?, ?, ?)`,
+		username, name, avatarURL, bio, source,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("account already exists or error: %w", err)
@@ -42,6 +107,8 @@ func (r *Repository) Add(username, name, avatarURL, bio string) (*Account, error
 		ID:       id,
 		Username: username,
 		Name:     name,
+		Type:     TypeUser,
+		Source:   source,
 	}, nil
 }
 
@@ -52,19 +119,50 @@ func (r *Repository) Remove(username string) error {
 		return fmt.Errorf("account not found: %w", err)
 	}
 
-	r.db.Exec("DELETE FROM commits WHERE account_id = ?", id)
-	r.db.Exec("DELETE FROM repos WHERE account_id = ?", id)
-	r.db.Exec("DELETE FROM stars WHERE account_id = ?", id)
-
-	_, err = r.db.Exec("DELETE FROM accounts WHERE id = ?", id)
-	return err
+	return r.inTx(func(ex database.Executor) error {
+		for _, query := range []string{
+			"DELETE FROM commits WHERE account_id = ?",
+			"DELETE FROM commit_rollups WHERE account_id = ?",
+			"DELETE FROM repos WHERE account_id = ?",
+			"DELETE FROM stars WHERE account_id = ?",
+			"DELETE FROM daily_activity WHERE account_id = ?",
+			"DELETE FROM daily_repo_commits WHERE account_id = ?",
+			"DELETE FROM daily_repo_stars WHERE account_id = ?",
+			"DELETE FROM backfill_tasks WHERE account_id = ?",
+			"DELETE FROM account_tags WHERE account_id = ?",
+			"DELETE FROM account_following WHERE account_id = ?",
+			"DELETE FROM accounts WHERE id = ?",
+		} {
+			if _, err := ex.Exec(query, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
+// List returns the monitored accounts, ordered by username
 func (r *Repository) List() ([]Account, error) {
-	rows, err := r.db.Query(`
-		SELECT id, username, name, avatar_url, bio, followers, following, added_at, last_fetched
-		FROM accounts ORDER BY username
+	return r.list(`SELECT ` + accountColumns + ` FROM accounts WHERE removed_at IS NULL ORDER BY username`)
+}
+
+// ListByStaleness returns monitored accounts ordered by last fetch,
+// never-fetched first
+func (r *Repository) ListByStaleness() ([]Account, error) {
+	return r.list(`
+		SELECT ` + accountColumns + ` FROM accounts WHERE removed_at IS NULL
+		ORDER BY last_fetched IS NOT NULL, last_fetched, username
 	`)
+}
+
+// ListAll returns every account, including those removed with their
+// history kept, ordered by username
+func (r *Repository) ListAll() ([]Account, error) {
+	return r.list(`SELECT ` + accountColumns + ` FROM accounts ORDER BY username`)
+}
+
+func (r *Repository) list(query string, args ...interface{}) ([]Account, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,43 +170,73 @@ func (r *Repository) List() ([]Account, error) {
 
 	var accounts []Account
 	for rows.Next() {
-		var a Account
-		var lastFetched *time.Time
-		if err := rows.Scan(&a.ID, &a.Username, &a.Name, &a.AvatarURL, &a.Bio, &a.Followers, &a.Following, &a.AddedAt, &lastFetched); err != nil {
+		a, err := scanAccount(rows)
+		if err != nil {
 			return nil, err
 		}
-		a.LastFetched = lastFetched
-		accounts = append(accounts, a)
+		accounts = append(accounts, *a)
 	}
 	return accounts, rows.Err()
 }
 
+// IsOrganization reports whether the account is an organization
+func (a *Account) IsOrganization() bool {
+	return a.Type == TypeOrganization
+}
+
+// IsMuted reports whether the account is muted at a time
+func (a *Account) IsMuted(at time.Time) bool {
+	return a.MutedUntil != nil && at.Before(*a.MutedUntil)
+}
+
+// IsStale reports whether the account has not been fetched within the given duration
+func (a *Account) IsStale(staleFor time.Duration) bool {
+	return a.LastFetched == nil || time.Since(*a.LastFetched) >= staleFor
+}
+
 func (r *Repository) Get(username string) (*Account, error) {
-	var a Account
-	var lastFetched *time.Time
-	err := r.db.QueryRow(`
-		SELECT id, username, name, avatar_url, bio, followers, following, added_at, last_fetched
-		FROM accounts WHERE username = ?
-	`, username).Scan(&a.ID, &a.Username, &a.Name, &a.AvatarURL, &a.Bio, &a.Followers, &a.Following, &a.AddedAt, &lastFetched)
-	if err != nil {
-		return nil, err
-	}
-	a.LastFetched = lastFetched
-	return &a, nil
+	return scanAccount(r.db.QueryRow(`SELECT `+accountColumns+` FROM accounts WHERE username = ?`, username))
 }
 
 func (r *Repository) GetByID(id int64) (*Account, error) {
-	var a Account
-	var lastFetched *time.Time
-	err := r.db.QueryRow(`
-		SELECT id, username, name, avatar_url, bio, followers, following, added_at, last_fetched
-		FROM accounts WHERE id = ?
-	`, id).Scan(&a.ID, &a.Username, &a.Name, &a.AvatarURL, &a.Bio, &a.Followers, &a.Following, &a.AddedAt, &lastFetched)
-	if err != nil {
-		return nil, err
-	}
-	a.LastFetched = lastFetched
-	return &a, nil
+	return scanAccount(r.db.QueryRow(`SELECT `+accountColumns+` FROM accounts WHERE id = ?`, id))
+}
+
+// SetType records whether an account is a user or an organization
+func (r *Repository) SetType(id int64, accountType string) error {
+	_, err := r.db.Exec("UPDATE accounts SET type = ? WHERE id = ?", accountType, id)
+	return err
+}
+
+// SetSource records where an account came from
+func (r *Repository) SetSource(id int64, source string) error {
+	_, err := r.db.Exec("UPDATE accounts SET source = ? WHERE id = ?", source, id)
+	return err
+}
+
+// Deactivate stops monitoring an account but keeps it and its history, so
+// past digests and searches still include it
+func (r *Repository) Deactivate(id int64) error {
+	_, err := r.db.Exec("UPDATE accounts SET removed_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	return err
+}
+
+// Reactivate resumes monitoring an account removed by Deactivate, recording
+// where it came from this time
+func (r *Repository) Reactivate(id int64, source string) error {
+	_, err := r.db.Exec("UPDATE accounts SET removed_at = NULL, source = ? WHERE id = ?", source, id)
+	return err
+}
+
+// Mute leaves an account out of digests until a time, or MutedForever
+func (r *Repository) Mute(id int64, until time.Time) error {
+	_, err := r.db.Exec("UPDATE accounts SET muted_until = ? WHERE id = ?", until, id)
+	return err
+}
+
+func (r *Repository) Unmute(id int64) error {
+	_, err := r.db.Exec("UPDATE accounts SET muted_until = NULL WHERE id = ?", id)
+	return err
 }
 
 func (r *Repository) UpdateLastFetched(id int64) error {
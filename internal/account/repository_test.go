@@ -4,6 +4,7 @@ package account
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/julienpequegnot/ghmon/internal/database"
 )
@@ -87,3 +88,81 @@ func TestRemoveAccount(t *testing.T) {
 		t.Errorf("expected 0 accounts after removal, got %d", len(accounts))
 	}
 }
+
+func TestListByStaleness(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	fresh, _ := repo.Add("torvalds", "Linus", "", "")
+	repo.Add("antirez", "Salvatore", "", "")
+	repo.UpdateLastFetched(fresh.ID)
+
+	accounts, err := repo.ListByStaleness()
+	if err != nil {
+		t.Fatalf("failed to list accounts: %v", err)
+	}
+
+	if len(accounts) != 2 || accounts[0].Username != "antirez" {
+		t.Fatalf("expected never-fetched account first, got %+v", accounts)
+	}
+	if !accounts[0].IsStale(time.Hour) {
+		t.Error("expected never-fetched account to be stale")
+	}
+	if accounts[1].IsStale(time.Hour) {
+		t.Error("expected just-fetched account to be fresh")
+	}
+}
+
+func TestSetType(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	acc, _ := repo.Add("golang", "The Go Programming Language", "", "")
+
+	got, _ := repo.Get("golang")
+	if got.Type != TypeUser || got.IsOrganization() {
+		t.Errorf("expected new accounts to be users, got %q", got.Type)
+	}
+
+	if err := repo.SetType(acc.ID, TypeOrganization); err != nil {
+		t.Fatalf("failed to set type: %v", err)
+	}
+	got, _ = repo.Get("golang")
+	if !got.IsOrganization() {
+		t.Errorf("expected an organization, got %q", got.Type)
+	}
+}
+
+func TestMute(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	acc, _ := repo.Add("torvalds", "Linus Torvalds", "", "")
+	now := time.Now()
+
+	if err := repo.Mute(acc.ID, now.Add(7*24*time.Hour)); err != nil {
+		t.Fatalf("failed to mute: %v", err)
+	}
+	got, _ := repo.Get("torvalds")
+	if !got.IsMuted(now) || got.IsMuted(now.Add(8*24*time.Hour)) {
+		t.Errorf("expected a mute lasting a week, got %v", got.MutedUntil)
+	}
+
+	repo.Mute(acc.ID, MutedForever)
+	got, _ = repo.Get("torvalds")
+	if !got.IsMuted(now.AddDate(100, 0, 0)) {
+		t.Error("expected a mute without end to last")
+	}
+
+	if err := repo.Unmute(acc.ID); err != nil {
+		t.Fatalf("failed to unmute: %v", err)
+	}
+	got, _ = repo.Get("torvalds")
+	if got.IsMuted(now) || got.MutedUntil != nil {
+		t.Errorf("expected account to be unmuted, got %v", got.MutedUntil)
+	}
+}
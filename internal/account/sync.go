@@ -0,0 +1,78 @@
+package account
+
+import (
+	"sort"
+	"strings"
+)
+
+// SyncPlan is the difference between the accounts ghmon knows about and the
+// user's GitHub following list
+type SyncPlan struct {
+	// Add are followed usernames that are not monitored yet
+	Add []string
+	// Reactivate are followed accounts that were removed with their history kept
+	Reactivate []Account
+	// Claim are followed accounts with no recorded source; sync adopts them
+	Claim []Account
+	// Remove are accounts sync added that are no longer followed
+	Remove []Account
+	// Keep are accounts that are not followed but were added some other way,
+	// so pruning never removes them
+	Keep []Account
+	// Unchanged are followed accounts that are already monitored
+	Unchanged []Account
+}
+
+// PlanSync compares accounts (including removed ones, see ListAll) with the
+// usernames the user follows. GitHub usernames are case-insensitive.
+func PlanSync(accounts []Account, following []string) *SyncPlan {
+	plan := &SyncPlan{}
+
+	known := make(map[string]Account)
+	for _, a := range accounts {
+		known[strings.ToLower(a.Username)] = a
+	}
+
+	followed := make(map[string]bool)
+	for _, login := range following {
+		key := strings.ToLower(login)
+		if followed[key] {
+			continue
+		}
+		followed[key] = true
+
+		a, ok := known[key]
+		switch {
+		case !ok:
+			plan.Add = append(plan.Add, login)
+		case a.RemovedAt != nil:
+			plan.Reactivate = append(plan.Reactivate, a)
+		case a.Source == "":
+			plan.Claim = append(plan.Claim, a)
+		default:
+			plan.Unchanged = append(plan.Unchanged, a)
+		}
+	}
+
+	for _, a := range accounts {
+		if a.RemovedAt != nil || followed[strings.ToLower(a.Username)] {
+			continue
+		}
+		if a.Source == SourceSync {
+			plan.Remove = append(plan.Remove, a)
+		} else {
+			plan.Keep = append(plan.Keep, a)
+		}
+	}
+
+	sort.Strings(plan.Add)
+	for _, list := range [][]Account{plan.Reactivate, plan.Claim, plan.Remove, plan.Keep, plan.Unchanged} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Username < list[j].Username })
+	}
+	return plan
+}
+
+// Changes reports whether applying the plan with prune would change anything
+func (p *SyncPlan) Changes(prune bool) bool {
+	return len(p.Add) > 0 || len(p.Reactivate) > 0 || len(p.Claim) > 0 || (prune && len(p.Remove) > 0)
+}
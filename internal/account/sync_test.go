@@ -0,0 +1,87 @@
+package account
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanSync(t *testing.T) {
+	removed := time.Now()
+	accounts := []Account{
+		{Username: "Torvalds", Source: SourceSync},
+		{Username: "antirez", Source: SourceSync},
+		{Username: "rsc", Source: SourceManual},
+		{Username: "bellard"},
+		{Username: "gvanrossum"},
+		{Username: "karpathy", Source: SourceSync, RemovedAt: &removed},
+	}
+	following := []string{"torvalds", "bellard", "karpathy", "mitchellh", "mitchellh"}
+
+	plan := PlanSync(accounts, following)
+
+	names := func(list []Account) []string {
+		var out []string
+		for _, a := range list {
+			out = append(out, a.Username)
+		}
+		return out
+	}
+	check := func(what string, got []string, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("%s = %v, want %v", what, got, want)
+			return
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s = %v, want %v", what, got, want)
+				return
+			}
+		}
+	}
+
+	check("add", plan.Add, "mitchellh")
+	check("reactivate", names(plan.Reactivate), "karpathy")
+	check("claim", names(plan.Claim), "bellard")
+	check("remove", names(plan.Remove), "antirez")
+	check("keep", names(plan.Keep), "gvanrossum", "rsc")
+	check("unchanged", names(plan.Unchanged), "Torvalds")
+
+	if !plan.Changes(false) {
+		t.Error("expected additions to count as changes")
+	}
+	if PlanSync(accounts[:1], []string{"torvalds"}).Changes(true) {
+		t.Error("expected an in-sync plan to have no changes")
+	}
+}
+
+func TestDeactivateAndReactivate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	acc, _ := repo.AddFrom(SourceSync, "antirez", "", "", "")
+	repo.Add("torvalds", "", "", "")
+
+	if err := repo.Deactivate(acc.ID); err != nil {
+		t.Fatalf("failed to deactivate: %v", err)
+	}
+	if accounts, _ := repo.List(); len(accounts) != 1 || accounts[0].Username != "torvalds" {
+		t.Errorf("expected removed account hidden from List, got %+v", accounts)
+	}
+	if all, _ := repo.ListAll(); len(all) != 2 {
+		t.Errorf("expected ListAll to include removed accounts, got %+v", all)
+	}
+	got, _ := repo.Get("antirez")
+	if got.RemovedAt == nil || got.Source != SourceSync {
+		t.Errorf("unexpected removed account: %+v", got)
+	}
+
+	if err := repo.Reactivate(acc.ID, SourceManual); err != nil {
+		t.Fatalf("failed to reactivate: %v", err)
+	}
+	got, _ = repo.Get("antirez")
+	if got.RemovedAt != nil || got.Source != SourceManual {
+		t.Errorf("unexpected reactivated account: %+v", got)
+	}
+}
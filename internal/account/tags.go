@@ -0,0 +1,129 @@
+package account
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// TagCount is a tag and the number of accounts carrying it
+type TagCount struct {
+	Tag      string
+	Accounts int
+}
+
+// NormalizeTag lowercases a tag and checks it only uses letters, digits,
+// dots, dashes and underscores
+func NormalizeTag(tag string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(t) {
+		return "", fmt.Errorf("invalid tag '%s' (use letters, digits, '.', '-' and '_')", tag)
+	}
+	return t, nil
+}
+
+// AddTag tags an account and reports whether it was not tagged already
+func (r *Repository) AddTag(username, tag string) (bool, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return false, err
+	}
+
+	var id int64
+	if err := r.db.QueryRow("SELECT id FROM accounts WHERE username = ?", username).Scan(&id); err != nil {
+		return false, fmt.Errorf("account not found: %w", err)
+	}
+
+	result, err := r.db.Exec(`INSERT OR IGNORE INTO account_tags (account_id, tag) VALUES (?, ?)`, id, tag)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// RemoveTag untags an account and reports whether it carried the tag
+func (r *Repository) RemoveTag(username, tag string) (bool, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.Exec(`
+		DELETE FROM account_tags
+		WHERE tag = ? AND account_id = (SELECT id FROM accounts WHERE username = ?)
+	`, tag, username)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ListTags returns every tag in use with its number of accounts
+func (r *Repository) ListTags() ([]TagCount, error) {
+	rows, err := r.db.Query(`
+		SELECT tag, COUNT(*) FROM account_tags GROUP BY tag ORDER BY tag
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []TagCount
+	for rows.Next() {
+		var t TagCount
+		if err := rows.Scan(&t.Tag, &t.Accounts); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// TagsByAccount returns each tagged account's tags, sorted, keyed by account ID
+func (r *Repository) TagsByAccount() (map[int64][]string, error) {
+	rows, err := r.db.Query(`SELECT account_id, tag FROM account_tags ORDER BY account_id, tag`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var id int64
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	return tags, rows.Err()
+}
+
+// ListByTags returns the accounts carrying any of the given tags, ordered
+// by username
+func (r *Repository) ListByTags(tags []string) ([]Account, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		t, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, t)
+	}
+
+	return r.list(`
+		SELECT `+accountColumns+`
+		FROM accounts
+		WHERE removed_at IS NULL
+			AND id IN (SELECT account_id FROM account_tags WHERE tag IN (?`+strings.Repeat(", ?", len(args)-1)+`))
+		ORDER BY username
+	`, args...)
+}
@@ -0,0 +1,82 @@
+package account
+
+import "testing"
+
+func TestNormalizeTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"go":         "go",
+		" ML ":       "ml",
+		"go-tooling": "go-tooling",
+		"sec_ops.v2": "sec_ops.v2",
+	} {
+		got, err := NormalizeTag(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "two words", "-leading", "a/b"} {
+		if _, err := NormalizeTag(in); err == nil {
+			t.Errorf("expected NormalizeTag(%q) to fail", in)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	repo.Add("rsc", "", "", "")
+	repo.Add("karpathy", "", "", "")
+	repo.Add("torvalds", "", "", "")
+
+	if added, err := repo.AddTag("rsc", "Go"); err != nil || !added {
+		t.Fatalf("expected tag to be added, got %v, %v", added, err)
+	}
+	if added, _ := repo.AddTag("rsc", "go"); added {
+		t.Error("expected duplicate tag to be ignored")
+	}
+	repo.AddTag("rsc", "compilers")
+	repo.AddTag("karpathy", "ml")
+	if _, err := repo.AddTag("nobody", "go"); err == nil {
+		t.Error("expected tagging an unknown account to fail")
+	}
+
+	tags, err := repo.ListTags()
+	if err != nil {
+		t.Fatalf("failed to list tags: %v", err)
+	}
+	if len(tags) != 3 || tags[0].Tag != "compilers" || tags[1].Tag != "go" || tags[1].Accounts != 1 {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+
+	accounts, err := repo.ListByTags([]string{"go", "ml"})
+	if err != nil {
+		t.Fatalf("failed to list tagged accounts: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Username != "karpathy" || accounts[1].Username != "rsc" {
+		t.Errorf("unexpected tagged accounts: %+v", accounts)
+	}
+
+	byAccount, _ := repo.TagsByAccount()
+	rsc, _ := repo.Get("rsc")
+	if got := byAccount[rsc.ID]; len(got) != 2 || got[0] != "compilers" || got[1] != "go" {
+		t.Errorf("unexpected tags for rsc: %v", got)
+	}
+
+	if removed, _ := repo.RemoveTag("rsc", "go"); !removed {
+		t.Error("expected tag to be removed")
+	}
+	if removed, _ := repo.RemoveTag("rsc", "go"); removed {
+		t.Error("expected removing a missing tag to report false")
+	}
+
+	// Removing an account drops its tags
+	if err := repo.Remove("karpathy"); err != nil {
+		t.Fatalf("failed to remove account: %v", err)
+	}
+	if accounts, _ := repo.ListByTags([]string{"ml"}); len(accounts) != 0 {
+		t.Errorf("expected no accounts tagged ml, got %+v", accounts)
+	}
+}
@@ -2,9 +2,12 @@
 package activity
 
 import (
+	"database/sql"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/julienpequegnot/ghmon/internal/analysis"
 	"github.com/julienpequegnot/ghmon/internal/database"
 )
 
@@ -14,7 +17,11 @@ type Commit struct {
 	RepoName    string
 	SHA         string
 	Message     string
+	Author      analysis.CommitAuthor
 	CommittedAt time.Time
+	// Automated is why ClassifyCommit flags the commit, "" if it looks
+	// hand-written
+	Automated string
 }
 
 // UserCommitActivity holds commit stats per user
@@ -26,51 +33,89 @@ type UserCommitActivity struct {
 }
 
 type CommitRepository struct {
-	db *database.DB
+	db   database.Executor
+	feed *Feed
 }
 
 func NewCommitRepository(db *database.DB) *CommitRepository {
 	return &CommitRepository{db: db}
 }
 
-func (r *CommitRepository) Add(accountID int64, repoName, sha, message string, committedAt time.Time) error {
-	_, err := r.db.Exec(
-		`INSERT OR IGNORE INTO commits (account_id, repo_name, sha, message, committed_at) VALUES (?, ?, ?, ?, ?)`,
-		accountID, repoName, sha, message, committedAt,
+// WithTx returns a repository that writes through tx, sharing this one's feed
+func (r *CommitRepository) WithTx(tx *database.Tx) *CommitRepository {
+	return &CommitRepository{db: tx, feed: r.feed}
+}
+
+// SetFeed publishes a NewCommit change to feed for every inserted commit
+func (r *CommitRepository) SetFeed(feed *Feed) {
+	r.feed = feed
+}
+
+// Add stores a commit whose author isn't known and reports whether it was
+// new; duplicates are ignored
+func (r *CommitRepository) Add(accountID int64, repoName, sha, message string, committedAt time.Time) (bool, error) {
+	return r.AddAuthored(accountID, repoName, sha, message, analysis.CommitAuthor{}, committedAt)
+}
+
+// AddAuthored stores a commit with its git author, classifying it with
+// ClassifyCommit, and reports whether it was new; duplicates are ignored
+func (r *CommitRepository) AddAuthored(accountID int64, repoName, sha, message string, author analysis.CommitAuthor, committedAt time.Time) (bool, error) {
+	automated := analysis.ClassifyCommit(author, message)
+	result, err := r.db.Exec(
+		`INSERT OR IGNORE INTO commits (account_id, repo_name, sha, message, author_name, author_email, automated, committed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		accountID, repoName, sha, message, author.Name, author.Email, automated, committedAt,
 	)
-	return err
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	id, _ := result.LastInsertId()
+	change := Change{
+		Kind:      NewCommit,
+		AccountID: accountID,
+		Commit: &Commit{
+			ID:          id,
+			AccountID:   accountID,
+			RepoName:    repoName,
+			SHA:         sha,
+			Message:     message,
+			Author:      author,
+			CommittedAt: committedAt,
+			Automated:   automated,
+		},
+	}
+	r.db.OnCommit(func() { r.feed.Publish(change) })
+	return true, nil
 }
 
 func (r *CommitRepository) GetForAccount(accountID int64, since time.Time) ([]Commit, error) {
-	rows, err := r.db.Query(`
-		SELECT id, account_id, repo_name, sha, message, committed_at
+	return r.query(`
+		SELECT `+commitColumns+`
 		FROM commits
 		WHERE account_id = ? AND committed_at >= ?
 		ORDER BY committed_at DESC
 	`, accountID, since)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var commits []Commit
-	for rows.Next() {
-		var c Commit
-		if err := rows.Scan(&c.ID, &c.AccountID, &c.RepoName, &c.SHA, &c.Message, &c.CommittedAt); err != nil {
-			return nil, err
-		}
-		commits = append(commits, c)
-	}
-	return commits, rows.Err()
 }
 
 func (r *CommitRepository) GetAllSince(since time.Time) ([]Commit, error) {
-	rows, err := r.db.Query(`
-		SELECT id, account_id, repo_name, sha, message, committed_at
+	return r.query(`
+		SELECT `+commitColumns+`
 		FROM commits
 		WHERE committed_at >= ?
 		ORDER BY committed_at DESC
 	`, since)
+}
+
+const commitColumns = `id, account_id, repo_name, sha, message, author_name, author_email, automated, committed_at`
+
+// query scans commits selected with commitColumns. Commits stored before
+// they were classified on insert are classified as they are read.
+func (r *CommitRepository) query(query string, args ...interface{}) ([]Commit, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -79,54 +124,94 @@ func (r *CommitRepository) GetAllSince(since time.Time) ([]Commit, error) {
 	var commits []Commit
 	for rows.Next() {
 		var c Commit
-		if err := rows.Scan(&c.ID, &c.AccountID, &c.RepoName, &c.SHA, &c.Message, &c.CommittedAt); err != nil {
+		var automated sql.NullString
+		if err := rows.Scan(&c.ID, &c.AccountID, &c.RepoName, &c.SHA, &c.Message,
+			&c.Author.Name, &c.Author.Email, &automated, &c.CommittedAt); err != nil {
 			return nil, err
 		}
+		c.Automated = automated.String
+		if !automated.Valid {
+			c.Automated = analysis.ClassifyCommit(c.Author, c.Message)
+		}
 		commits = append(commits, c)
 	}
 	return commits, rows.Err()
 }
 
-func (r *CommitRepository) CountByAccount(since time.Time) (map[int64]int, error) {
-	rows, err := r.db.Query(`
-		SELECT account_id, COUNT(*) as count
-		FROM commits
-		WHERE committed_at >= ?
-		GROUP BY account_id
-		ORDER BY count DESC
-	`, since)
+// CountByAccount returns the number of commits per account since a time,
+// leaving out the accounts in exclude
+func (r *CommitRepository) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	return countByAccount(r.db, "commits", "commits", "committed_at", "1", since, exclude)
+}
+
+// HumanCountByAccount is CountByAccount for the commits ClassifyCommit
+// doesn't flag as automated. Commits still unclassified aren't counted; see
+// ClassifyPending.
+func (r *CommitRepository) HumanCountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	return countByAccount(r.db, "human_commits", "commits", "committed_at", "automated = ''", since, exclude)
+}
+
+// ClassifyPending classifies the commits stored before commits were
+// classified on insert, once, and returns how many it classified
+func ClassifyPending(db *database.DB) (int, error) {
+	var pending []Commit
+	rows, err := db.Query(`SELECT id, message, author_name, author_email FROM commits WHERE automated IS NULL`)
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("failed to list unclassified commits: %w", err)
 	}
-	defer rows.Close()
-
-	counts := make(map[int64]int)
 	for rows.Next() {
-		var accountID int64
-		var count int
-		if err := rows.Scan(&accountID, &count); err != nil {
-			return nil, err
+		var c Commit
+		if err := rows.Scan(&c.ID, &c.Message, &c.Author.Name, &c.Author.Email); err != nil {
+			rows.Close()
+			return 0, err
 		}
-		counts[accountID] = count
+		pending = append(pending, c)
 	}
-	return counts, rows.Err()
+	rows.Close()
+	if err := rows.Err(); err != nil || len(pending) == 0 {
+		return 0, err
+	}
+
+	err = db.WithTx(func(tx *database.Tx) error {
+		for _, c := range pending {
+			if _, err := tx.Exec(`UPDATE commits SET automated = ? WHERE id = ?`, analysis.ClassifyCommit(c.Author, c.Message), c.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to classify commits: %w", err)
+	}
+	return len(pending), nil
 }
 
-// GetUserActivity returns commit activity grouped by user with repo details
-func (r *CommitRepository) GetUserActivity(since time.Time, limit int) ([]UserCommitActivity, error) {
+// GetUserActivity returns commit activity grouped by user with repo details,
+// leaving out the accounts in exclude
+func (r *CommitRepository) GetUserActivity(since time.Time, limit int, exclude ...int64) ([]UserCommitActivity, error) {
+	w := newRollupWindow(since)
+	cond, args := w.partial("committed_at")
+	except, exceptArgs := excluding("account_id", exclude)
+
+	queryArgs := append([]interface{}{w.firstDay}, exceptArgs...)
+	queryArgs = append(append(queryArgs, args...), exceptArgs...)
+	queryArgs = append(queryArgs, limit)
 	rows, err := r.db.Query(`
 		SELECT
-			c.account_id,
+			x.account_id,
 			a.username,
-			COUNT(*) as commit_count,
-			GROUP_CONCAT(DISTINCT c.repo_name) as repos
-		FROM commits c
-		JOIN accounts a ON c.account_id = a.id
-		WHERE c.committed_at >= ?
-		GROUP BY c.account_id
+			SUM(x.n) as commit_count,
+			GROUP_CONCAT(DISTINCT x.repo_name) as repos
+		FROM (
+			SELECT account_id, repo_name, commits AS n FROM daily_repo_commits WHERE day >= ? AND commits > 0 AND `+except+`
+			UNION ALL
+			SELECT account_id, repo_name, 1 FROM commits WHERE `+cond+` AND `+except+`
+		) x
+		JOIN accounts a ON x.account_id = a.id
+		GROUP BY x.account_id
 		ORDER BY commit_count DESC
 		LIMIT ?
-	`, since, limit)
+	`, queryArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,124 @@
+package activity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	// Activity rows reference an account
+	if _, err := db.Exec(`INSERT INTO accounts (id, username) VALUES (1, 'testuser')`); err != nil {
+		t.Fatalf("failed to create test account: %v", err)
+	}
+	return db
+}
+
+func TestAddCommitReportsInsert(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewCommitRepository(db)
+	feed := NewFeed()
+	repo.SetFeed(feed)
+
+	var changes []Change
+	feed.Subscribe(func(c Change) { changes = append(changes, c) })
+
+	inserted, err := repo.Add(1, "torvalds/linux", "abc123", "fix", time.Now())
+	if err != nil {
+		t.Fatalf("failed to add commit: %v", err)
+	}
+	if !inserted {
+		t.Error("expected first add to insert")
+	}
+
+	inserted, err = repo.Add(1, "torvalds/linux", "abc123", "fix", time.Now())
+	if err != nil {
+		t.Fatalf("failed to add duplicate commit: %v", err)
+	}
+	if inserted {
+		t.Error("expected duplicate add not to insert")
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(changes))
+	}
+	if changes[0].Kind != NewCommit || changes[0].Commit.SHA != "abc123" {
+		t.Errorf("unexpected change: %+v", changes[0])
+	}
+}
+
+func TestAddWithoutFeed(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewStarRepository(db)
+	inserted, err := repo.Add(1, "golang/go", "", "Go", 1, time.Now())
+	if err != nil || !inserted {
+		t.Errorf("expected insert without feed, got %v, %v", inserted, err)
+	}
+}
+
+func TestHumanCountByAccount(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewCommitRepository(db)
+	now := time.Now()
+	dependabot := analysis.CommitAuthor{Name: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com"}
+	repo.AddAuthored(1, "testuser/app", "h1", "Add feature", analysis.CommitAuthor{Name: "Test User"}, now)
+	repo.AddAuthored(1, "testuser/app", "b1", "Fix typo", dependabot, now)
+	repo.Add(1, "testuser/app", "m1", "Merge branch 'main'", now)
+	// Old commits, partly in rollups and partly in the partial first day
+	repo.Add(1, "testuser/app", "o1", "Add tests", now.AddDate(0, 0, -3))
+
+	commits, _ := repo.GetForAccount(1, now.Add(-time.Minute))
+	for _, c := range commits {
+		if c.SHA == "b1" && (c.Automated != analysis.AutomatedBot || c.Author != dependabot) {
+			t.Errorf("expected the pushed commit's author to be kept and flagged, got %+v", c)
+		}
+	}
+
+	for _, since := range []time.Time{now.Add(-time.Minute), now.AddDate(0, 0, -7)} {
+		total, _ := repo.CountByAccount(since)
+		human, _ := repo.HumanCountByAccount(since)
+		wantTotal, wantHuman := 3, 1
+		if since.Before(now.AddDate(0, 0, -3)) {
+			wantTotal, wantHuman = 4, 2
+		}
+		if total[1] != wantTotal || human[1] != wantHuman {
+			t.Errorf("since %v: got %d commits, %d human, want %d, %d", since, total[1], human[1], wantTotal, wantHuman)
+		}
+	}
+
+	// Commits stored before classification on insert are classified once
+	if _, err := db.Exec(`UPDATE commits SET automated = NULL`); err != nil {
+		t.Fatalf("failed to clear classification: %v", err)
+	}
+	if human, _ := repo.HumanCountByAccount(now.AddDate(0, 0, -7)); human[1] != 0 {
+		t.Errorf("expected unclassified commits not to count, got %v", human)
+	}
+	if n, err := ClassifyPending(db); err != nil || n != 4 {
+		t.Fatalf("expected 4 commits classified, got %d (%v)", n, err)
+	}
+	if human, _ := repo.HumanCountByAccount(now.AddDate(0, 0, -7)); human[1] != 2 {
+		t.Errorf("expected 2 human commits once classified, got %v", human)
+	}
+	if n, _ := ClassifyPending(db); n != 0 {
+		t.Errorf("expected nothing left to classify, got %d", n)
+	}
+
+	db.Exec(`DELETE FROM commits WHERE sha = 'h1'`)
+	if human, _ := repo.HumanCountByAccount(now.AddDate(0, 0, -7)); human[1] != 1 {
+		t.Errorf("expected deleting a human commit to update the rollup, got %v", human)
+	}
+}
@@ -0,0 +1,62 @@
+package activity
+
+import "sync"
+
+// ChangeKind identifies the type of row a Change describes
+type ChangeKind int
+
+const (
+	NewCommit ChangeKind = iota
+	NewRepo
+	NewStar
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case NewCommit:
+		return "commit"
+	case NewRepo:
+		return "repo"
+	case NewStar:
+		return "star"
+	}
+	return "unknown"
+}
+
+// Change is published when a repository actually inserts a new row.
+// Exactly one of Commit, Repo or Star is set, matching Kind.
+type Change struct {
+	Kind      ChangeKind
+	AccountID int64
+	Commit    *Commit
+	Repo      *Repo
+	Star      *Star
+}
+
+// Feed fans out changes to in-process subscribers. Subscribers are called
+// synchronously from the inserting goroutine and must be safe for concurrent use.
+type Feed struct {
+	mu          sync.RWMutex
+	subscribers []func(Change)
+}
+
+func NewFeed() *Feed {
+	return &Feed{}
+}
+
+func (f *Feed) Subscribe(fn func(Change)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscribers = append(f.subscribers, fn)
+}
+
+func (f *Feed) Publish(c Change) {
+	if f == nil {
+		return
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, fn := range f.subscribers {
+		fn(c)
+	}
+}
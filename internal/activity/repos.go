@@ -19,28 +19,89 @@ type Repo struct {
 }
 
 type RepoRepository struct {
-	db *database.DB
+	db   database.Executor
+	feed *Feed
 }
 
 func NewRepoRepository(db *database.DB) *RepoRepository {
 	return &RepoRepository{db: db}
 }
 
-func (r *RepoRepository) Add(accountID int64, name, fullName, description, language string, stars int, createdAt time.Time) error {
-	_, err := r.db.Exec(
+// WithTx returns a repository that writes through tx, sharing this one's feed
+func (r *RepoRepository) WithTx(tx *database.Tx) *RepoRepository {
+	return &RepoRepository{db: tx, feed: r.feed}
+}
+
+// SetFeed publishes a NewRepo change to feed for every inserted repo
+func (r *RepoRepository) SetFeed(feed *Feed) {
+	r.feed = feed
+}
+
+// Add stores a repo and reports whether it was new; duplicates are ignored
+func (r *RepoRepository) Add(accountID int64, name, fullName, description, language string, stars int, createdAt time.Time) (bool, error) {
+	result, err := r.db.Exec(
 		`INSERT OR IGNORE INTO repos (account_id, name, full_name, description, language, stars, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		accountID, name, fullName, description, language, stars, createdAt,
 	)
-	return err
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	id, _ := result.LastInsertId()
+	change := Change{
+		Kind:      NewRepo,
+		AccountID: accountID,
+		Repo: &Repo{
+			ID:          id,
+			AccountID:   accountID,
+			Name:        name,
+			FullName:    fullName,
+			Description: description,
+			Language:    language,
+			Stars:       stars,
+			CreatedAt:   createdAt,
+		},
+	}
+	r.db.OnCommit(func() { r.feed.Publish(change) })
+	return true, nil
 }
 
 func (r *RepoRepository) GetNewSince(since time.Time) ([]Repo, error) {
-	rows, err := r.db.Query(`
+	return r.query(`
 		SELECT id, account_id, name, full_name, description, language, stars, created_at
 		FROM repos
 		WHERE created_at >= ?
 		ORDER BY created_at DESC
 	`, since)
+}
+
+// GetForAccount returns the repos one account created since a time
+func (r *RepoRepository) GetForAccount(accountID int64, since time.Time) ([]Repo, error) {
+	return r.query(`
+		SELECT id, account_id, name, full_name, description, language, stars, created_at
+		FROM repos
+		WHERE account_id = ? AND created_at >= ?
+		ORDER BY created_at DESC
+	`, accountID, since)
+}
+
+// GetForAccounts returns the repos the accounts in accountIDs created since a
+// time, in one query
+func (r *RepoRepository) GetForAccounts(accountIDs []int64, since time.Time) ([]Repo, error) {
+	in, args := among("account_id", accountIDs)
+	return r.query(`
+		SELECT id, account_id, name, full_name, description, language, stars, created_at
+		FROM repos
+		WHERE `+in+` AND created_at >= ?
+		ORDER BY created_at DESC
+	`, append(args, since)...)
+}
+
+func (r *RepoRepository) query(query string, args ...interface{}) ([]Repo, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -56,3 +117,9 @@ func (r *RepoRepository) GetNewSince(since time.Time) ([]Repo, error) {
 	}
 	return repos, rows.Err()
 }
+
+// CountByAccount returns the number of repos per account since a time,
+// leaving out the accounts in exclude
+func (r *RepoRepository) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	return countByAccount(r.db, "repos", "repos", "created_at", "1", since, exclude)
+}
@@ -0,0 +1,104 @@
+package activity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// rollupWindow splits the period from since to now into the partial UTC day
+// that since falls in, which is counted from the raw rows, and the whole days
+// after it, which are read from the daily rollup tables
+type rollupWindow struct {
+	since time.Time
+	// firstDay is the first whole day, as stored in the rollups' day column
+	firstDay string
+	// scanFrom and scanTo bound the raw rows examined for the partial day,
+	// padded so timestamps stored with any UTC offset are covered
+	scanFrom time.Time
+	scanTo   time.Time
+}
+
+func newRollupWindow(since time.Time) rollupWindow {
+	since = since.UTC()
+	next := time.Date(since.Year(), since.Month(), since.Day()+1, 0, 0, 0, 0, time.UTC)
+	return rollupWindow{
+		since:    since,
+		firstDay: next.Format("2006-01-02"),
+		scanFrom: since.Add(-24 * time.Hour),
+		scanTo:   next.Add(24 * time.Hour),
+	}
+}
+
+// partial returns a condition selecting rows whose column falls between
+// since and the first whole day, with its arguments. The plain range lets
+// SQLite use the column's index; date() and datetime() then compare in UTC
+// like the rollup triggers do.
+func (w rollupWindow) partial(column string) (string, []interface{}) {
+	cond := fmt.Sprintf("%[1]s >= ? AND %[1]s < ? AND datetime(%[1]s) >= datetime(?) AND date(%[1]s) < ?", column)
+	return cond, []interface{}{w.scanFrom, w.scanTo, w.since, w.firstDay}
+}
+
+// excluding returns a condition leaving out the accounts in ids, with its
+// arguments, to AND onto a query. It is always true when ids is empty.
+func excluding(column string, ids []int64) (string, []interface{}) {
+	if len(ids) == 0 {
+		return "1", nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return fmt.Sprintf("%s NOT IN (?%s)", column, strings.Repeat(", ?", len(ids)-1)), args
+}
+
+// among returns a condition selecting the accounts in ids, with its
+// arguments, to AND onto a query. It is always false when ids is empty.
+func among(column string, ids []int64) (string, []interface{}) {
+	if len(ids) == 0 {
+		return "0", nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return fmt.Sprintf("%s IN (?%s)", column, strings.Repeat(", ?", len(ids)-1)), args
+}
+
+// countByAccount sums one daily_activity counter per account since a time,
+// topping up the partial first day from the raw table's rows matching match
+func countByAccount(db database.Executor, counter, table, column, match string, since time.Time, exclude []int64) (map[int64]int, error) {
+	w := newRollupWindow(since)
+	cond, args := w.partial(column)
+	except, exceptArgs := excluding("account_id", exclude)
+
+	query := fmt.Sprintf(`
+		SELECT account_id, SUM(n) AS count FROM (
+			SELECT account_id, %[1]s AS n FROM daily_activity WHERE day >= ? AND %[1]s > 0 AND %[4]s
+			UNION ALL
+			SELECT account_id, COUNT(*) FROM %[2]s WHERE %[3]s AND %[4]s AND %[5]s GROUP BY account_id
+		)
+		GROUP BY account_id
+		ORDER BY count DESC
+	`, counter, table, cond, except, match)
+	queryArgs := append([]interface{}{w.firstDay}, exceptArgs...)
+	queryArgs = append(append(queryArgs, args...), exceptArgs...)
+	rows, err := db.Query(query, queryArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var accountID int64
+		var count int
+		if err := rows.Scan(&accountID, &count); err != nil {
+			return nil, err
+		}
+		counts[accountID] = count
+	}
+	return counts, rows.Err()
+}
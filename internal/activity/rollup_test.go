@@ -0,0 +1,169 @@
+package activity
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRollupCountsMatchRawRows(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	commits := NewCommitRepository(db)
+	repos := NewRepoRepository(db)
+	stars := NewStarRepository(db)
+
+	// Spread activity over a few days in a zone east of UTC, so local and
+	// UTC days differ, with several rows on the day since falls in
+	zone := time.FixedZone("UTC+5", 5*60*60)
+	start := time.Date(2026, 3, 10, 0, 0, 0, 0, zone)
+	var times []time.Time
+	for i := 0; i < 40; i++ {
+		at := start.Add(time.Duration(i) * 3 * time.Hour)
+		times = append(times, at)
+		repo := fmt.Sprintf("testuser/repo%d", i%3)
+		if _, err := commits.Add(1, repo, fmt.Sprintf("sha%d", i), "change", at); err != nil {
+			t.Fatalf("failed to add commit: %v", err)
+		}
+		if i%4 == 0 {
+			if _, err := repos.Add(1, fmt.Sprintf("new%d", i), fmt.Sprintf("testuser/new%d", i), "", "Go", 0, at); err != nil {
+				t.Fatalf("failed to add repo: %v", err)
+			}
+		}
+		if i%2 == 0 {
+			if _, err := stars.Add(1, fmt.Sprintf("other/star%d", i), "", "Go", 0, at); err != nil {
+				t.Fatalf("failed to add star: %v", err)
+			}
+		}
+	}
+
+	check := func(since time.Time) {
+		t.Helper()
+		wantCommits, wantRepos, wantStars := 0, 0, 0
+		for i, at := range times {
+			if at.Before(since) {
+				continue
+			}
+			wantCommits++
+			if i%4 == 0 {
+				wantRepos++
+			}
+			if i%2 == 0 {
+				wantStars++
+			}
+		}
+
+		for _, c := range []struct {
+			name  string
+			count func(time.Time, ...int64) (map[int64]int, error)
+			want  int
+		}{
+			{"commits", commits.CountByAccount, wantCommits},
+			{"repos", repos.CountByAccount, wantRepos},
+			{"stars", stars.CountByAccount, wantStars},
+		} {
+			counts, err := c.count(since)
+			if err != nil {
+				t.Fatalf("failed to count %s: %v", c.name, err)
+			}
+			if counts[1] != c.want {
+				t.Errorf("%s since %v = %d, want %d", c.name, since, counts[1], c.want)
+			}
+		}
+
+		activity, err := commits.GetUserActivity(since, 10)
+		if err != nil {
+			t.Fatalf("failed to get user activity: %v", err)
+		}
+		got := 0
+		if len(activity) > 0 {
+			got = activity[0].Count
+		}
+		if got != wantCommits {
+			t.Errorf("user activity since %v = %d commits, want %d", since, got, wantCommits)
+		}
+	}
+
+	// Boundaries at midnight, mid-day and between rows, in both zones
+	for _, since := range []time.Time{
+		start.Add(-24 * time.Hour),
+		start,
+		start.Add(13*time.Hour + 30*time.Minute),
+		start.Add(48 * time.Hour).UTC(),
+		start.Add(70*time.Hour + time.Second),
+		start.Add(30 * 24 * time.Hour),
+	} {
+		check(since)
+	}
+
+	// Deleted rows are taken out of the rollups too
+	if _, err := db.Exec(`DELETE FROM commits WHERE sha IN ('sha1', 'sha2', 'sha20')`); err != nil {
+		t.Fatalf("failed to delete commits: %v", err)
+	}
+	counts, err := commits.CountByAccount(start)
+	if err != nil {
+		t.Fatalf("failed to count commits: %v", err)
+	}
+	if counts[1] != 37 {
+		t.Errorf("commits after delete = %d, want 37", counts[1])
+	}
+
+	// Excluded accounts are left out of the rollups and the partial day alike
+	since := start.Add(13*time.Hour + 30*time.Minute)
+	if counts, _ := commits.CountByAccount(since, 1); len(counts) != 0 {
+		t.Errorf("expected the excluded account to be left out, got %v", counts)
+	}
+	if activity, _ := commits.GetUserActivity(since, 10, 1); len(activity) != 0 {
+		t.Errorf("expected no activity for the excluded account, got %+v", activity)
+	}
+	if counts, _ := stars.CountByAccount(since, 2); counts[1] == 0 {
+		t.Error("expected excluding another account to keep this one")
+	}
+}
+
+func TestTrendingReposFromRollup(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	db.Exec(`INSERT INTO accounts (id, username) VALUES (2, 'other'), (3, 'third')`)
+
+	stars := NewStarRepository(db)
+	start := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	stars.Add(1, "golang/go", "Old description", "Go", 0, start)
+	stars.Add(2, "golang/go", "The Go programming language", "Go", 0, start.AddDate(0, 0, 3))
+	stars.Add(3, "golang/go", "The Go programming language", "Go", 0, start.AddDate(0, 0, 5))
+	stars.Add(1, "rsc/quote", "", "Go", 0, start.AddDate(0, 0, 1))
+	stars.Add(2, "rsc/quote", "", "Go", 0, start.Add(2*time.Hour))
+
+	for _, tt := range []struct {
+		since time.Time
+		want  map[string]int
+	}{
+		{start.Add(-time.Hour), map[string]int{"golang/go": 3, "rsc/quote": 2}},
+		// Mid-day: the first star is before since, the second in the partial day
+		{start.Add(time.Hour), map[string]int{"golang/go": 2, "rsc/quote": 2}},
+		{start.AddDate(0, 0, 2), map[string]int{"golang/go": 2}},
+	} {
+		trending, err := stars.GetTrendingRepos(tt.since, 2)
+		if err != nil {
+			t.Fatalf("failed to get trending repos: %v", err)
+		}
+		got := make(map[string]int)
+		for _, tr := range trending {
+			got[tr.RepoFullName] = tr.StarCount
+			if len(tr.StarredBy) != tr.StarCount {
+				t.Errorf("expected one username per account, got %v", tr.StarredBy)
+			}
+			if tr.RepoFullName == "golang/go" && tr.RepoDescription != "The Go programming language" {
+				t.Errorf("expected the latest description, got %q", tr.RepoDescription)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("trending since %v = %v, want %v", tt.since, got, tt.want)
+		}
+	}
+
+	if trending, _ := stars.GetTrendingRepos(start.Add(-time.Hour), 2, 3); len(trending) != 2 || trending[0].StarCount != 2 {
+		t.Errorf("expected the excluded account's star left out, got %+v", trending)
+	}
+}
@@ -28,28 +28,88 @@ type TrendingRepo struct {
 }
 
 type StarRepository struct {
-	db *database.DB
+	db   database.Executor
+	feed *Feed
 }
 
 func NewStarRepository(db *database.DB) *StarRepository {
 	return &StarRepository{db: db}
 }
 
-func (r *StarRepository) Add(accountID int64, repoFullName, description, language string, stars int, starredAt time.Time) error {
-	_, err := r.db.Exec(
+// WithTx returns a repository that writes through tx, sharing this one's feed
+func (r *StarRepository) WithTx(tx *database.Tx) *StarRepository {
+	return &StarRepository{db: tx, feed: r.feed}
+}
+
+// SetFeed publishes a NewStar change to feed for every inserted star
+func (r *StarRepository) SetFeed(feed *Feed) {
+	r.feed = feed
+}
+
+// Add stores a star and reports whether it was new; duplicates are ignored
+func (r *StarRepository) Add(accountID int64, repoFullName, description, language string, stars int, starredAt time.Time) (bool, error) {
+	result, err := r.db.Exec(
 		`INSERT OR IGNORE INTO stars (account_id, repo_full_name, repo_description, repo_language, repo_stars, starred_at) VALUES (?, ?, ?, ?, ?, ?)`,
 		accountID, repoFullName, description, language, stars, starredAt,
 	)
-	return err
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	id, _ := result.LastInsertId()
+	change := Change{
+		Kind:      NewStar,
+		AccountID: accountID,
+		Star: &Star{
+			ID:              id,
+			AccountID:       accountID,
+			RepoFullName:    repoFullName,
+			RepoDescription: description,
+			RepoLanguage:    language,
+			RepoStars:       stars,
+			StarredAt:       starredAt,
+		},
+	}
+	r.db.OnCommit(func() { r.feed.Publish(change) })
+	return true, nil
 }
 
 func (r *StarRepository) GetSince(since time.Time) ([]Star, error) {
-	rows, err := r.db.Query(`
+	return r.query(`
 		SELECT id, account_id, repo_full_name, repo_description, repo_language, repo_stars, starred_at
 		FROM stars
 		WHERE starred_at >= ?
 		ORDER BY starred_at DESC
 	`, since)
+}
+
+// GetForAccount returns the repos one account starred since a time
+func (r *StarRepository) GetForAccount(accountID int64, since time.Time) ([]Star, error) {
+	return r.query(`
+		SELECT id, account_id, repo_full_name, repo_description, repo_language, repo_stars, starred_at
+		FROM stars
+		WHERE account_id = ? AND starred_at >= ?
+		ORDER BY starred_at DESC
+	`, accountID, since)
+}
+
+// GetForAccounts returns the repos the accounts in accountIDs starred since a
+// time, in one query
+func (r *StarRepository) GetForAccounts(accountIDs []int64, since time.Time) ([]Star, error) {
+	in, args := among("account_id", accountIDs)
+	return r.query(`
+		SELECT id, account_id, repo_full_name, repo_description, repo_language, repo_stars, starred_at
+		FROM stars
+		WHERE `+in+` AND starred_at >= ?
+		ORDER BY starred_at DESC
+	`, append(args, since)...)
+}
+
+func (r *StarRepository) query(query string, args ...interface{}) ([]Star, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -66,23 +126,40 @@ func (r *StarRepository) GetSince(since time.Time) ([]Star, error) {
 	return stars, rows.Err()
 }
 
-// GetTrendingRepos returns repos starred by multiple followed accounts
-func (r *StarRepository) GetTrendingRepos(since time.Time, minStars int) ([]TrendingRepo, error) {
+// GetTrendingRepos returns repos starred by multiple followed accounts,
+// leaving out the accounts in exclude. Whole days are read from the daily
+// rollup, so long ranges stay cheap.
+func (r *StarRepository) GetTrendingRepos(since time.Time, minStars int, exclude ...int64) ([]TrendingRepo, error) {
+	w := newRollupWindow(since)
+	cond, args := w.partial("starred_at")
+	except, exceptArgs := excluding("account_id", exclude)
+
+	queryArgs := append([]interface{}{w.firstDay}, exceptArgs...)
+	queryArgs = append(append(queryArgs, args...), exceptArgs...)
+	queryArgs = append(queryArgs, minStars)
+	// MAX(x.day) makes the bare description and language columns come from
+	// the most recent star
 	rows, err := r.db.Query(`
 		SELECT
-			s.repo_full_name,
-			s.repo_description,
-			s.repo_language,
-			COUNT(DISTINCT s.account_id) as star_count,
-			GROUP_CONCAT(a.username, ',') as usernames
-		FROM stars s
-		JOIN accounts a ON s.account_id = a.id
-		WHERE s.starred_at >= ?
-		GROUP BY s.repo_full_name
+			x.repo_full_name,
+			x.repo_description,
+			x.repo_language,
+			COUNT(DISTINCT x.account_id) as star_count,
+			GROUP_CONCAT(DISTINCT a.username) as usernames,
+			MAX(x.day)
+		FROM (
+			SELECT account_id, repo_full_name, repo_description, repo_language, day
+			FROM daily_repo_stars WHERE day >= ? AND stars > 0 AND `+except+`
+			UNION ALL
+			SELECT account_id, repo_full_name, COALESCE(repo_description, ''), COALESCE(repo_language, ''), date(starred_at)
+			FROM stars WHERE `+cond+` AND `+except+`
+		) x
+		JOIN accounts a ON x.account_id = a.id
+		GROUP BY x.repo_full_name
 		HAVING star_count >= ?
 		ORDER BY star_count DESC
 		LIMIT 10
-	`, since, minStars)
+	`, queryArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -91,8 +168,8 @@ func (r *StarRepository) GetTrendingRepos(since time.Time, minStars int) ([]Tren
 	var trending []TrendingRepo
 	for rows.Next() {
 		var t TrendingRepo
-		var usernames string
-		if err := rows.Scan(&t.RepoFullName, &t.RepoDescription, &t.RepoLanguage, &t.StarCount, &usernames); err != nil {
+		var usernames, day string
+		if err := rows.Scan(&t.RepoFullName, &t.RepoDescription, &t.RepoLanguage, &t.StarCount, &usernames, &day); err != nil {
 			return nil, err
 		}
 		t.StarredBy = strings.Split(usernames, ",")
@@ -101,3 +178,9 @@ func (r *StarRepository) GetTrendingRepos(since time.Time, minStars int) ([]Tren
 
 	return trending, rows.Err()
 }
+
+// CountByAccount returns the number of stars per account since a time,
+// leaving out the accounts in exclude
+func (r *StarRepository) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	return countByAccount(r.db, "stars", "stars", "starred_at", "1", since, exclude)
+}
@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Reasons a commit is classified as automated. Human commits have no reason.
+const (
+	AutomatedBot        = "bot"
+	AutomatedDependency = "dependency"
+	AutomatedMerge      = "merge"
+	AutomatedVersion    = "version"
+	AutomatedFormatting = "formatting"
+	AutomatedCI         = "ci"
+)
+
+// botNames are bot accounts whose logins don't end in [bot] or -bot
+var botNames = map[string]bool{
+	"dependabot":      true,
+	"renovate":        true,
+	"greenkeeperio":   true,
+	"github-actions":  true,
+	"pre-commit-ci":   true,
+	"imgbot":          true,
+	"allcontributors": true,
+	"codecov":         true,
+}
+
+var (
+	mergeMessage = regexp.MustCompile(`(?i)^Merge (branch|branches|pull request|remote-tracking branch|tag|commit)\b|^Merge \S+ into \S+`)
+
+	dependencyMessage = regexp.MustCompile(`(?i)^(` +
+		`(chore|build|fix|ci)\(deps(-dev)?\)|deps(\(.*?\))?:|` +
+		`(chore|build)(\(.*?\))?: bump |` +
+		`bump \S+ (from|to) |` +
+		`update (dependency|module|rust crate|npm package) \S+|` +
+		`update \S+ (action|digest) to |` +
+		`(update|upgrade) (all )?(dependencies|deps)\b|` +
+		`lock ?file maintenance)`)
+
+	versionMessage = regexp.MustCompile(`(?i)^(` +
+		`chore\(release\):|` +
+		`(release:?|bump version to|bump to|version bump to|prepare (for )?release) v?\d|` +
+		`(bump version|version bump|prepare (for )?release)$|` +
+		`v?\d+\.\d+(\.\d+)?([-+.][0-9a-z.]+)?$)`)
+
+	formattingMessage = regexp.MustCompile(`(?i)^(` +
+		`style(\(.*?\))?:|` +
+		`(run |ran |apply |applied )?(gofmt|go fmt|goimports|prettier|black|rustfmt|cargo fmt|clang-format|eslint --fix)(( on| to| over) .*| formatting| format)?\.?$|` +
+		`(fix |auto-?)?(format|formatting|reformat|lint|linting|whitespace|indentation)( fix(es)?| code| files)?\.?$|` +
+		`fix (lint|linting|formatting|whitespace|indentation)\b)`)
+
+	ciMessage = regexp.MustCompile(`(?i)^(ci(\(.*?\))?:|\[ci\]|(update|fix) (ci|workflows?|github actions)\b)`)
+)
+
+// IsBot reports whether a login belongs to a bot account
+func IsBot(username string) bool {
+	login := strings.ToLower(username)
+	return strings.HasSuffix(login, "[bot]") || strings.HasSuffix(login, "-bot") || botNames[login]
+}
+
+// CommitAuthor is the git author of a commit, as push payloads and the
+// commits API report it
+type CommitAuthor struct {
+	Name  string
+	Email string
+}
+
+// IsBot reports whether the author is a bot, by name or by the login in a
+// GitHub noreply address such as 49699333+dependabot[bot]@users.noreply.github.com
+func (a CommitAuthor) IsBot() bool {
+	if IsBot(a.Name) {
+		return true
+	}
+	local, domain, ok := strings.Cut(a.Email, "@")
+	if !ok || !strings.EqualFold(domain, "users.noreply.github.com") {
+		return false
+	}
+	if _, login, ok := strings.Cut(local, "+"); ok {
+		local = login
+	}
+	return IsBot(local)
+}
+
+// ClassifyCommit returns why a commit looks automated: a bot author, a
+// merge, a version bump, a dependency update, a formatting-only change or a
+// CI change. It returns "" for commits that look hand-written. Only the first
+// line of the message is looked at; diffs are not fetched.
+func ClassifyCommit(author CommitAuthor, message string) string {
+	if author.IsBot() {
+		return AutomatedBot
+	}
+
+	subject := strings.TrimSpace(message)
+	if i := strings.IndexByte(subject, '\n'); i >= 0 {
+		subject = strings.TrimSpace(subject[:i])
+	}
+
+	switch {
+	case mergeMessage.MatchString(subject):
+		return AutomatedMerge
+	case versionMessage.MatchString(subject):
+		return AutomatedVersion
+	case dependencyMessage.MatchString(subject):
+		return AutomatedDependency
+	case formattingMessage.MatchString(subject):
+		return AutomatedFormatting
+	case ciMessage.MatchString(subject):
+		return AutomatedCI
+	}
+	return ""
+}
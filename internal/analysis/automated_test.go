@@ -0,0 +1,49 @@
+package analysis
+
+import "testing"
+
+func TestClassifyCommit(t *testing.T) {
+	tests := []struct {
+		author  CommitAuthor
+		message string
+		want    string
+	}{
+		{CommitAuthor{Name: "dependabot[bot]"}, "Fix typo", AutomatedBot},
+		{CommitAuthor{Name: "renovate"}, "Fix typo", AutomatedBot},
+		{CommitAuthor{Name: "my-release-bot"}, "Fix typo", AutomatedBot},
+		{CommitAuthor{Name: "torvalds"}, "Merge branch 'for-linus' of git://git.kernel.org/pub", AutomatedMerge},
+		{CommitAuthor{Name: "rsc"}, "Merge pull request #42 from rsc/fix", AutomatedMerge},
+		{CommitAuthor{Name: "rsc"}, "chore(deps): update module golang.org/x/net to v0.20.0", AutomatedDependency},
+		{CommitAuthor{Name: "rsc"}, "build(deps-dev): bump eslint from 8.0.0 to 8.1.0", AutomatedDependency},
+		{CommitAuthor{Name: "rsc"}, "Bump lodash from 4.17.15 to 4.17.21\n\nBumps lodash.", AutomatedDependency},
+		{CommitAuthor{Name: "rsc"}, "Update dependency react to v18", AutomatedDependency},
+		{CommitAuthor{Name: "rsc"}, "Update actions/checkout action to v4", AutomatedDependency},
+		{CommitAuthor{Name: "rsc"}, "v1.2.3", AutomatedVersion},
+		{CommitAuthor{Name: "rsc"}, "Release v2.0.0-rc.1", AutomatedVersion},
+		{CommitAuthor{Name: "rsc"}, "chore(release): 1.4.0", AutomatedVersion},
+		{CommitAuthor{Name: "rsc"}, "Bump version to 0.3.1", AutomatedVersion},
+		{CommitAuthor{Name: "rsc"}, "gofmt", AutomatedFormatting},
+		{CommitAuthor{Name: "rsc"}, "Run prettier on src", AutomatedFormatting},
+		{CommitAuthor{Name: "rsc"}, "style: fix indentation", AutomatedFormatting},
+		{CommitAuthor{Name: "rsc"}, "Fix lint", AutomatedFormatting},
+		{CommitAuthor{Name: "rsc"}, "ci: cache go modules", AutomatedCI},
+		{CommitAuthor{Name: "rsc"}, "Update workflows for Go 1.22", AutomatedCI},
+
+		{CommitAuthor{Name: "torvalds"}, "Linux 6.9", ""},
+		{CommitAuthor{Name: "rsc"}, "cmd/go: fix build with -trimpath", ""},
+		{CommitAuthor{Name: "rsc"}, "Release lock before returning", ""},
+		{CommitAuthor{Name: "rsc"}, "Black hole rendering", ""},
+		{CommitAuthor{Name: "rsc"}, "Update README", ""},
+		{CommitAuthor{Name: "rsc"}, "Format dates in the local timezone", ""},
+		{CommitAuthor{Name: "robot"}, "Add feature", ""},
+		{CommitAuthor{Name: "Dependabot", Email: "49699333+dependabot[bot]@users.noreply.github.com"}, "Fix typo", AutomatedBot},
+		{CommitAuthor{Name: "Russ Cox", Email: "rsc@users.noreply.github.com"}, "Fix typo", ""},
+		{CommitAuthor{Name: "Russ Cox", Email: "renovate@example.com"}, "Fix typo", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyCommit(tt.author, tt.message); got != tt.want {
+			t.Errorf("ClassifyCommit(%+v, %q) = %q, want %q", tt.author, tt.message, got, tt.want)
+		}
+	}
+}
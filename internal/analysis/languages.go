@@ -2,8 +2,6 @@ package analysis
 
 import (
 	"sort"
-
-	"github.com/julienpequegnot/ghmon/internal/activity"
 )
 
 type LanguageStats struct {
@@ -12,21 +10,15 @@ type LanguageStats struct {
 	Percentage float64
 }
 
-// AnalyzeLanguages aggregates language usage from repos and stars
-func AnalyzeLanguages(repos []activity.Repo, stars []activity.Star) []LanguageStats {
+// AnalyzeLanguages aggregates language usage from the languages of repos
+// and stars, skipping empty ones
+func AnalyzeLanguages(languages []string) []LanguageStats {
 	counts := make(map[string]int)
 	total := 0
 
-	for _, repo := range repos {
-		if repo.Language != "" {
-			counts[repo.Language]++
-			total++
-		}
-	}
-
-	for _, star := range stars {
-		if star.RepoLanguage != "" {
-			counts[star.RepoLanguage]++
+	for _, lang := range languages {
+		if lang != "" {
+			counts[lang]++
 			total++
 		}
 	}
@@ -0,0 +1,103 @@
+// Package archive exports the database to a portable tar.gz of JSONL files
+// and merges such archives back in.
+//
+// An archive holds manifest.json followed by one JSONL file per table.
+// Records refer to accounts by username rather than row id, so an archive
+// can be imported into any database.
+package archive
+
+import (
+	"time"
+)
+
+// Format identifies ghmon archives in the manifest
+const Format = "ghmon-archive"
+
+// Version is the archive layout written by Export. Import accepts this and
+// any earlier version; fields added later are optional.
+const Version = 1
+
+const manifestName = "manifest.json"
+
+// Manifest describes an archive
+type Manifest struct {
+	Format        string         `json:"format"`
+	Version       int            `json:"version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	SchemaVersion int            `json:"schema_version"`
+	Counts        map[string]int `json:"counts"`
+}
+
+type Account struct {
+	Username    string     `json:"username"`
+	Name        string     `json:"name"`
+	AvatarURL   string     `json:"avatar_url"`
+	Bio         string     `json:"bio"`
+	Followers   int        `json:"followers"`
+	Following   int        `json:"following"`
+	AddedAt     time.Time  `json:"added_at"`
+	LastFetched *time.Time `json:"last_fetched"`
+	Type        string     `json:"type,omitempty"`
+	Source      string     `json:"source,omitempty"`
+	RemovedAt   *time.Time `json:"removed_at,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+}
+
+type Commit struct {
+	Username    string    `json:"username"`
+	RepoName    string    `json:"repo_name"`
+	SHA         string    `json:"sha"`
+	Message     string    `json:"message"`
+	AuthorName  string    `json:"author_name,omitempty"`
+	AuthorEmail string    `json:"author_email,omitempty"`
+	CommittedAt time.Time `json:"committed_at"`
+}
+
+type Repo struct {
+	Username    string    `json:"username"`
+	Name        string    `json:"name"`
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description"`
+	Language    string    `json:"language"`
+	Stars       int       `json:"stars"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Star struct {
+	Username        string    `json:"username"`
+	RepoFullName    string    `json:"repo_full_name"`
+	RepoDescription string    `json:"repo_description"`
+	RepoLanguage    string    `json:"repo_language"`
+	RepoStars       int       `json:"repo_stars"`
+	StarredAt       time.Time `json:"starred_at"`
+}
+
+type Digest struct {
+	PeriodStart   time.Time `json:"period_start"`
+	PeriodEnd     time.Time `json:"period_end"`
+	Content       string    `json:"content"`
+	Data          string    `json:"data,omitempty"`
+	SmartAnalysis string    `json:"smart_analysis"`
+	Source        string    `json:"source,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Tables lists the archived tables in import order: accounts first, since
+// every other record refers to one
+func Tables() []string {
+	return []string{"accounts", "commits", "repos", "stars", "digests"}
+}
+
+// TableResult is how many records of one table were read and how many of
+// those were new
+type TableResult struct {
+	Table    string
+	Read     int
+	Inserted int
+}
+
+// ImportResult summarises an import
+type ImportResult struct {
+	Manifest Manifest
+	Tables   []TableResult
+}
@@ -0,0 +1,122 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+func setupTestDB(t *testing.T, name string) *database.DB {
+	db, err := database.New(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func count(t *testing.T, db *database.DB, table string) int {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n); err != nil {
+		t.Fatalf("failed to count %s: %v", table, err)
+	}
+	return n
+}
+
+func inserts(result *ImportResult) map[string]int {
+	out := make(map[string]int)
+	for _, tr := range result.Tables {
+		out[tr.Table] = tr.Inserted
+	}
+	return out
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := setupTestDB(t, "src.db")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	src.Exec(`INSERT INTO accounts (id, username, name) VALUES (1, 'alice', 'Alice'), (2, 'bob', '')`)
+	src.Exec(`INSERT INTO commits (account_id, repo_name, sha, message, committed_at) VALUES (1, 'alice/x', 'sha1', 'io_uring support', ?), (2, 'bob/y', 'sha2', 'fix', ?)`, now, now)
+	src.Exec(`INSERT INTO repos (account_id, name, full_name, description, language, stars, created_at) VALUES (2, 'y', 'bob/y', 'desc', 'Go', 3, ?)`, now)
+	src.Exec(`INSERT INTO stars (account_id, repo_full_name, repo_description, repo_language, repo_stars, starred_at) VALUES (1, 'golang/go', 'Go', 'Go', 100, ?)`, now)
+	src.Exec(`INSERT INTO account_tags (account_id, tag) VALUES (1, 'kernel'), (2, 'go')`)
+	src.Exec(`INSERT INTO digests (period_start, period_end, content) VALUES (?, ?, 'weekly')`, now.AddDate(0, 0, -7), now)
+
+	var buf bytes.Buffer
+	manifest, err := Export(src, &buf)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if manifest.Counts["commits"] != 2 || manifest.Version != Version {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	archive := buf.Bytes()
+
+	// A destination that already shares one account and commit
+	dst := setupTestDB(t, "dst.db")
+	dst.Exec(`INSERT INTO accounts (id, username) VALUES (7, 'bob')`)
+	dst.Exec(`INSERT INTO commits (account_id, repo_name, sha, message, committed_at) VALUES (7, 'bob/y', 'sha2', 'fix', ?)`, now)
+
+	result, err := Import(dst, bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	got := inserts(result)
+	want := map[string]int{"accounts": 1, "commits": 1, "repos": 1, "stars": 1, "digests": 1}
+	for table, n := range want {
+		if got[table] != n {
+			t.Errorf("expected %d new %s, got %d", n, table, got[table])
+		}
+	}
+
+	var repoOwner string
+	dst.QueryRow(`SELECT a.username FROM repos r JOIN accounts a ON a.id = r.account_id`).Scan(&repoOwner)
+	if repoOwner != "bob" {
+		t.Errorf("expected repo mapped to the existing bob account, got %q", repoOwner)
+	}
+	if count(t, dst, "account_tags") != 2 {
+		t.Errorf("expected tags imported for new and existing accounts")
+	}
+
+	// Importing again, or back into the source, changes nothing
+	for name, db := range map[string]*database.DB{"dst": dst, "src": src} {
+		result, err := Import(db, bytes.NewReader(archive))
+		if err != nil {
+			t.Fatalf("re-import into %s failed: %v", name, err)
+		}
+		for table, n := range inserts(result) {
+			if n != 0 {
+				t.Errorf("re-import into %s inserted %d %s", name, n, table)
+			}
+		}
+	}
+	if count(t, src, "digests") != 1 {
+		t.Errorf("expected digest not to be duplicated")
+	}
+}
+
+func TestImportRejectsInvalidArchives(t *testing.T) {
+	db := setupTestDB(t, "test.db")
+
+	if _, err := Import(db, bytes.NewReader([]byte("not an archive"))); err == nil {
+		t.Error("expected error for a non-archive")
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	writeFile(tw, manifestName, []byte(`{"format": "ghmon-archive", "version": 99}`), time.Now())
+	tw.Close()
+	gz.Close()
+
+	_, err := Import(db, &buf)
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected error for a newer archive version, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// exporters read one table and write each row as a JSON line
+var exporters = map[string]func(db *database.DB, enc *json.Encoder) (int, error){
+	"accounts": exportAccounts,
+	"commits":  exportCommits,
+	"repos":    exportRepos,
+	"stars":    exportStars,
+	"digests":  exportDigests,
+}
+
+// Export writes every table to w as a gzipped tar archive
+func Export(db *database.DB, w io.Writer) (*Manifest, error) {
+	schemaVersion, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Format:        Format,
+		Version:       Version,
+		CreatedAt:     time.Now().UTC(),
+		SchemaVersion: schemaVersion,
+		Counts:        make(map[string]int),
+	}
+
+	files := make(map[string][]byte)
+	for _, table := range Tables() {
+		var buf bytes.Buffer
+		n, err := exporters[table](db, json.NewEncoder(&buf))
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", table, err)
+		}
+		manifest.Counts[table] = n
+		files[table] = buf.Bytes()
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(tw, manifestName, data, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, table := range Tables() {
+		if err := writeFile(tw, table+".jsonl", files[table], manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func exportAccounts(db *database.DB, enc *json.Encoder) (int, error) {
+	tags, err := accountTags(db)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.Query(`
+		SELECT username, COALESCE(name, ''), COALESCE(avatar_url, ''), COALESCE(bio, ''),
+			followers, following, added_at, last_fetched, type, COALESCE(source, ''), removed_at
+		FROM accounts ORDER BY username
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var a Account
+		if err := rows.Scan(&a.Username, &a.Name, &a.AvatarURL, &a.Bio, &a.Followers, &a.Following, &a.AddedAt, &a.LastFetched, &a.Type, &a.Source, &a.RemovedAt); err != nil {
+			return n, err
+		}
+		a.Tags = tags[a.Username]
+		if err := enc.Encode(a); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+func accountTags(db *database.DB) (map[string][]string, error) {
+	rows, err := db.Query(`
+		SELECT a.username, t.tag
+		FROM account_tags t JOIN accounts a ON a.id = t.account_id
+		ORDER BY a.username, t.tag
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var username, tag string
+		if err := rows.Scan(&username, &tag); err != nil {
+			return nil, err
+		}
+		tags[username] = append(tags[username], tag)
+	}
+	return tags, rows.Err()
+}
+
+func exportCommits(db *database.DB, enc *json.Encoder) (int, error) {
+	rows, err := db.Query(`
+		SELECT a.username, c.repo_name, c.sha, COALESCE(c.message, ''), c.author_name, c.author_email, c.committed_at
+		FROM commits c JOIN accounts a ON a.id = c.account_id
+		ORDER BY c.id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var c Commit
+		if err := rows.Scan(&c.Username, &c.RepoName, &c.SHA, &c.Message, &c.AuthorName, &c.AuthorEmail, &c.CommittedAt); err != nil {
+			return n, err
+		}
+		if err := enc.Encode(c); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+func exportRepos(db *database.DB, enc *json.Encoder) (int, error) {
+	rows, err := db.Query(`
+		SELECT a.username, r.name, r.full_name, COALESCE(r.description, ''), COALESCE(r.language, ''), r.stars, r.created_at
+		FROM repos r JOIN accounts a ON a.id = r.account_id
+		ORDER BY r.id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var r Repo
+		if err := rows.Scan(&r.Username, &r.Name, &r.FullName, &r.Description, &r.Language, &r.Stars, &r.CreatedAt); err != nil {
+			return n, err
+		}
+		if err := enc.Encode(r); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+func exportStars(db *database.DB, enc *json.Encoder) (int, error) {
+	rows, err := db.Query(`
+		SELECT a.username, s.repo_full_name, COALESCE(s.repo_description, ''), COALESCE(s.repo_language, ''), s.repo_stars, s.starred_at
+		FROM stars s JOIN accounts a ON a.id = s.account_id
+		ORDER BY s.id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var s Star
+		if err := rows.Scan(&s.Username, &s.RepoFullName, &s.RepoDescription, &s.RepoLanguage, &s.RepoStars, &s.StarredAt); err != nil {
+			return n, err
+		}
+		if err := enc.Encode(s); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+func exportDigests(db *database.DB, enc *json.Encoder) (int, error) {
+	rows, err := db.Query(`
+		SELECT period_start, period_end, COALESCE(content, ''), COALESCE(data, ''),
+			COALESCE(smart_analysis, ''), COALESCE(source, ''), created_at
+		FROM digests ORDER BY id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var d Digest
+		if err := rows.Scan(&d.PeriodStart, &d.PeriodEnd, &d.Content, &d.Data, &d.SmartAnalysis, &d.Source, &d.CreatedAt); err != nil {
+			return n, err
+		}
+		if err := enc.Encode(d); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
@@ -0,0 +1,267 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// importers merge one JSONL file, returning records read and inserted
+var importers = map[string]func(tx *database.Tx, data []byte, ids map[string]int64) (int, int, error){
+	"accounts": importAccounts,
+	"commits":  importCommits,
+	"repos":    importRepos,
+	"stars":    importStars,
+	"digests":  importDigests,
+}
+
+// Import merges the archive in r into db in a single transaction. Records
+// that already exist (by the tables' UNIQUE constraints, or by period and
+// creation time for digests) are skipped, so importing the same archive
+// twice changes nothing.
+func Import(db *database.DB, r io.Reader) (*ImportResult, error) {
+	files, err := readFiles(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := files[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("not a ghmon archive: %s is missing", manifestName)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("not a ghmon archive (format %q)", manifest.Format)
+	}
+	if manifest.Version > Version {
+		return nil, fmt.Errorf("archive version %d is newer than this ghmon supports (%d); upgrade ghmon first", manifest.Version, Version)
+	}
+
+	result := &ImportResult{Manifest: manifest}
+	err = db.WithTx(func(tx *database.Tx) error {
+		ids, err := accountIDs(tx)
+		if err != nil {
+			return err
+		}
+		for _, table := range Tables() {
+			data, ok := files[table+".jsonl"]
+			if !ok {
+				continue
+			}
+			read, inserted, err := importers[table](tx, data, ids)
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", table, err)
+			}
+			result.Tables = append(result.Tables, TableResult{Table: table, Read: read, Inserted: inserted})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func readFiles(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a gzipped archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+	return files, nil
+}
+
+// eachLine decodes every non-empty line of a JSONL file into a new T
+func eachLine[T any](data []byte, fn func(v *T) error) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	n := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			return n, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(&v); err != nil {
+			return n, fmt.Errorf("line %d: %w", line, err)
+		}
+		n++
+	}
+	return n, scanner.Err()
+}
+
+func accountIDs(tx *database.Tx) (map[string]int64, error) {
+	rows, err := tx.Query(`SELECT id, username FROM accounts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int64)
+	for rows.Next() {
+		var id int64
+		var username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		ids[username] = id
+	}
+	return ids, rows.Err()
+}
+
+func inserted(res interface{ RowsAffected() (int64, error) }) int {
+	n, _ := res.RowsAffected()
+	return int(n)
+}
+
+func lookup(ids map[string]int64, username string) (int64, error) {
+	id, ok := ids[username]
+	if !ok {
+		return 0, fmt.Errorf("unknown account '%s'", username)
+	}
+	return id, nil
+}
+
+func importAccounts(tx *database.Tx, data []byte, ids map[string]int64) (int, int, error) {
+	added := 0
+	read, err := eachLine(data, func(a *Account) error {
+		id, ok := ids[a.Username]
+		if !ok {
+			res, err := tx.Exec(`
+				INSERT INTO accounts (username, name, avatar_url, bio, followers, following, added_at, last_fetched, type, source, removed_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, COALESCE(NULLIF(?, ''), 'User'), NULLIF(?, ''), ?)
+			`, a.Username, a.Name, a.AvatarURL, a.Bio, a.Followers, a.Following, a.AddedAt, a.LastFetched, a.Type, a.Source, a.RemovedAt)
+			if err != nil {
+				return err
+			}
+			id, _ = res.LastInsertId()
+			ids[a.Username] = id
+			added++
+		}
+		// Tags are merged into existing accounts too
+		for _, tag := range a.Tags {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO account_tags (account_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return read, added, err
+}
+
+func importCommits(tx *database.Tx, data []byte, ids map[string]int64) (int, int, error) {
+	added := 0
+	read, err := eachLine(data, func(c *Commit) error {
+		accountID, err := lookup(ids, c.Username)
+		if err != nil {
+			return err
+		}
+		author := analysis.CommitAuthor{Name: c.AuthorName, Email: c.AuthorEmail}
+		res, err := tx.Exec(
+			`INSERT OR IGNORE INTO commits (account_id, repo_name, sha, message, author_name, author_email, automated, committed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			accountID, c.RepoName, c.SHA, c.Message, author.Name, author.Email, analysis.ClassifyCommit(author, c.Message), c.CommittedAt,
+		)
+		if err != nil {
+			return err
+		}
+		added += inserted(res)
+		return nil
+	})
+	return read, added, err
+}
+
+func importRepos(tx *database.Tx, data []byte, ids map[string]int64) (int, int, error) {
+	added := 0
+	read, err := eachLine(data, func(r *Repo) error {
+		accountID, err := lookup(ids, r.Username)
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(
+			`INSERT OR IGNORE INTO repos (account_id, name, full_name, description, language, stars, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			accountID, r.Name, r.FullName, r.Description, r.Language, r.Stars, r.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		added += inserted(res)
+		return nil
+	})
+	return read, added, err
+}
+
+func importStars(tx *database.Tx, data []byte, ids map[string]int64) (int, int, error) {
+	added := 0
+	read, err := eachLine(data, func(s *Star) error {
+		accountID, err := lookup(ids, s.Username)
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(
+			`INSERT OR IGNORE INTO stars (account_id, repo_full_name, repo_description, repo_language, repo_stars, starred_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			accountID, s.RepoFullName, s.RepoDescription, s.RepoLanguage, s.RepoStars, s.StarredAt,
+		)
+		if err != nil {
+			return err
+		}
+		added += inserted(res)
+		return nil
+	})
+	return read, added, err
+}
+
+func importDigests(tx *database.Tx, data []byte, ids map[string]int64) (int, int, error) {
+	added := 0
+	read, err := eachLine(data, func(d *Digest) error {
+		// digests have no natural key; datetime() normalises the stored
+		// timestamp formats so a re-import matches the original row
+		res, err := tx.Exec(`
+			INSERT INTO digests (period_start, period_end, content, data, smart_analysis, source, created_at)
+			SELECT ?, ?, ?, ?, ?, ?, ?
+			WHERE NOT EXISTS (
+				SELECT 1 FROM digests
+				WHERE datetime(period_start) = datetime(?) AND datetime(period_end) = datetime(?)
+					AND datetime(created_at) = datetime(?)
+			)
+		`, d.PeriodStart, d.PeriodEnd, d.Content, d.Data, d.SmartAnalysis, d.Source, d.CreatedAt, d.PeriodStart, d.PeriodEnd, d.CreatedAt)
+		if err != nil {
+			return err
+		}
+		added += inserted(res)
+		return nil
+	})
+	return read, added, err
+}
@@ -0,0 +1,58 @@
+package backfill
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// SinceFormat is the layout used for backfill start dates
+const SinceFormat = "2006-01-02"
+
+// Task names for the stages of an account backfill. Commit tasks are
+// recorded per repository as CommitsTask + full name.
+const (
+	StarsTask   = "stars"
+	CommitsTask = "commits:"
+)
+
+// Progress tracks which backfill tasks have completed so an interrupted
+// backfill can resume where it left off
+type Progress struct {
+	db database.Executor
+}
+
+func NewProgress(db *database.DB) *Progress {
+	return &Progress{db: db}
+}
+
+// WithTx returns a Progress that records through tx
+func (p *Progress) WithTx(tx *database.Tx) *Progress {
+	return &Progress{db: tx}
+}
+
+func (p *Progress) IsDone(accountID int64, since time.Time, task string) bool {
+	var count int
+	p.db.QueryRow(
+		`SELECT COUNT(*) FROM backfill_tasks WHERE account_id = ? AND since = ? AND task = ?`,
+		accountID, since.Format(SinceFormat), task,
+	).Scan(&count)
+	return count > 0
+}
+
+func (p *Progress) MarkDone(accountID int64, since time.Time, task string, items int) error {
+	_, err := p.db.Exec(
+		`INSERT OR REPLACE INTO backfill_tasks (account_id, since, task, items) VALUES (?, ?, ?, ?)`,
+		accountID, since.Format(SinceFormat), task, items,
+	)
+	return err
+}
+
+// Reset forgets all completed tasks for an account and start date
+func (p *Progress) Reset(accountID int64, since time.Time) error {
+	_, err := p.db.Exec(
+		`DELETE FROM backfill_tasks WHERE account_id = ? AND since = ?`,
+		accountID, since.Format(SinceFormat),
+	)
+	return err
+}
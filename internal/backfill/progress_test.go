@@ -0,0 +1,43 @@
+package backfill
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+func TestProgress(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`INSERT INTO accounts (id, username) VALUES (1, 'testuser')`); err != nil {
+		t.Fatalf("failed to create test account: %v", err)
+	}
+
+	progress := NewProgress(db)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if progress.IsDone(1, since, StarsTask) {
+		t.Error("expected task not to be done")
+	}
+
+	if err := progress.MarkDone(1, since, StarsTask, 12); err != nil {
+		t.Fatalf("failed to mark task done: %v", err)
+	}
+	if !progress.IsDone(1, since, StarsTask) {
+		t.Error("expected task to be done")
+	}
+	if progress.IsDone(1, since.AddDate(0, 1, 0), StarsTask) {
+		t.Error("expected task for a different start date not to be done")
+	}
+
+	progress.Reset(1, since)
+	if progress.IsDone(1, since, StarsTask) {
+		t.Error("expected task to be cleared after reset")
+	}
+}
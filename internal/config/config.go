@@ -9,10 +9,12 @@ import (
 )
 
 type Config struct {
-	GitHub GitHubConfig `yaml:"github"`
-	APIs   APIConfig    `yaml:"apis"`
-	Fetch  FetchConfig  `yaml:"fetch"`
-	Digest DigestConfig `yaml:"digest"`
+	GitHub    GitHubConfig    `yaml:"github"`
+	APIs      APIConfig       `yaml:"apis"`
+	Fetch     FetchConfig     `yaml:"fetch"`
+	Digest    DigestConfig    `yaml:"digest"`
+	Retention RetentionConfig `yaml:"retention"`
+	Ignore    IgnoreConfig    `yaml:"ignore,omitempty"`
 }
 
 type GitHubConfig struct {
@@ -33,6 +35,35 @@ type DigestConfig struct {
 	DefaultDays int `yaml:"default_days"`
 }
 
+// RetentionConfig is how many days of each table 'ghmon prune' keeps;
+// zero keeps rows forever
+type RetentionConfig struct {
+	CommitsDays   int `yaml:"commits_days"`
+	ReposDays     int `yaml:"repos_days"`
+	StarsDays     int `yaml:"stars_days"`
+	DigestsDays   int `yaml:"digests_days"`
+	FetchRunsDays int `yaml:"fetch_runs_days"`
+}
+
+// Enabled reports whether any table has a max age
+func (r RetentionConfig) Enabled() bool {
+	return r.CommitsDays > 0 || r.ReposDays > 0 || r.StarsDays > 0 || r.DigestsDays > 0 || r.FetchRunsDays > 0
+}
+
+// IgnoreConfig leaves matching activity out of digests, exports and 'show'.
+// Rules from the ignore file are added to these.
+type IgnoreConfig struct {
+	// Repos are glob patterns matched against owner/name and name
+	Repos []string `yaml:"repos,omitempty"`
+	// Messages are regular expressions matched against commit messages
+	Messages []string `yaml:"messages,omitempty"`
+	// Authors are glob patterns matched against account usernames
+	Authors   []string `yaml:"authors,omitempty"`
+	Languages []string `yaml:"languages,omitempty"`
+	// Events are the kinds of activity to leave out: commits, repos or stars
+	Events []string `yaml:"events,omitempty"`
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		GitHub: GitHubConfig{
@@ -49,6 +80,7 @@ func DefaultConfig() *Config {
 		Digest: DigestConfig{
 			DefaultDays: 7,
 		},
+		// Retention is opt-in: nothing is pruned until a max age is set
 	}
 }
 
@@ -65,6 +97,17 @@ func DBPath() string {
 	return filepath.Join(ConfigDir(), "ghmon.db")
 }
 
+// IgnorePath is the ignore file, one rule per line
+func IgnorePath() string {
+	return filepath.Join(ConfigDir(), "ignore")
+}
+
+// FetchLockPath is the lock file that keeps the daemon, manual fetches and
+// backfills from running at the same time
+func FetchLockPath() string {
+	return filepath.Join(ConfigDir(), "fetch.lock")
+}
+
 func (c *Config) Save() error {
 	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
 		return err
@@ -16,6 +16,10 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Fetch.Concurrency != 5 {
 		t.Errorf("expected concurrency 5, got %d", cfg.Fetch.Concurrency)
 	}
+
+	if cfg.Retention.Enabled() {
+		t.Errorf("expected retention to be off by default, got %+v", cfg.Retention)
+	}
 }
 
 func TestConfigDir(t *testing.T) {
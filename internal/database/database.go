@@ -1,32 +1,115 @@
-// internal/database/database.go
 package database
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// BusyTimeout is how long, in milliseconds, a connection waits for another
+// process (typically the daemon) to release its write lock before failing
+// with "database is locked"
+const BusyTimeout = 10000
+
 type DB struct {
+	// conn is the single writer connection; read is a pool of read-only
+	// connections which, thanks to WAL, never block on the writer
 	conn *sql.DB
+	read *sql.DB
+	path string
 }
 
+// New opens the database and applies any pending migrations
 func New(dbPath string) (*DB, error) {
-	conn, err := sql.Open("sqlite3", dbPath)
+	db, err := Open(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
-	db := &DB{conn: conn}
-	if err := db.initSchema(); err != nil {
+	if err := db.checkFTS5(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Open opens the database without migrating it
+func Open(dbPath string) (*DB, error) {
+	params := fmt.Sprintf("_busy_timeout=%d&_foreign_keys=on", BusyTimeout)
+
+	// Transactions take the write lock up front, so two writers queue on
+	// busy_timeout instead of failing when one upgrades from a read lock
+	conn, err := sql.Open("sqlite3", dbPath+"?"+params+"&_journal_mode=WAL&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time; queue them in-process rather than
+	// have them contend for the file lock
+	conn.SetMaxOpenConns(1)
+
+	// Connect once so the database exists and is in WAL mode before any
+	// reader opens it
+	if err := conn.Ping(); err != nil {
 		conn.Close()
 		return nil, err
 	}
 
+	db := &DB{conn: conn, read: conn, path: dbPath}
+	if isMemory(dbPath) {
+		// Every connection to :memory: is a separate database
+		return db, nil
+	}
+
+	read, err := sql.Open("sqlite3", dbPath+"?"+params+"&_query_only=true")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	db.read = read
+
 	return db, nil
 }
 
+// HasFTS5 reports whether SQLite has FTS5, which the full-text search indexes
+// need. go-sqlite3 only compiles it in with the sqlite_fts5 build tag.
+func (db *DB) HasFTS5() bool {
+	var enabled bool
+	db.conn.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	return enabled
+}
+
+// checkFTS5 fails when a build with FTS5 has already created the FTS5 search
+// indexes but this one lacks it: the triggers keeping them in sync would fail
+// every write to commits, repos and stars
+func (db *DB) checkFTS5() error {
+	if db.HasFTS5() {
+		return nil
+	}
+	var count int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND sql LIKE '%USING fts5%'`).Scan(&count); err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("the database has FTS5 search indexes but SQLite was built without FTS5; build ghmon with -tags sqlite_fts5")
+	}
+	return nil
+}
+
+func isMemory(dbPath string) bool {
+	return dbPath == "" || dbPath == ":memory:" || strings.HasPrefix(dbPath, "file::memory:")
+}
+
 func (db *DB) Close() error {
+	if db.read != db.conn {
+		db.read.Close()
+	}
 	return db.conn.Close()
 }
 
@@ -35,83 +118,9 @@ func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
 }
 
 func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return db.conn.Query(query, args...)
+	return db.read.Query(query, args...)
 }
 
 func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
-	return db.conn.QueryRow(query, args...)
-}
-
-func (db *DB) initSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS accounts (
-		id INTEGER PRIMARY KEY,
-		username TEXT UNIQUE NOT NULL,
-		name TEXT,
-		avatar_url TEXT,
-		bio TEXT,
-		followers INTEGER DEFAULT 0,
-		following INTEGER DEFAULT 0,
-		added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		last_fetched DATETIME
-	);
-
-	CREATE TABLE IF NOT EXISTS commits (
-		id INTEGER PRIMARY KEY,
-		account_id INTEGER NOT NULL,
-		repo_name TEXT NOT NULL,
-		sha TEXT NOT NULL,
-		message TEXT,
-		committed_at DATETIME,
-		fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (account_id) REFERENCES accounts(id),
-		UNIQUE(account_id, sha)
-	);
-
-	CREATE TABLE IF NOT EXISTS repos (
-		id INTEGER PRIMARY KEY,
-		account_id INTEGER NOT NULL,
-		name TEXT NOT NULL,
-		full_name TEXT NOT NULL,
-		description TEXT,
-		language TEXT,
-		stars INTEGER DEFAULT 0,
-		created_at DATETIME,
-		fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (account_id) REFERENCES accounts(id),
-		UNIQUE(account_id, full_name)
-	);
-
-	CREATE TABLE IF NOT EXISTS stars (
-		id INTEGER PRIMARY KEY,
-		account_id INTEGER NOT NULL,
-		repo_full_name TEXT NOT NULL,
-		repo_description TEXT,
-		repo_language TEXT,
-		repo_stars INTEGER DEFAULT 0,
-		starred_at DATETIME,
-		fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (account_id) REFERENCES accounts(id),
-		UNIQUE(account_id, repo_full_name)
-	);
-
-	CREATE TABLE IF NOT EXISTS digests (
-		id INTEGER PRIMARY KEY,
-		period_start DATETIME NOT NULL,
-		period_end DATETIME NOT NULL,
-		content TEXT,
-		smart_analysis TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_commits_account ON commits(account_id);
-	CREATE INDEX IF NOT EXISTS idx_commits_date ON commits(committed_at);
-	CREATE INDEX IF NOT EXISTS idx_repos_account ON repos(account_id);
-	CREATE INDEX IF NOT EXISTS idx_repos_created ON repos(created_at);
-	CREATE INDEX IF NOT EXISTS idx_stars_account ON stars(account_id);
-	CREATE INDEX IF NOT EXISTS idx_stars_date ON stars(starred_at);
-	`
-
-	_, err := db.conn.Exec(schema)
-	return err
+	return db.read.QueryRow(query, args...)
 }
@@ -2,8 +2,10 @@
 package database
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -32,7 +34,7 @@ func TestInitSchema(t *testing.T) {
 	}
 	defer db.Close()
 
-	tables := []string{"accounts", "commits", "repos", "stars", "digests"}
+	tables := []string{"accounts", "commits", "repos", "stars", "digests", "fetch_runs", "fetch_run_accounts", "backfill_tasks", "commit_rollups", "daily_activity", "daily_repo_commits", "daily_repo_stars", "account_tags", "watched_repos", "watched_repo_events", "watched_repo_stars", "account_following", "pruning"}
 	for _, table := range tables {
 		rows, err := db.conn.Query("SELECT 1 FROM " + table + " LIMIT 1")
 		if err != nil {
@@ -42,3 +44,82 @@ func TestInitSchema(t *testing.T) {
 		rows.Close()
 	}
 }
+
+func TestOpenPragmas(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("failed to read journal_mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("expected WAL journal mode, got %s", mode)
+	}
+
+	var foreignKeys int
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		t.Fatalf("failed to read foreign_keys: %v", err)
+	}
+	if foreignKeys != 1 {
+		t.Error("expected foreign keys to be enforced")
+	}
+
+	_, err = db.Exec(`INSERT INTO commits (account_id, repo_name, sha, message, committed_at) VALUES (999, 'x/y', 'abc', 'msg', CURRENT_TIMESTAMP)`)
+	if err == nil {
+		t.Error("expected insert referencing a missing account to fail")
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// Two handles stand in for the daemon and a CLI command
+	first, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer first.Close()
+	second, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer second.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i, db := range []*DB{first, second} {
+		wg.Add(1)
+		go func(i int, db *DB) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				err := db.WithTx(func(tx *Tx) error {
+					_, err := tx.Exec(`INSERT INTO accounts (username) VALUES (?)`, fmt.Sprintf("user-%d-%d", i, j))
+					return err
+				})
+				if err != nil {
+					errs <- err
+				}
+				var count int
+				if err := db.QueryRow(`SELECT COUNT(*) FROM accounts`).Scan(&count); err != nil {
+					errs <- err
+				}
+			}
+		}(i, db)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent access failed: %v", err)
+	}
+
+	var count int
+	first.QueryRow(`SELECT COUNT(*) FROM accounts`).Scan(&count)
+	if count != 100 {
+		t.Errorf("expected 100 accounts, got %d", count)
+	}
+}
@@ -0,0 +1,23 @@
+package database
+
+// Size returns the number of bytes the database occupies, excluding the WAL
+func (db *DB) Size() (int64, error) {
+	var pages, pageSize int64
+	if err := db.conn.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := db.conn.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
+
+// Vacuum rebuilds the database file to release free pages, then truncates
+// the WAL so the space is returned to the filesystem
+func (db *DB) Vacuum() error {
+	if _, err := db.conn.Exec(`VACUUM`); err != nil {
+		return err
+	}
+	_, err := db.conn.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+	return err
+}
@@ -0,0 +1,286 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is one ordered schema change, loaded from migrations/NNNN_name.sql
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+	// Requires names the SQLite feature the migration needs, from a
+	// "-- requires: <feature>" first line. Without it the migration is
+	// skipped and stays pending until a build that has it opens the database.
+	Requires string
+}
+
+// requirements are the SQLite features a migration can require
+var requirements = map[string]func(*DB) bool{
+	"fts5": (*DB).HasFTS5,
+}
+
+// MigrationStatus pairs a migration with when it was applied, if ever
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+	// Unsupported is set when the migration is pending because this SQLite
+	// build lacks the feature it requires
+	Unsupported bool
+}
+
+// MigrateResult describes what Migrate did
+type MigrateResult struct {
+	From    int
+	To      int
+	Applied []Migration
+	// Skipped are pending migrations this SQLite build can't apply
+	Skipped    []Migration
+	BackupPath string
+}
+
+// Migrations returns the embedded migrations in version order
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, label, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version: %w", entry.Name(), err)
+		}
+
+		data, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m := Migration{Version: version, Name: label, SQL: string(data)}
+		if first, _, _ := strings.Cut(m.SQL, "\n"); strings.HasPrefix(first, "-- requires:") {
+			m.Requires = strings.TrimSpace(strings.TrimPrefix(first, "-- requires:"))
+			if _, ok := requirements[m.Requires]; !ok {
+				return nil, fmt.Errorf("migration %s: unknown requirement '%s'", entry.Name(), m.Requires)
+			}
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+func (db *DB) ensureVersionTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+// SchemaVersion returns the highest applied migration version, 0 if none
+func (db *DB) SchemaVersion() (int, error) {
+	if err := db.ensureVersionTable(); err != nil {
+		return 0, err
+	}
+	var version int
+	err := db.conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	return version, err
+}
+
+// MigrationStatus lists every known migration and whether it has been applied
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	rows, err := db.conn.Query(`SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		s := MigrationStatus{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			s.AppliedAt = &at
+		} else {
+			s.Unsupported = !db.supports(m.Requires)
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// Migrate applies pending migrations in order, each in its own transaction.
+// Migrations this SQLite build can't apply are skipped and left pending. If an
+// existing database is about to change, it is first backed up next to the
+// database file.
+func (db *DB) Migrate() (*MigrateResult, error) {
+	from, err := db.SchemaVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	applied, err := db.appliedVersions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrateResult{From: from, To: from}
+	var pending []Migration
+	for _, m := range migrations {
+		switch {
+		case applied[m.Version]:
+		case !db.supports(m.Requires):
+			result.Skipped = append(result.Skipped, m)
+		default:
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	if db.hasData() {
+		result.BackupPath, err = db.backup(from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up database before migrating: %w", err)
+		}
+	}
+
+	for _, m := range pending {
+		ran, err := db.apply(m)
+		if err != nil {
+			return result, fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if !ran {
+			continue
+		}
+		result.Applied = append(result.Applied, m)
+		if m.Version > result.To {
+			result.To = m.Version
+		}
+	}
+	return result, nil
+}
+
+func (db *DB) appliedVersions() (map[int]bool, error) {
+	rows, err := db.conn.Query(`SELECT version FROM schema_version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// supports reports whether this SQLite build has the feature a migration
+// requires
+func (db *DB) supports(feature string) bool {
+	return feature == "" || requirements[feature](db)
+}
+
+// apply runs a migration and records it, unless another process (say the
+// daemon starting alongside a command) applied it since Migrate looked. The
+// transaction takes the write lock up front, so the check and the migration
+// can't interleave with another process's. It reports whether it ran.
+func (db *DB) apply(m Migration) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	if err := tx.QueryRow(`SELECT COUNT(*) > 0 FROM schema_version WHERE version = ?`, m.Version).Scan(&applied); err != nil {
+		return false, err
+	}
+	if applied {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, name) VALUES (?, ?)`, m.Version, m.Name); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// hasData reports whether the database holds any tables besides schema_version,
+// i.e. whether it is worth backing up
+func (db *DB) hasData() bool {
+	if isMemory(db.path) {
+		return false
+	}
+	var count int
+	db.conn.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT IN ('schema_version') AND name NOT LIKE 'sqlite_%'
+	`).Scan(&count)
+	return count > 0
+}
+
+func (db *DB) backup(version int) (string, error) {
+	backupPath := fmt.Sprintf("%s.backup-v%d-%s", db.path, version, time.Now().Format("20060102-150405"))
+	if _, err := db.conn.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
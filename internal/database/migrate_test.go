@@ -0,0 +1,132 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationsOrdered(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("expected migrations to start at version 1, got %+v", migrations)
+	}
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			t.Errorf("migrations out of order at %d", migrations[i].Version)
+		}
+	}
+}
+
+func TestNewDBIsMigrated(t *testing.T) {
+	tmpDir := t.TempDir()
+	db, err := New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("failed to read status: %v", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil && !s.Unsupported {
+			t.Errorf("expected migration %d to be applied", s.Version)
+		}
+		if s.Requires == "fts5" && s.Unsupported == db.HasFTS5() {
+			t.Errorf("expected migration %d to depend on FTS5 being compiled in", s.Version)
+		}
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(tmpDir, "*.backup-*"))
+	if len(backups) != 0 {
+		t.Errorf("expected no backup for a fresh database, got %v", backups)
+	}
+}
+
+func TestMigrateLegacyDB(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "legacy.db")
+
+	// A database created before versioned migrations existed
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE accounts (id INTEGER PRIMARY KEY, username TEXT UNIQUE NOT NULL, name TEXT, avatar_url TEXT, bio TEXT, followers INTEGER DEFAULT 0, following INTEGER DEFAULT 0, added_at DATETIME DEFAULT CURRENT_TIMESTAMP, last_fetched DATETIME)`); err != nil {
+		t.Fatalf("failed to create legacy table: %v", err)
+	}
+	db.Exec(`INSERT INTO accounts (username) VALUES ('torvalds')`)
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("failed to read status: %v", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			t.Errorf("expected migration %d to be pending", s.Version)
+		}
+	}
+
+	result, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	db.Close()
+
+	if result.From != 0 || len(result.Applied)+len(result.Skipped) != len(statuses) {
+		t.Errorf("unexpected migrate result: %+v", result)
+	}
+	if _, err := os.Stat(result.BackupPath); err != nil {
+		t.Errorf("expected backup at %s: %v", result.BackupPath, err)
+	}
+
+	db, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM accounts`).Scan(&count)
+	if count != 1 {
+		t.Errorf("expected legacy data to survive, got %d accounts", count)
+	}
+}
+
+func TestMigrateConcurrent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// Two processes that both found the schema out of date before either
+	// took the write lock
+	first, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer first.Close()
+	second, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer second.Close()
+	if err := second.ensureVersionTable(); err != nil {
+		t.Fatalf("failed to create version table: %v", err)
+	}
+
+	migrations, _ := Migrations()
+	if _, err := first.Migrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ran, err := second.apply(migrations[0])
+	if err != nil {
+		t.Fatalf("expected an applied migration to be skipped, got %v", err)
+	}
+	if ran {
+		t.Error("expected an applied migration not to run again")
+	}
+}
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS fetch_runs (
+	id INTEGER PRIMARY KEY,
+	started_at DATETIME NOT NULL,
+	finished_at DATETIME,
+	accounts_total INTEGER DEFAULT 0,
+	accounts_failed INTEGER DEFAULT 0,
+	requests_used INTEGER DEFAULT 0,
+	items_inserted INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS fetch_run_accounts (
+	id INTEGER PRIMARY KEY,
+	run_id INTEGER NOT NULL,
+	account_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	duration_ms INTEGER DEFAULT 0,
+	requests_used INTEGER DEFAULT 0,
+	commits INTEGER DEFAULT 0,
+	repos INTEGER DEFAULT 0,
+	stars INTEGER DEFAULT 0,
+	events_error TEXT DEFAULT '',
+	repos_error TEXT DEFAULT '',
+	stars_error TEXT DEFAULT '',
+	FOREIGN KEY (run_id) REFERENCES fetch_runs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fetch_run_accounts_run ON fetch_run_accounts(run_id);
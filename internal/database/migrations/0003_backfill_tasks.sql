@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS backfill_tasks (
+	id INTEGER PRIMARY KEY,
+	account_id INTEGER NOT NULL,
+	since TEXT NOT NULL,
+	task TEXT NOT NULL,
+	items INTEGER DEFAULT 0,
+	completed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (account_id) REFERENCES accounts(id),
+	UNIQUE(account_id, since, task)
+);
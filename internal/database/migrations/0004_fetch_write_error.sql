@@ -0,0 +1,2 @@
+-- Records failures to write an account's fetched data, separately from API errors
+ALTER TABLE fetch_run_accounts ADD COLUMN write_error TEXT DEFAULT '';
@@ -0,0 +1,11 @@
+-- Monthly per-repo commit counts, kept when pruning deletes old commits so
+-- long-term totals survive retention
+
+CREATE TABLE commit_rollups (
+	account_id INTEGER NOT NULL,
+	repo_name TEXT NOT NULL,
+	month TEXT NOT NULL,
+	commits INTEGER DEFAULT 0,
+	PRIMARY KEY (account_id, repo_name, month),
+	FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
@@ -0,0 +1,53 @@
+-- Full-text indexes over commit messages and repository descriptions.
+-- FTS4 rather than FTS5: go-sqlite3 only compiles FTS5 in with the
+-- sqlite_fts5 build tag, while FTS3/4 are always available. The indexes use
+-- the activity tables as external content and are kept in sync by triggers,
+-- so every insert path (fetch, backfill, import) is covered.
+
+CREATE VIRTUAL TABLE commits_fts USING fts4(content="commits", repo_name, message, tokenize=unicode61);
+CREATE VIRTUAL TABLE repos_fts USING fts4(content="repos", full_name, description, tokenize=unicode61);
+CREATE VIRTUAL TABLE stars_fts USING fts4(content="stars", repo_full_name, repo_description, tokenize=unicode61);
+
+CREATE TRIGGER commits_fts_insert AFTER INSERT ON commits BEGIN
+	INSERT INTO commits_fts(docid, repo_name, message) VALUES (new.id, new.repo_name, new.message);
+END;
+CREATE TRIGGER commits_fts_delete BEFORE DELETE ON commits BEGIN
+	DELETE FROM commits_fts WHERE docid = old.id;
+END;
+CREATE TRIGGER commits_fts_update_before BEFORE UPDATE ON commits BEGIN
+	DELETE FROM commits_fts WHERE docid = old.id;
+END;
+CREATE TRIGGER commits_fts_update_after AFTER UPDATE ON commits BEGIN
+	INSERT INTO commits_fts(docid, repo_name, message) VALUES (new.id, new.repo_name, new.message);
+END;
+
+CREATE TRIGGER repos_fts_insert AFTER INSERT ON repos BEGIN
+	INSERT INTO repos_fts(docid, full_name, description) VALUES (new.id, new.full_name, new.description);
+END;
+CREATE TRIGGER repos_fts_delete BEFORE DELETE ON repos BEGIN
+	DELETE FROM repos_fts WHERE docid = old.id;
+END;
+CREATE TRIGGER repos_fts_update_before BEFORE UPDATE ON repos BEGIN
+	DELETE FROM repos_fts WHERE docid = old.id;
+END;
+CREATE TRIGGER repos_fts_update_after AFTER UPDATE ON repos BEGIN
+	INSERT INTO repos_fts(docid, full_name, description) VALUES (new.id, new.full_name, new.description);
+END;
+
+CREATE TRIGGER stars_fts_insert AFTER INSERT ON stars BEGIN
+	INSERT INTO stars_fts(docid, repo_full_name, repo_description) VALUES (new.id, new.repo_full_name, new.repo_description);
+END;
+CREATE TRIGGER stars_fts_delete BEFORE DELETE ON stars BEGIN
+	DELETE FROM stars_fts WHERE docid = old.id;
+END;
+CREATE TRIGGER stars_fts_update_before BEFORE UPDATE ON stars BEGIN
+	DELETE FROM stars_fts WHERE docid = old.id;
+END;
+CREATE TRIGGER stars_fts_update_after AFTER UPDATE ON stars BEGIN
+	INSERT INTO stars_fts(docid, repo_full_name, repo_description) VALUES (new.id, new.repo_full_name, new.repo_description);
+END;
+
+-- Index activity stored before this migration
+INSERT INTO commits_fts(commits_fts) VALUES ('rebuild');
+INSERT INTO repos_fts(repos_fts) VALUES ('rebuild');
+INSERT INTO stars_fts(stars_fts) VALUES ('rebuild');
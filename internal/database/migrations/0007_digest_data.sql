@@ -0,0 +1,6 @@
+-- Generated digests are now saved: data holds the structured digest as JSON
+-- and source records which command produced it
+
+ALTER TABLE digests ADD COLUMN data TEXT DEFAULT '';
+ALTER TABLE digests ADD COLUMN source TEXT DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_digests_created ON digests(created_at);
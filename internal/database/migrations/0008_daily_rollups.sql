@@ -0,0 +1,76 @@
+-- Per-account, per-day activity counts so long-range digests read a few
+-- rows per account instead of every commit, repo and star. Triggers keep
+-- them in step with the activity tables on insert and delete. Days are UTC.
+
+CREATE TABLE daily_activity (
+	account_id INTEGER NOT NULL,
+	day TEXT NOT NULL,
+	commits INTEGER DEFAULT 0,
+	repos INTEGER DEFAULT 0,
+	stars INTEGER DEFAULT 0,
+	PRIMARY KEY (account_id, day),
+	FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX idx_daily_activity_day ON daily_activity(day);
+
+CREATE TABLE daily_repo_commits (
+	account_id INTEGER NOT NULL,
+	day TEXT NOT NULL,
+	repo_name TEXT NOT NULL,
+	commits INTEGER DEFAULT 0,
+	PRIMARY KEY (account_id, day, repo_name),
+	FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX idx_daily_repo_commits_day ON daily_repo_commits(day);
+
+CREATE TRIGGER daily_commits_insert AFTER INSERT ON commits BEGIN
+	INSERT INTO daily_activity (account_id, day, commits)
+	VALUES (new.account_id, COALESCE(date(new.committed_at), substr(new.committed_at, 1, 10), ''), 1)
+	ON CONFLICT (account_id, day) DO UPDATE SET commits = commits + 1;
+	INSERT INTO daily_repo_commits (account_id, day, repo_name, commits)
+	VALUES (new.account_id, COALESCE(date(new.committed_at), substr(new.committed_at, 1, 10), ''), new.repo_name, 1)
+	ON CONFLICT (account_id, day, repo_name) DO UPDATE SET commits = commits + 1;
+END;
+CREATE TRIGGER daily_commits_delete AFTER DELETE ON commits BEGIN
+	UPDATE daily_activity SET commits = commits - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.committed_at), substr(old.committed_at, 1, 10), '');
+	UPDATE daily_repo_commits SET commits = commits - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.committed_at), substr(old.committed_at, 1, 10), '')
+		AND repo_name = old.repo_name;
+END;
+
+CREATE TRIGGER daily_repos_insert AFTER INSERT ON repos BEGIN
+	INSERT INTO daily_activity (account_id, day, repos)
+	VALUES (new.account_id, COALESCE(date(new.created_at), substr(new.created_at, 1, 10), ''), 1)
+	ON CONFLICT (account_id, day) DO UPDATE SET repos = repos + 1;
+END;
+CREATE TRIGGER daily_repos_delete AFTER DELETE ON repos BEGIN
+	UPDATE daily_activity SET repos = repos - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.created_at), substr(old.created_at, 1, 10), '');
+END;
+
+CREATE TRIGGER daily_stars_insert AFTER INSERT ON stars BEGIN
+	INSERT INTO daily_activity (account_id, day, stars)
+	VALUES (new.account_id, COALESCE(date(new.starred_at), substr(new.starred_at, 1, 10), ''), 1)
+	ON CONFLICT (account_id, day) DO UPDATE SET stars = stars + 1;
+END;
+CREATE TRIGGER daily_stars_delete AFTER DELETE ON stars BEGIN
+	UPDATE daily_activity SET stars = stars - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.starred_at), substr(old.starred_at, 1, 10), '');
+END;
+
+-- Roll up activity stored before this migration
+INSERT INTO daily_activity (account_id, day, commits, repos, stars)
+SELECT account_id, day, SUM(commits), SUM(repos), SUM(stars) FROM (
+	SELECT account_id, COALESCE(date(committed_at), substr(committed_at, 1, 10), '') AS day, 1 AS commits, 0 AS repos, 0 AS stars FROM commits
+	UNION ALL
+	SELECT account_id, COALESCE(date(created_at), substr(created_at, 1, 10), ''), 0, 1, 0 FROM repos
+	UNION ALL
+	SELECT account_id, COALESCE(date(starred_at), substr(starred_at, 1, 10), ''), 0, 0, 1 FROM stars
+)
+GROUP BY account_id, day;
+
+INSERT INTO daily_repo_commits (account_id, day, repo_name, commits)
+SELECT account_id, COALESCE(date(committed_at), substr(committed_at, 1, 10), ''), repo_name, COUNT(*)
+FROM commits
+GROUP BY 1, 2, 3;
@@ -0,0 +1,11 @@
+-- Free-form tags for grouping accounts, so digests, exports and fetches can
+-- be limited to one group
+
+CREATE TABLE account_tags (
+	account_id INTEGER NOT NULL,
+	tag TEXT NOT NULL,
+	added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (account_id, tag),
+	FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX idx_account_tags_tag ON account_tags(tag);
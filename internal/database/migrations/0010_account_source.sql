@@ -0,0 +1,7 @@
+-- Where each account came from ('manual' or 'sync'), so 'ghmon sync --prune'
+-- only removes accounts sync added itself, and when an account stopped being
+-- monitored with its history kept. Accounts added before this migration
+-- have no source; sync claims the ones it finds in the following list.
+
+ALTER TABLE accounts ADD COLUMN source TEXT;
+ALTER TABLE accounts ADD COLUMN removed_at DATETIME;
@@ -0,0 +1,4 @@
+-- GitHub account type ('User' or 'Organization'); organizations are fetched
+-- through the org endpoints and shown in their own digest section
+
+ALTER TABLE accounts ADD COLUMN type TEXT NOT NULL DEFAULT 'User';
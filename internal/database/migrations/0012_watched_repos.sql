@@ -0,0 +1,37 @@
+-- Repositories watched regardless of who contributes to them: their events
+-- (pushes, releases, pull requests, issues) and a star count per fetch, so
+-- digests can show stargazer growth
+
+CREATE TABLE watched_repos (
+	id INTEGER PRIMARY KEY,
+	full_name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	description TEXT NOT NULL DEFAULT '',
+	language TEXT NOT NULL DEFAULT '',
+	stars INTEGER NOT NULL DEFAULT 0,
+	added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	last_fetched DATETIME
+);
+
+CREATE TABLE watched_repo_events (
+	id INTEGER PRIMARY KEY,
+	repo_id INTEGER NOT NULL,
+	event_id TEXT NOT NULL,
+	type TEXT NOT NULL,
+	action TEXT NOT NULL DEFAULT '',
+	actor TEXT NOT NULL DEFAULT '',
+	number INTEGER NOT NULL DEFAULT 0,
+	title TEXT NOT NULL DEFAULT '',
+	commits INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	UNIQUE (repo_id, event_id),
+	FOREIGN KEY (repo_id) REFERENCES watched_repos(id)
+);
+CREATE INDEX idx_watched_repo_events_created ON watched_repo_events(repo_id, created_at);
+
+CREATE TABLE watched_repo_stars (
+	repo_id INTEGER NOT NULL,
+	recorded_at DATETIME NOT NULL,
+	stars INTEGER NOT NULL,
+	PRIMARY KEY (repo_id, recorded_at),
+	FOREIGN KEY (repo_id) REFERENCES watched_repos(id)
+);
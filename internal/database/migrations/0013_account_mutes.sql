@@ -0,0 +1,4 @@
+-- When a muted account's activity shows up in digests again; 'ghmon mute'
+-- hides an account at query time without stopping its fetches
+
+ALTER TABLE accounts ADD COLUMN muted_until DATETIME;
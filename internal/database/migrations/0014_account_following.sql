@@ -0,0 +1,10 @@
+-- Who each monitored account follows, as last seen by 'ghmon suggest'.
+-- first_seen tells accounts followed since the first snapshot apart from the
+-- ones already followed then.
+
+CREATE TABLE account_following (
+	account_id INTEGER NOT NULL,
+	login TEXT NOT NULL COLLATE NOCASE,
+	first_seen DATETIME NOT NULL,
+	PRIMARY KEY (account_id, login)
+);
@@ -0,0 +1,37 @@
+-- Commit authors from push payloads, and whether each commit looks automated,
+-- decided once when it is stored. automated holds the reason, '' for commits
+-- that look hand-written; commits stored before this migration stay NULL
+-- until they are classified. daily_activity counts human commits next to all
+-- commits, so digests rank accounts without reading every commit.
+
+ALTER TABLE commits ADD COLUMN author_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE commits ADD COLUMN author_email TEXT NOT NULL DEFAULT '';
+ALTER TABLE commits ADD COLUMN automated TEXT;
+CREATE INDEX idx_commits_unclassified ON commits(id) WHERE automated IS NULL;
+
+ALTER TABLE daily_activity ADD COLUMN human_commits INTEGER DEFAULT 0;
+
+DROP TRIGGER daily_commits_insert;
+CREATE TRIGGER daily_commits_insert AFTER INSERT ON commits BEGIN
+	INSERT INTO daily_activity (account_id, day, commits, human_commits)
+	VALUES (new.account_id, COALESCE(date(new.committed_at), substr(new.committed_at, 1, 10), ''), 1, new.automated IS '')
+	ON CONFLICT (account_id, day) DO UPDATE SET commits = commits + 1, human_commits = human_commits + excluded.human_commits;
+	INSERT INTO daily_repo_commits (account_id, day, repo_name, commits)
+	VALUES (new.account_id, COALESCE(date(new.committed_at), substr(new.committed_at, 1, 10), ''), new.repo_name, 1)
+	ON CONFLICT (account_id, day, repo_name) DO UPDATE SET commits = commits + 1;
+END;
+
+DROP TRIGGER daily_commits_delete;
+CREATE TRIGGER daily_commits_delete AFTER DELETE ON commits BEGIN
+	UPDATE daily_activity SET commits = commits - 1, human_commits = human_commits - (old.automated IS '')
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.committed_at), substr(old.committed_at, 1, 10), '');
+	UPDATE daily_repo_commits SET commits = commits - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.committed_at), substr(old.committed_at, 1, 10), '')
+		AND repo_name = old.repo_name;
+END;
+
+CREATE TRIGGER daily_commits_classify AFTER UPDATE OF automated ON commits
+WHEN (old.automated IS '') != (new.automated IS '') BEGIN
+	UPDATE daily_activity SET human_commits = human_commits + (new.automated IS '') - (old.automated IS '')
+	WHERE account_id = new.account_id AND day = COALESCE(date(new.committed_at), substr(new.committed_at, 1, 10), '');
+END;
@@ -0,0 +1,66 @@
+-- requires: fts5
+-- Moves the full-text indexes from FTS4 to FTS5, which go-sqlite3 compiles in
+-- with the sqlite_fts5 build tag; builds without it skip this migration and
+-- 'ghmon search' is unavailable until one with it opens the database. The
+-- indexes still use the activity tables as external content and are kept in
+-- sync by triggers, so every insert path (fetch, backfill, import) is covered.
+-- Updates only reindex a row when an indexed column changes.
+
+DROP TRIGGER commits_fts_insert;
+DROP TRIGGER commits_fts_delete;
+DROP TRIGGER commits_fts_update_before;
+DROP TRIGGER commits_fts_update_after;
+DROP TRIGGER repos_fts_insert;
+DROP TRIGGER repos_fts_delete;
+DROP TRIGGER repos_fts_update_before;
+DROP TRIGGER repos_fts_update_after;
+DROP TRIGGER stars_fts_insert;
+DROP TRIGGER stars_fts_delete;
+DROP TRIGGER stars_fts_update_before;
+DROP TRIGGER stars_fts_update_after;
+
+DROP TABLE commits_fts;
+DROP TABLE repos_fts;
+DROP TABLE stars_fts;
+
+CREATE VIRTUAL TABLE commits_fts USING fts5(repo_name, message, content='commits', content_rowid='id', tokenize='unicode61');
+CREATE VIRTUAL TABLE repos_fts USING fts5(full_name, description, content='repos', content_rowid='id', tokenize='unicode61');
+CREATE VIRTUAL TABLE stars_fts USING fts5(repo_full_name, repo_description, content='stars', content_rowid='id', tokenize='unicode61');
+
+CREATE TRIGGER commits_fts_insert AFTER INSERT ON commits BEGIN
+	INSERT INTO commits_fts(rowid, repo_name, message) VALUES (new.id, new.repo_name, new.message);
+END;
+CREATE TRIGGER commits_fts_delete AFTER DELETE ON commits BEGIN
+	INSERT INTO commits_fts(commits_fts, rowid, repo_name, message) VALUES ('delete', old.id, old.repo_name, old.message);
+END;
+CREATE TRIGGER commits_fts_update AFTER UPDATE OF repo_name, message ON commits BEGIN
+	INSERT INTO commits_fts(commits_fts, rowid, repo_name, message) VALUES ('delete', old.id, old.repo_name, old.message);
+	INSERT INTO commits_fts(rowid, repo_name, message) VALUES (new.id, new.repo_name, new.message);
+END;
+
+CREATE TRIGGER repos_fts_insert AFTER INSERT ON repos BEGIN
+	INSERT INTO repos_fts(rowid, full_name, description) VALUES (new.id, new.full_name, new.description);
+END;
+CREATE TRIGGER repos_fts_delete AFTER DELETE ON repos BEGIN
+	INSERT INTO repos_fts(repos_fts, rowid, full_name, description) VALUES ('delete', old.id, old.full_name, old.description);
+END;
+CREATE TRIGGER repos_fts_update AFTER UPDATE OF full_name, description ON repos BEGIN
+	INSERT INTO repos_fts(repos_fts, rowid, full_name, description) VALUES ('delete', old.id, old.full_name, old.description);
+	INSERT INTO repos_fts(rowid, full_name, description) VALUES (new.id, new.full_name, new.description);
+END;
+
+CREATE TRIGGER stars_fts_insert AFTER INSERT ON stars BEGIN
+	INSERT INTO stars_fts(rowid, repo_full_name, repo_description) VALUES (new.id, new.repo_full_name, new.repo_description);
+END;
+CREATE TRIGGER stars_fts_delete AFTER DELETE ON stars BEGIN
+	INSERT INTO stars_fts(stars_fts, rowid, repo_full_name, repo_description) VALUES ('delete', old.id, old.repo_full_name, old.repo_description);
+END;
+CREATE TRIGGER stars_fts_update AFTER UPDATE OF repo_full_name, repo_description ON stars BEGIN
+	INSERT INTO stars_fts(stars_fts, rowid, repo_full_name, repo_description) VALUES ('delete', old.id, old.repo_full_name, old.repo_description);
+	INSERT INTO stars_fts(rowid, repo_full_name, repo_description) VALUES (new.id, new.repo_full_name, new.repo_description);
+END;
+
+-- Index activity stored before this migration
+INSERT INTO commits_fts(commits_fts) VALUES ('rebuild');
+INSERT INTO repos_fts(repos_fts) VALUES ('rebuild');
+INSERT INTO stars_fts(stars_fts) VALUES ('rebuild');
@@ -0,0 +1,31 @@
+-- Retention pruning deletes old activity but should keep it in the daily
+-- rollups, which long-range counts read. While a prune runs it holds a row in
+-- pruning, inside its transaction, and the rollup delete triggers skip.
+
+CREATE TABLE pruning (
+	started_at DATETIME NOT NULL
+);
+
+DROP TRIGGER daily_commits_delete;
+CREATE TRIGGER daily_commits_delete AFTER DELETE ON commits
+WHEN NOT EXISTS (SELECT 1 FROM pruning) BEGIN
+	UPDATE daily_activity SET commits = commits - 1, human_commits = human_commits - (old.automated IS '')
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.committed_at), substr(old.committed_at, 1, 10), '');
+	UPDATE daily_repo_commits SET commits = commits - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.committed_at), substr(old.committed_at, 1, 10), '')
+		AND repo_name = old.repo_name;
+END;
+
+DROP TRIGGER daily_repos_delete;
+CREATE TRIGGER daily_repos_delete AFTER DELETE ON repos
+WHEN NOT EXISTS (SELECT 1 FROM pruning) BEGIN
+	UPDATE daily_activity SET repos = repos - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.created_at), substr(old.created_at, 1, 10), '');
+END;
+
+DROP TRIGGER daily_stars_delete;
+CREATE TRIGGER daily_stars_delete AFTER DELETE ON stars
+WHEN NOT EXISTS (SELECT 1 FROM pruning) BEGIN
+	UPDATE daily_activity SET stars = stars - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.starred_at), substr(old.starred_at, 1, 10), '');
+END;
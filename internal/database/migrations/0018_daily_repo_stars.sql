@@ -0,0 +1,37 @@
+-- Per-account, per-day stars of each repository, so trending repos over long
+-- ranges read the rollup instead of every star. The description and language
+-- are the latest seen that day. Like the other daily rollups, retention
+-- pruning leaves them in place.
+
+CREATE TABLE daily_repo_stars (
+	account_id INTEGER NOT NULL,
+	day TEXT NOT NULL,
+	repo_full_name TEXT NOT NULL,
+	repo_description TEXT NOT NULL DEFAULT '',
+	repo_language TEXT NOT NULL DEFAULT '',
+	stars INTEGER DEFAULT 0,
+	PRIMARY KEY (account_id, day, repo_full_name),
+	FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX idx_daily_repo_stars_day ON daily_repo_stars(day);
+
+CREATE TRIGGER daily_repo_stars_insert AFTER INSERT ON stars BEGIN
+	INSERT INTO daily_repo_stars (account_id, day, repo_full_name, repo_description, repo_language, stars)
+	VALUES (new.account_id, COALESCE(date(new.starred_at), substr(new.starred_at, 1, 10), ''), new.repo_full_name,
+		COALESCE(new.repo_description, ''), COALESCE(new.repo_language, ''), 1)
+	ON CONFLICT (account_id, day, repo_full_name) DO UPDATE SET stars = stars + 1,
+		repo_description = excluded.repo_description, repo_language = excluded.repo_language;
+END;
+CREATE TRIGGER daily_repo_stars_delete AFTER DELETE ON stars
+WHEN NOT EXISTS (SELECT 1 FROM pruning) BEGIN
+	UPDATE daily_repo_stars SET stars = stars - 1
+	WHERE account_id = old.account_id AND day = COALESCE(date(old.starred_at), substr(old.starred_at, 1, 10), '')
+		AND repo_full_name = old.repo_full_name;
+END;
+
+-- Roll up stars stored before this migration
+INSERT INTO daily_repo_stars (account_id, day, repo_full_name, repo_description, repo_language, stars)
+SELECT account_id, COALESCE(date(starred_at), substr(starred_at, 1, 10), ''), repo_full_name,
+	COALESCE(repo_description, ''), COALESCE(repo_language, ''), COUNT(*)
+FROM stars
+GROUP BY 1, 2, 3;
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Executor is implemented by both *DB and *Tx so repositories can run either
+// standalone or as part of a transaction
+type Executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	// OnCommit runs fn once the writes made so far are durable: immediately
+	// for a DB, after a successful commit for a Tx
+	OnCommit(fn func())
+}
+
+// Tx is a database transaction that prepares each distinct statement once
+// and reuses it for the rest of the transaction
+type Tx struct {
+	tx       *sql.Tx
+	stmts    map[string]*sql.Stmt
+	onCommit []func()
+}
+
+// OnCommit on a DB runs fn immediately since every statement autocommits
+func (db *DB) OnCommit(fn func()) {
+	fn()
+}
+
+// WithTx runs fn inside a transaction, committing if it returns nil and
+// rolling back otherwise
+func (db *DB) WithTx(fn func(tx *Tx) error) (err error) {
+	sqlTx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+
+	tx := &Tx{tx: sqlTx, stmts: make(map[string]*sql.Stmt)}
+	defer func() {
+		if p := recover(); p != nil {
+			sqlTx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return err
+	}
+
+	for _, fn := range tx.onCommit {
+		fn()
+	}
+	return nil
+}
+
+func (tx *Tx) stmt(query string) (*sql.Stmt, error) {
+	if stmt, ok := tx.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := tx.tx.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	tx.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := tx.stmt(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
+
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := tx.stmt(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	// Prepare errors surface through Row.Scan when using the unprepared path
+	stmt, err := tx.stmt(query)
+	if err != nil {
+		return tx.tx.QueryRow(query, args...)
+	}
+	return stmt.QueryRow(args...)
+}
+
+// OnCommit defers fn until the transaction commits; it is dropped on rollback
+func (tx *Tx) OnCommit(fn func()) {
+	tx.onCommit = append(tx.onCommit, fn)
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithTx(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	committed := false
+	err = db.WithTx(func(tx *Tx) error {
+		for _, name := range []string{"torvalds", "antirez"} {
+			if _, err := tx.Exec(`INSERT INTO accounts (username) VALUES (?)`, name); err != nil {
+				return err
+			}
+		}
+		tx.OnCommit(func() { committed = true })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+	if !committed {
+		t.Error("expected commit hook to run")
+	}
+
+	rolledBack := errors.New("boom")
+	hookRan := false
+	err = db.WithTx(func(tx *Tx) error {
+		tx.Exec(`INSERT INTO accounts (username) VALUES ('gvanrossum')`)
+		tx.OnCommit(func() { hookRan = true })
+		return rolledBack
+	})
+	if !errors.Is(err, rolledBack) {
+		t.Errorf("expected rollback error, got %v", err)
+	}
+	if hookRan {
+		t.Error("expected commit hook not to run on rollback")
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM accounts`).Scan(&count)
+	if count != 2 {
+		t.Errorf("expected 2 accounts after rollback, got %d", count)
+	}
+}
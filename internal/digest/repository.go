@@ -0,0 +1,88 @@
+package digest
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// Record is a saved digest
+type Record struct {
+	ID          int64
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// Content is the digest rendered as markdown
+	Content string
+	// Data is the structured digest as JSON
+	Data          string
+	SmartAnalysis string
+	// Source is the command that generated the digest, e.g. "digest" or "export"
+	Source    string
+	CreatedAt time.Time
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Save stores a digest, filling in its ID and creation time
+func (r *Repository) Save(rec *Record) error {
+	rec.CreatedAt = time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO digests (period_start, period_end, content, data, smart_analysis, source, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, rec.PeriodStart, rec.PeriodEnd, rec.Content, rec.Data, rec.SmartAnalysis, rec.Source, rec.CreatedAt)
+	if err != nil {
+		return err
+	}
+	rec.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// List returns the most recent digests, newest first, without their content
+func (r *Repository) List(limit int) ([]Record, error) {
+	rows, err := r.db.Query(`
+		SELECT id, period_start, period_end, '', '', COALESCE(smart_analysis, ''), COALESCE(source, ''), created_at
+		FROM digests
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *rec)
+	}
+	return records, rows.Err()
+}
+
+func (r *Repository) Get(id int64) (*Record, error) {
+	return scanRecord(r.db.QueryRow(`
+		SELECT id, period_start, period_end, COALESCE(content, ''), COALESCE(data, ''),
+			COALESCE(smart_analysis, ''), COALESCE(source, ''), created_at
+		FROM digests WHERE id = ?
+	`, id))
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRecord(s scanner) (*Record, error) {
+	var rec Record
+	if err := s.Scan(&rec.ID, &rec.PeriodStart, &rec.PeriodEnd, &rec.Content, &rec.Data, &rec.SmartAnalysis, &rec.Source, &rec.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
@@ -0,0 +1,54 @@
+package digest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+func TestSaveListGet(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+	now := time.Now()
+
+	first := &Record{PeriodStart: now.AddDate(0, 0, -14), PeriodEnd: now.AddDate(0, 0, -7), Content: "# week 1", Data: `{"summary":{}}`, Source: "digest"}
+	second := &Record{PeriodStart: now.AddDate(0, 0, -7), PeriodEnd: now, Content: "# week 2", SmartAnalysis: "focus", Source: "export"}
+	for _, rec := range []*Record{first, second} {
+		if err := repo.Save(rec); err != nil {
+			t.Fatalf("failed to save digest: %v", err)
+		}
+	}
+	if first.ID == 0 || second.ID == first.ID {
+		t.Errorf("expected distinct ids, got %d and %d", first.ID, second.ID)
+	}
+
+	records, err := repo.List(10)
+	if err != nil {
+		t.Fatalf("failed to list digests: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != second.ID {
+		t.Fatalf("expected newest digest first, got %+v", records)
+	}
+	if records[0].Content != "" {
+		t.Error("expected list to omit content")
+	}
+
+	got, err := repo.Get(first.ID)
+	if err != nil {
+		t.Fatalf("failed to get digest: %v", err)
+	}
+	if got.Content != "# week 1" || got.Data != `{"summary":{}}` || got.Source != "digest" {
+		t.Errorf("unexpected digest: %+v", got)
+	}
+
+	if _, err := repo.Get(999); err == nil {
+		t.Error("expected error for missing digest")
+	}
+}
@@ -0,0 +1,183 @@
+package fetchrun
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// Run is a single invocation of fetch across a set of accounts
+type Run struct {
+	ID             int64
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+	AccountsTotal  int
+	AccountsFailed int
+	Requests       int
+	Items          int
+}
+
+// AccountResult records what happened while fetching one account during a run
+type AccountResult struct {
+	ID          int64
+	RunID       int64
+	AccountID   int64
+	Username    string
+	Duration    time.Duration
+	Requests    int
+	Commits     int
+	Repos       int
+	Stars       int
+	EventsError string
+	ReposError  string
+	StarsError  string
+	WriteError  string
+}
+
+// Items returns the number of rows inserted for the account
+func (r *AccountResult) Items() int {
+	return r.Commits + r.Repos + r.Stars
+}
+
+// Failed reports whether any endpoint returned an error
+func (r *AccountResult) Failed() bool {
+	return r.EventsError != "" || r.ReposError != "" || r.StarsError != "" || r.WriteError != ""
+}
+
+type Repository struct {
+	db *database.DB
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Start records the beginning of a new run
+func (r *Repository) Start(accountsTotal int) (*Run, error) {
+	startedAt := time.Now()
+	result, err := r.db.Exec(
+		`INSERT INTO fetch_runs (started_at, accounts_total) VALUES (?, ?)`,
+		startedAt, accountsTotal,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result.LastInsertId()
+	return &Run{
+		ID:            id,
+		StartedAt:     startedAt,
+		AccountsTotal: accountsTotal,
+	}, nil
+}
+
+// AddResult stores the outcome for one account and folds it into the run totals
+func (r *Repository) AddResult(run *Run, res *AccountResult) error {
+	res.RunID = run.ID
+	result, err := r.db.Exec(`
+		INSERT INTO fetch_run_accounts (
+			run_id, account_id, username, duration_ms, requests_used,
+			commits, repos, stars, events_error, repos_error, stars_error, write_error
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.ID, res.AccountID, res.Username, res.Duration.Milliseconds(), res.Requests,
+		res.Commits, res.Repos, res.Stars, res.EventsError, res.ReposError, res.StarsError, res.WriteError)
+	if err != nil {
+		return err
+	}
+	res.ID, _ = result.LastInsertId()
+
+	run.Requests += res.Requests
+	run.Items += res.Items()
+	if res.Failed() {
+		run.AccountsFailed++
+	}
+	return nil
+}
+
+// Finish marks the run as complete and persists its totals
+func (r *Repository) Finish(run *Run) error {
+	finishedAt := time.Now()
+	_, err := r.db.Exec(`
+		UPDATE fetch_runs
+		SET finished_at = ?, accounts_failed = ?, requests_used = ?, items_inserted = ?
+		WHERE id = ?
+	`, finishedAt, run.AccountsFailed, run.Requests, run.Items, run.ID)
+	if err != nil {
+		return err
+	}
+	run.FinishedAt = &finishedAt
+	return nil
+}
+
+// List returns the most recent runs, newest first
+func (r *Repository) List(limit int) ([]Run, error) {
+	rows, err := r.db.Query(`
+		SELECT id, started_at, finished_at, accounts_total, accounts_failed, requests_used, items_inserted
+		FROM fetch_runs
+		ORDER BY started_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+func (r *Repository) Get(id int64) (*Run, error) {
+	return scanRun(r.db.QueryRow(`
+		SELECT id, started_at, finished_at, accounts_total, accounts_failed, requests_used, items_inserted
+		FROM fetch_runs WHERE id = ?
+	`, id))
+}
+
+// Results returns the per-account results of a run, failures first
+func (r *Repository) Results(runID int64) ([]AccountResult, error) {
+	rows, err := r.db.Query(`
+		SELECT id, run_id, account_id, username, duration_ms, requests_used,
+			commits, repos, stars, events_error, repos_error, stars_error, write_error
+		FROM fetch_run_accounts
+		WHERE run_id = ?
+		ORDER BY (events_error != '' OR repos_error != '' OR stars_error != '' OR write_error != '') DESC, username
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []AccountResult
+	for rows.Next() {
+		var res AccountResult
+		var durationMs int64
+		if err := rows.Scan(&res.ID, &res.RunID, &res.AccountID, &res.Username, &durationMs, &res.Requests,
+			&res.Commits, &res.Repos, &res.Stars, &res.EventsError, &res.ReposError, &res.StarsError, &res.WriteError); err != nil {
+			return nil, err
+		}
+		res.Duration = time.Duration(durationMs) * time.Millisecond
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRun(s scanner) (*Run, error) {
+	var run Run
+	var finishedAt *time.Time
+	if err := s.Scan(&run.ID, &run.StartedAt, &finishedAt, &run.AccountsTotal, &run.AccountsFailed, &run.Requests, &run.Items); err != nil {
+		return nil, err
+	}
+	run.FinishedAt = finishedAt
+	return &run, nil
+}
@@ -0,0 +1,58 @@
+package fetchrun
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	tmpDir := t.TempDir()
+	db, err := database.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+func TestRunLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewRepository(db)
+
+	run, err := repo.Start(2)
+	if err != nil {
+		t.Fatalf("failed to start run: %v", err)
+	}
+
+	repo.AddResult(run, &AccountResult{AccountID: 1, Username: "torvalds", Requests: 3, Commits: 4, Stars: 1})
+	repo.AddResult(run, &AccountResult{AccountID: 2, Username: "antirez", Requests: 3, StarsError: "GitHub API error: 502 Bad Gateway"})
+
+	if err := repo.Finish(run); err != nil {
+		t.Fatalf("failed to finish run: %v", err)
+	}
+
+	loaded, err := repo.Get(run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run: %v", err)
+	}
+	if loaded.AccountsFailed != 1 || loaded.Requests != 6 || loaded.Items != 5 {
+		t.Errorf("unexpected totals: %+v", loaded)
+	}
+	if loaded.FinishedAt == nil {
+		t.Error("expected finished_at to be set")
+	}
+
+	results, err := repo.Results(run.ID)
+	if err != nil {
+		t.Fatalf("failed to list results: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Username != "antirez" {
+		t.Errorf("expected failed account first, got %s", results[0].Username)
+	}
+}
@@ -3,24 +3,40 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
+	"sync"
 	"time"
 )
 
 const baseURL = "https://api.github.com"
 
 type Client struct {
-	token              string
-	httpClient         *http.Client
-	rateLimitRemaining int
-	rateLimitReset     time.Time
+	token      string
+	httpClient *http.Client
+
+	// mu guards the rate limit, which concurrent requests update
+	mu        sync.Mutex
+	rateLimit RateLimit
+
+	// onWait, if set, reports a rate limit wait instead of printing it
+	onWait func(remaining int, wait time.Duration)
+}
+
+// RateLimit is the API budget from the last response's headers
+type RateLimit struct {
+	Remaining int
+	Limit     int
+	Reset     time.Time
 }
 
 type User struct {
 	Login     string `json:"login"`
+	Type      string `json:"type"`
 	Name      string `json:"name"`
 	AvatarURL string `json:"avatar_url"`
 	Bio       string `json:"bio"`
@@ -29,7 +45,9 @@ type User struct {
 }
 
 type Event struct {
+	ID        string          `json:"id"`
 	Type      string          `json:"type"`
+	Actor     EventActor      `json:"actor"`
 	Repo      EventRepo       `json:"repo"`
 	Payload   json.RawMessage `json:"payload"`
 	CreatedAt time.Time       `json:"created_at"`
@@ -39,19 +57,59 @@ type EventRepo struct {
 	Name string `json:"name"`
 }
 
+type EventActor struct {
+	Login string `json:"login"`
+}
+
 type PushPayload struct {
 	Commits []Commit `json:"commits"`
 }
 
 type Commit struct {
-	SHA     string `json:"sha"`
-	Message string `json:"message"`
+	SHA     string       `json:"sha"`
+	Message string       `json:"message"`
+	Author  CommitAuthor `json:"author"`
+}
+
+// CommitAuthor is a commit's git author, which may differ from the account
+// that pushed it
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
 }
 
 type CreatePayload struct {
 	RefType string `json:"ref_type"`
 }
 
+// ReleasePayload is the payload of a ReleaseEvent
+type ReleasePayload struct {
+	Action  string `json:"action"`
+	Release struct {
+		TagName string `json:"tag_name"`
+		Name    string `json:"name"`
+	} `json:"release"`
+}
+
+// PullRequestPayload is the payload of a PullRequestEvent
+type PullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Merged bool   `json:"merged"`
+	} `json:"pull_request"`
+}
+
+// IssuesPayload is the payload of an IssuesEvent
+type IssuesPayload struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	} `json:"issue"`
+}
+
 type StarredRepo struct {
 	FullName    string    `json:"full_name"`
 	Description string    `json:"description"`
@@ -66,7 +124,38 @@ type Repo struct {
 	Description string    `json:"description"`
 	Language    string    `json:"language"`
 	Stars       int       `json:"stargazers_count"`
+	Fork        bool      `json:"fork"`
 	CreatedAt   time.Time `json:"created_at"`
+	PushedAt    time.Time `json:"pushed_at"`
+}
+
+// RepoCommit is a commit as returned by the repository commits API
+type RepoCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// APIError is returned when GitHub responds with a non-200 status
+type APIError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API error: %s", e.Status)
+}
+
+// IsStatus reports whether err is an APIError with the given status code
+func IsStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
 }
 
 func NewClient(token string) *Client {
@@ -78,32 +167,46 @@ func NewClient(token string) *Client {
 	}
 }
 
-// RateLimitRemaining returns the number of API calls remaining
-func (c *Client) RateLimitRemaining() int {
-	return c.rateLimitRemaining
+// RateLimit returns a snapshot of the current rate limit
+func (c *Client) RateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
 }
 
-// RateLimitReset returns when the rate limit resets
-func (c *Client) RateLimitReset() time.Time {
-	return c.rateLimitReset
+// OnRateLimitWait makes WaitForRateLimit report its waits to fn rather than
+// printing them, e.g. so a live progress view can show them. Set it before
+// making requests.
+func (c *Client) OnRateLimitWait(fn func(remaining int, wait time.Duration)) {
+	c.onWait = fn
 }
 
 // WaitForRateLimit blocks until rate limit resets if we're near the limit
 func (c *Client) WaitForRateLimit() {
-	if c.rateLimitRemaining > 0 && c.rateLimitRemaining < 10 && time.Now().Before(c.rateLimitReset) {
-		waitTime := time.Until(c.rateLimitReset) + time.Second
-		fmt.Printf("Rate limit low (%d remaining), waiting %v...\n", c.rateLimitRemaining, waitTime.Round(time.Second))
+	rl := c.RateLimit()
+	if rl.Remaining > 0 && rl.Remaining < 10 && time.Now().Before(rl.Reset) {
+		waitTime := time.Until(rl.Reset) + time.Second
+		if c.onWait != nil {
+			c.onWait(rl.Remaining, waitTime)
+		} else {
+			// stderr, so structured output on stdout stays a single document
+			fmt.Fprintf(os.Stderr, "Rate limit low (%d remaining), waiting %v...\n", rl.Remaining, waitTime.Round(time.Second))
+		}
 		time.Sleep(waitTime)
 	}
 }
 
 func (c *Client) doRequest(url string) ([]byte, error) {
+	return c.doRequestAccept(url, "application/vnd.github+json")
+}
+
+func (c *Client) doRequestAccept(url, accept string) ([]byte, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Accept", accept)
 	if c.token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
@@ -115,19 +218,26 @@ func (c *Client) doRequest(url string) ([]byte, error) {
 	defer resp.Body.Close()
 
 	// Parse rate limit headers
+	c.mu.Lock()
 	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "" {
 		if val, err := strconv.Atoi(remaining); err == nil {
-			c.rateLimitRemaining = val
+			c.rateLimit.Remaining = val
+		}
+	}
+	if limit := resp.Header.Get("X-RateLimit-Limit"); limit != "" {
+		if val, err := strconv.Atoi(limit); err == nil {
+			c.rateLimit.Limit = val
 		}
 	}
 	if reset := resp.Header.Get("X-RateLimit-Reset"); reset != "" {
 		if val, err := strconv.ParseInt(reset, 10, 64); err == nil {
-			c.rateLimitReset = time.Unix(val, 0)
+			c.rateLimit.Reset = time.Unix(val, 0)
 		}
 	}
+	c.mu.Unlock()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+		return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
 	return io.ReadAll(resp.Body)
@@ -185,50 +295,43 @@ func (c *Client) GetUserEvents(username string) ([]Event, error) {
 	return parseEvents(data)
 }
 
-func parseEvents(data []byte) ([]Event, error) {
-	var events []Event
-	if err := json.Unmarshal(data, &events); err != nil {
+// GetOrgEvents returns recent public events in an organization's repositories
+func (c *Client) GetOrgEvents(org string) ([]Event, error) {
+	url := fmt.Sprintf("%s/orgs/%s/events?per_page=100", baseURL, org)
+	data, err := c.doRequest(url)
+	if err != nil {
 		return nil, err
 	}
-	return events, nil
-}
 
-func (c *Client) GetUserStarred(username string) ([]StarredRepo, error) {
-	url := fmt.Sprintf("%s/users/%s/starred?per_page=100", baseURL, username)
+	return parseEvents(data)
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// GetRepoEvents returns recent public events in a repository
+func (c *Client) GetRepoEvents(fullName string) ([]Event, error) {
+	url := fmt.Sprintf("%s/repos/%s/events?per_page=100", baseURL, fullName)
+	data, err := c.doRequest(url)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/vnd.github.star+json")
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
-	}
+	return parseEvents(data)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+func parseEvents(data []byte) ([]Event, error) {
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	// Parse rate limit headers
-	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "" {
-		if val, err := strconv.Atoi(remaining); err == nil {
-			c.rateLimitRemaining = val
-		}
-	}
-	if reset := resp.Header.Get("X-RateLimit-Reset"); reset != "" {
-		if val, err := strconv.ParseInt(reset, 10, 64); err == nil {
-			c.rateLimitReset = time.Unix(val, 0)
-		}
-	}
+	return events, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
-	}
+func (c *Client) GetUserStarred(username string) ([]StarredRepo, error) {
+	return c.getUserStarredPage(username, 1)
+}
 
-	data, err := io.ReadAll(resp.Body)
+func (c *Client) getUserStarredPage(username string, page int) ([]StarredRepo, error) {
+	url := fmt.Sprintf("%s/users/%s/starred?per_page=100&page=%d", baseURL, username, page)
+	data, err := c.doRequestAccept(url, "application/vnd.github.star+json")
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +359,30 @@ func (c *Client) GetUserStarred(username string) ([]StarredRepo, error) {
 	return repos, nil
 }
 
+// GetUserStarredSince pages through a user's stars, newest first, until it
+// reaches stars older than since
+func (c *Client) GetUserStarredSince(username string, since time.Time) ([]StarredRepo, error) {
+	var all []StarredRepo
+	for page := 1; ; page++ {
+		c.WaitForRateLimit()
+		starred, err := c.getUserStarredPage(username, page)
+		if err != nil {
+			return all, err
+		}
+		if len(starred) == 0 {
+			break
+		}
+
+		for _, s := range starred {
+			if s.StarredAt.Before(since) {
+				return all, nil
+			}
+			all = append(all, s)
+		}
+	}
+	return all, nil
+}
+
 func (c *Client) GetUserRepos(username string) ([]Repo, error) {
 	url := fmt.Sprintf("%s/users/%s/repos?per_page=100&sort=created&direction=desc", baseURL, username)
 	data, err := c.doRequest(url)
@@ -271,6 +398,85 @@ func (c *Client) GetUserRepos(username string) ([]Repo, error) {
 	return repos, nil
 }
 
+// GetRepo returns a single repository by its full name
+func (c *Client) GetRepo(fullName string) (*Repo, error) {
+	url := fmt.Sprintf("%s/repos/%s", baseURL, fullName)
+	data, err := c.doRequest(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var repo Repo
+	if err := json.Unmarshal(data, &repo); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// GetOrgRepos returns an organization's most recently created repositories
+func (c *Client) GetOrgRepos(org string) ([]Repo, error) {
+	url := fmt.Sprintf("%s/orgs/%s/repos?per_page=100&sort=created&direction=desc", baseURL, org)
+	data, err := c.doRequest(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repo
+	if err := json.Unmarshal(data, &repos); err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// GetAllUserRepos pages through every repository owned by a user, newest first
+func (c *Client) GetAllUserRepos(username string) ([]Repo, error) {
+	var all []Repo
+	for page := 1; ; page++ {
+		c.WaitForRateLimit()
+		url := fmt.Sprintf("%s/users/%s/repos?per_page=100&sort=created&direction=desc&page=%d", baseURL, username, page)
+		data, err := c.doRequest(url)
+		if err != nil {
+			return all, err
+		}
+
+		var repos []Repo
+		if err := json.Unmarshal(data, &repos); err != nil {
+			return all, err
+		}
+		if len(repos) == 0 {
+			break
+		}
+		all = append(all, repos...)
+	}
+	return all, nil
+}
+
+// GetRepoCommits pages through the commits authored by author on a repository since the given time
+func (c *Client) GetRepoCommits(fullName, author string, since time.Time) ([]RepoCommit, error) {
+	var all []RepoCommit
+	for page := 1; ; page++ {
+		c.WaitForRateLimit()
+		url := fmt.Sprintf("%s/repos/%s/commits?author=%s&since=%s&per_page=100&page=%d",
+			baseURL, fullName, author, since.UTC().Format(time.RFC3339), page)
+		data, err := c.doRequest(url)
+		if err != nil {
+			return all, err
+		}
+
+		var commits []RepoCommit
+		if err := json.Unmarshal(data, &commits); err != nil {
+			return all, err
+		}
+		if len(commits) == 0 {
+			break
+		}
+		all = append(all, commits...)
+	}
+	return all, nil
+}
+
 func ParsePushPayload(payload json.RawMessage) (*PushPayload, error) {
 	var p PushPayload
 	if err := json.Unmarshal(payload, &p); err != nil {
@@ -286,3 +492,81 @@ func ParseCreatePayload(payload json.RawMessage) (*CreatePayload, error) {
 	}
 	return &p, nil
 }
+
+func ParseReleasePayload(payload json.RawMessage) (*ReleasePayload, error) {
+	var p ReleasePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func ParsePullRequestPayload(payload json.RawMessage) (*PullRequestPayload, error) {
+	var p PullRequestPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func ParseIssuesPayload(payload json.RawMessage) (*IssuesPayload, error) {
+	var p IssuesPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Contributor is a repository contributor as returned by the contributors API
+type Contributor struct {
+	Login         string `json:"login"`
+	Type          string `json:"type"`
+	Contributions int    `json:"contributions"`
+}
+
+// listPages pages through a list endpoint, stopping after max items when max
+// is positive
+func listPages[T any](c *Client, url string, max int) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		c.WaitForRateLimit()
+		data, err := c.doRequest(fmt.Sprintf("%s?per_page=100&page=%d", url, page))
+		if err != nil {
+			return all, err
+		}
+
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			return all, err
+		}
+		if len(items) == 0 {
+			break
+		}
+		all = append(all, items...)
+		if max > 0 && len(all) >= max {
+			return all[:max], nil
+		}
+	}
+	return all, nil
+}
+
+// GetOrgMembers returns an organization's members; only public members are
+// visible unless the token belongs to a member
+func (c *Client) GetOrgMembers(org string) ([]User, error) {
+	return listPages[User](c, fmt.Sprintf("%s/orgs/%s/members", baseURL, org), 0)
+}
+
+// GetRepoContributors returns up to max contributors, most contributions first
+func (c *Client) GetRepoContributors(fullName string, max int) ([]Contributor, error) {
+	return listPages[Contributor](c, fmt.Sprintf("%s/repos/%s/contributors", baseURL, fullName), max)
+}
+
+// GetStargazers returns up to max users who starred a repository, oldest first
+func (c *Client) GetStargazers(fullName string, max int) ([]User, error) {
+	return listPages[User](c, fmt.Sprintf("%s/repos/%s/stargazers", baseURL, fullName), max)
+}
+
+// GetUserFollowing returns the accounts another user follows
+func (c *Client) GetUserFollowing(username string) ([]User, error) {
+	return listPages[User](c, fmt.Sprintf("%s/users/%s/following", baseURL, username), 0)
+}
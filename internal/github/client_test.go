@@ -2,7 +2,12 @@
 package github
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -33,3 +38,65 @@ func TestParseEventsJSON(t *testing.T) {
 		t.Errorf("expected PushEvent, got %s", events[0].Type)
 	}
 }
+
+func TestParsePushPayloadAuthor(t *testing.T) {
+	payload, err := ParsePushPayload([]byte(`{"commits": [
+		{"sha": "abc123", "message": "Bump x/net", "author": {"name": "dependabot[bot]", "email": "49699333+dependabot[bot]@users.noreply.github.com"}},
+		{"sha": "def456", "message": "test commit"}
+	]}`))
+	if err != nil {
+		t.Fatalf("failed to parse push payload: %v", err)
+	}
+	if len(payload.Commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(payload.Commits))
+	}
+
+	author := payload.Commits[0].Author
+	if author.Name != "dependabot[bot]" || author.Email != "49699333+dependabot[bot]@users.noreply.github.com" {
+		t.Errorf("expected the git author from the push payload, got %+v", author)
+	}
+	if payload.Commits[1].Author != (CommitAuthor{}) {
+		t.Errorf("expected no author when the payload has none, got %+v", payload.Commits[1].Author)
+	}
+}
+
+func TestIsStatus(t *testing.T) {
+	err := fmt.Errorf("listing commits: %w", &APIError{StatusCode: 409, Status: "409 Conflict"})
+
+	if !IsStatus(err, 409) {
+		t.Error("expected wrapped 409 to match")
+	}
+	if IsStatus(err, 404) {
+		t.Error("expected 409 not to match 404")
+	}
+	if err.Error() != "listing commits: GitHub API error: 409 Conflict" {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4990")
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", "1735743840")
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.doRequest(server.URL)
+			client.RateLimit()
+		}()
+	}
+	wg.Wait()
+
+	rl := client.RateLimit()
+	if rl.Remaining != 4990 || rl.Limit != 5000 || !rl.Reset.Equal(time.Unix(1735743840, 0)) {
+		t.Errorf("unexpected rate limit: %+v", rl)
+	}
+}
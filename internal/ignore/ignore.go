@@ -0,0 +1,258 @@
+// Package ignore leaves activity matching user-defined rules out of digests,
+// exports and 'show', including watched repositories' events. Rules are applied when reading, so changing them never
+// needs a refetch.
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+// Event kinds a rule can leave out entirely
+const (
+	EventCommits = "commits"
+	EventRepos   = "repos"
+	EventStars   = "stars"
+)
+
+// Matcher reports whether activity matches any ignore rule. A nil Matcher
+// matches nothing.
+type Matcher struct {
+	repos     []*regexp.Regexp
+	messages  []*regexp.Regexp
+	authors   []*regexp.Regexp
+	languages map[string]bool
+	events    map[string]bool
+}
+
+// Load compiles the rules in cfg, which may be nil, together with the rules
+// in the ignore file, if there is one
+func Load(cfg *config.Config) (*Matcher, error) {
+	var rules []config.IgnoreConfig
+	if cfg != nil {
+		rules = append(rules, cfg.Ignore)
+	}
+
+	f, err := os.Open(config.IgnorePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+	if err == nil {
+		defer f.Close()
+		fileRules, err := Parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.IgnorePath(), err)
+		}
+		rules = append(rules, fileRules)
+	}
+
+	return Compile(rules...)
+}
+
+// Parse reads an ignore file: one rule per line as 'kind: pattern', where
+// kind is repo, message, author, language or event. A line without a kind is
+// a repo pattern. Blank lines and lines starting with # are skipped.
+func Parse(r io.Reader) (config.IgnoreConfig, error) {
+	var rules config.IgnoreConfig
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kind, pattern := "repo", line
+		if i := strings.Index(line, ":"); i > 0 && !strings.ContainsAny(line[:i], " */?") {
+			kind, pattern = strings.ToLower(line[:i]), strings.TrimSpace(line[i+1:])
+		}
+		if pattern == "" {
+			return rules, fmt.Errorf("line %d: empty %s pattern", n, kind)
+		}
+
+		switch kind {
+		case "repo", "repos":
+			rules.Repos = append(rules.Repos, pattern)
+		case "message", "messages":
+			rules.Messages = append(rules.Messages, pattern)
+		case "author", "authors":
+			rules.Authors = append(rules.Authors, pattern)
+		case "language", "languages":
+			rules.Languages = append(rules.Languages, pattern)
+		case "event", "events":
+			rules.Events = append(rules.Events, pattern)
+		default:
+			return rules, fmt.Errorf("line %d: unknown rule '%s' (expected repo, message, author, language or event)", n, kind)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// Compile checks and combines rules
+func Compile(rules ...config.IgnoreConfig) (*Matcher, error) {
+	m := &Matcher{languages: make(map[string]bool), events: make(map[string]bool)}
+
+	for _, r := range rules {
+		for _, p := range r.Repos {
+			m.repos = append(m.repos, glob(p))
+		}
+		for _, p := range r.Authors {
+			m.authors = append(m.authors, glob(p))
+		}
+		for _, p := range r.Messages {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid message pattern '%s': %w", p, err)
+			}
+			m.messages = append(m.messages, re)
+		}
+		for _, l := range r.Languages {
+			m.languages[strings.ToLower(strings.TrimSpace(l))] = true
+		}
+		for _, e := range r.Events {
+			event := strings.ToLower(strings.TrimSpace(e))
+			switch event {
+			case "commit", EventCommits, "events":
+				m.events[EventCommits] = true
+			case "repo", EventRepos:
+				m.events[EventRepos] = true
+			case "star", EventStars:
+				m.events[EventStars] = true
+			default:
+				return nil, fmt.Errorf("unknown event type '%s' (expected commits, repos or stars)", e)
+			}
+		}
+	}
+	return m, nil
+}
+
+// glob compiles a case-insensitive pattern where * matches any run of
+// characters, including '/', and ? any single character. Everything else is
+// literal, so bot names like 'dependabot[bot]' need no escaping.
+func glob(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	for _, r := range strings.TrimSpace(pattern) {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// Empty reports whether there are no rules
+func (m *Matcher) Empty() bool {
+	return m == nil || (len(m.repos) == 0 && len(m.messages) == 0 && len(m.authors) == 0 &&
+		len(m.languages) == 0 && len(m.events) == 0)
+}
+
+// HasCommitRules reports whether any rule looks at individual commits, as
+// opposed to whole accounts. Author rules do, as they also match commits'
+// git authors.
+func (m *Matcher) HasCommitRules() bool {
+	return m != nil && (m.events[EventCommits] || len(m.repos) > 0 || len(m.messages) > 0 || len(m.authors) > 0)
+}
+
+// HasRepoRules reports whether any rule looks at individual new repositories
+func (m *Matcher) HasRepoRules() bool {
+	return m != nil && (m.events[EventRepos] || len(m.repos) > 0 || len(m.languages) > 0)
+}
+
+// HasStarRules reports whether any rule looks at individual stars
+func (m *Matcher) HasStarRules() bool {
+	return m != nil && (m.events[EventStars] || len(m.repos) > 0 || len(m.languages) > 0)
+}
+
+// IgnoresAuthor reports whether all of an account's activity is ignored
+func (m *Matcher) IgnoresAuthor(username string) bool {
+	return m != nil && matchAny(m.authors, username)
+}
+
+// IgnoresCommit reports whether a commit is ignored by its repository, its
+// message or its git author's name or email, so bot commits pushed by a
+// monitored account can be left out
+func (m *Matcher) IgnoresCommit(c activity.Commit) bool {
+	if m == nil {
+		return false
+	}
+	if m.events[EventCommits] || m.ignoresRepoName(c.RepoName) {
+		return true
+	}
+	if (c.Author.Name != "" && matchAny(m.authors, c.Author.Name)) ||
+		(c.Author.Email != "" && matchAny(m.authors, c.Author.Email)) {
+		return true
+	}
+	for _, re := range m.messages {
+		if re.MatchString(c.Message) {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoresRepo reports whether a new repository is ignored
+func (m *Matcher) IgnoresRepo(r activity.Repo) bool {
+	if m == nil {
+		return false
+	}
+	return m.events[EventRepos] || m.ignoresRepoName(r.FullName) || m.languages[strings.ToLower(r.Language)]
+}
+
+// IgnoresStar reports whether a star is ignored by the starred repository
+func (m *Matcher) IgnoresStar(s activity.Star) bool {
+	if m == nil {
+		return false
+	}
+	return m.events[EventStars] || m.ignoresRepoName(s.RepoFullName) || m.languages[strings.ToLower(s.RepoLanguage)]
+}
+
+// IgnoresWatchedRepo reports whether a watched repository is left out by its
+// name or language
+func (m *Matcher) IgnoresWatchedRepo(r watch.Repo) bool {
+	if m == nil {
+		return false
+	}
+	return m.ignoresRepoName(r.FullName) || m.languages[strings.ToLower(r.Language)]
+}
+
+// IgnoresWatchedEvent reports whether an event in a watched repository is
+// ignored by its actor or, for pushes, by the commits event rule
+func (m *Matcher) IgnoresWatchedEvent(e watch.Event) bool {
+	if m == nil {
+		return false
+	}
+	return matchAny(m.authors, e.Actor) || (e.Type == watch.EventPush && m.events[EventCommits])
+}
+
+// ignoresRepoName matches repo patterns against owner/name and name alone
+func (m *Matcher) ignoresRepoName(fullName string) bool {
+	name := fullName
+	if i := strings.LastIndex(fullName, "/"); i >= 0 {
+		name = fullName[i+1:]
+	}
+	return matchAny(m.repos, fullName) || matchAny(m.repos, name)
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+func TestParse(t *testing.T) {
+	rules, err := Parse(strings.NewReader(`
+# Personal repos nobody needs to hear about
+dotfiles
+repo: */*-learning
+message: ^Merge (branch|pull request)
+author: dependabot[bot]
+language: Jupyter Notebook
+event: stars
+`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	if len(rules.Repos) != 2 || rules.Repos[0] != "dotfiles" || rules.Repos[1] != "*/*-learning" {
+		t.Errorf("unexpected repo rules: %v", rules.Repos)
+	}
+	if len(rules.Messages) != 1 || rules.Messages[0] != "^Merge (branch|pull request)" {
+		t.Errorf("unexpected message rules: %v", rules.Messages)
+	}
+	if len(rules.Authors) != 1 || len(rules.Languages) != 1 || len(rules.Events) != 1 {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	if _, err := Parse(strings.NewReader("colour: blue")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected an unknown rule to be reported with its line, got %v", err)
+	}
+}
+
+func TestCompileRejectsBadRules(t *testing.T) {
+	if _, err := Compile(config.IgnoreConfig{Messages: []string{"(unclosed"}}); err == nil {
+		t.Error("expected an invalid regex to be rejected")
+	}
+	if _, err := Compile(config.IgnoreConfig{Events: []string{"forks"}}); err == nil {
+		t.Error("expected an unknown event type to be rejected")
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m, err := Compile(
+		config.IgnoreConfig{
+			Repos:    []string{"dotfiles", "*-learning"},
+			Messages: []string{`^Merge branch`},
+			Authors:  []string{"*[bot]"},
+		},
+		config.IgnoreConfig{Languages: []string{"jupyter notebook"}},
+	)
+	if err != nil {
+		t.Fatalf("failed to compile: %v", err)
+	}
+
+	if !m.IgnoresAuthor("dependabot[bot]") || m.IgnoresAuthor("bot") {
+		t.Error("expected only usernames ending in [bot] to be ignored")
+	}
+
+	commits := []struct {
+		commit  activity.Commit
+		ignored bool
+	}{
+		{activity.Commit{RepoName: "rsc/dotfiles", Message: "vim"}, true},
+		{activity.Commit{RepoName: "karpathy/rust-learning", Message: "day 1"}, true},
+		{activity.Commit{RepoName: "golang/go", Message: "Merge branch 'master'"}, true},
+		{activity.Commit{RepoName: "golang/go", Message: "cmd/go: fix build"}, false},
+		{activity.Commit{RepoName: "rsc/dotfiles-docs", Message: "docs"}, false},
+		// Pushed by a monitored account, authored by a bot
+		{activity.Commit{RepoName: "golang/go", Message: "Bump x/net", Author: analysis.CommitAuthor{Name: "dependabot[bot]"}}, true},
+		{activity.Commit{RepoName: "golang/go", Message: "Bump x/net", Author: analysis.CommitAuthor{Name: "Renovate", Email: "renovate[bot]"}}, true},
+		{activity.Commit{RepoName: "golang/go", Message: "cmd/go: fix build", Author: analysis.CommitAuthor{Name: "Russ Cox", Email: "rsc@golang.org"}}, false},
+	}
+	for _, tt := range commits {
+		if got := m.IgnoresCommit(tt.commit); got != tt.ignored {
+			t.Errorf("IgnoresCommit(%+v) = %v, want %v", tt.commit, got, tt.ignored)
+		}
+	}
+
+	if !m.IgnoresRepo(activity.Repo{FullName: "karpathy/notebooks", Language: "Jupyter Notebook"}) {
+		t.Error("expected a repo to be ignored by language")
+	}
+	if m.IgnoresStar(activity.Star{RepoFullName: "golang/go", RepoLanguage: "Go"}) {
+		t.Error("expected a star on golang/go to be kept")
+	}
+
+	events, _ := Compile(config.IgnoreConfig{Events: []string{"stars"}})
+	if !events.IgnoresStar(activity.Star{RepoFullName: "golang/go"}) || events.IgnoresRepo(activity.Repo{FullName: "golang/go"}) {
+		t.Error("expected the stars event rule to ignore stars only")
+	}
+	if !events.HasStarRules() || events.HasCommitRules() || events.HasRepoRules() {
+		t.Error("expected the stars event rule to only look at stars")
+	}
+
+	authors, _ := Compile(config.IgnoreConfig{Authors: []string{"*[bot]"}})
+	if !authors.HasCommitRules() || authors.HasRepoRules() || authors.HasStarRules() {
+		t.Error("expected author rules to look at accounts and commits only")
+	}
+
+	if !m.IgnoresWatchedRepo(watch.Repo{FullName: "rsc/dotfiles"}) || m.IgnoresWatchedRepo(watch.Repo{FullName: "golang/go", Language: "Go"}) {
+		t.Error("expected watched repos to be ignored by name only")
+	}
+	if !m.IgnoresWatchedEvent(watch.Event{Type: watch.EventPullRequest, Actor: "renovate[bot]"}) || m.IgnoresWatchedEvent(watch.Event{Type: watch.EventPush, Actor: "rsc"}) {
+		t.Error("expected watched events to be ignored by actor")
+	}
+	pushes, _ := Compile(config.IgnoreConfig{Events: []string{"commits"}})
+	if !pushes.IgnoresWatchedEvent(watch.Event{Type: watch.EventPush}) || pushes.IgnoresWatchedEvent(watch.Event{Type: watch.EventRelease}) {
+		t.Error("expected the commits event rule to ignore watched pushes only")
+	}
+
+	var none *Matcher
+	if !none.Empty() || none.HasCommitRules() || none.IgnoresCommit(activity.Commit{RepoName: "rsc/dotfiles"}) {
+		t.Error("expected a nil matcher to ignore nothing")
+	}
+}
+
+func TestLoadReadsIgnoreFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	m, err := Load(nil)
+	if err != nil || !m.Empty() {
+		t.Fatalf("expected no rules without an ignore file, got %v", err)
+	}
+
+	os.MkdirAll(filepath.Join(home, ".ghmon"), 0755)
+	os.WriteFile(config.IgnorePath(), []byte("dotfiles\n"), 0644)
+
+	cfg := config.DefaultConfig()
+	cfg.Ignore.Events = []string{"stars"}
+	m, err = Load(cfg)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if !m.IgnoresRepo(activity.Repo{FullName: "rsc/dotfiles"}) || !m.IgnoresStar(activity.Star{RepoFullName: "golang/go"}) {
+		t.Error("expected rules from both the config and the ignore file")
+	}
+}
@@ -0,0 +1,133 @@
+// Package importer turns lists of GitHub users from various sources into
+// accounts to monitor
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+)
+
+// Candidate is an account an import source proposes to monitor
+type Candidate struct {
+	Username string
+	// Detail says why the source picked the account, e.g. "412 contributions"
+	Detail string
+}
+
+// Plan sorts candidates by what importing them would do
+type Plan struct {
+	// New are not known to ghmon yet
+	New []Candidate
+	// Reactivate were removed from monitoring with their history kept
+	Reactivate []Candidate
+	// Monitored are already being monitored
+	Monitored []Candidate
+}
+
+// Total returns the number of candidates in the plan
+func (p *Plan) Total() int {
+	return len(p.New) + len(p.Reactivate) + len(p.Monitored)
+}
+
+// NewPlan compares candidates with accounts (including removed ones, see
+// account.Repository.ListAll), dropping duplicate candidates. GitHub
+// usernames are case-insensitive.
+func NewPlan(accounts []account.Account, candidates []Candidate) *Plan {
+	known := make(map[string]account.Account)
+	for _, a := range accounts {
+		known[strings.ToLower(a.Username)] = a
+	}
+
+	plan := &Plan{}
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		key := strings.ToLower(c.Username)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		a, ok := known[key]
+		switch {
+		case !ok:
+			plan.New = append(plan.New, c)
+		case a.RemovedAt != nil:
+			c.Username = a.Username
+			plan.Reactivate = append(plan.Reactivate, c)
+		default:
+			c.Username = a.Username
+			plan.Monitored = append(plan.Monitored, c)
+		}
+	}
+	return plan
+}
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+
+// ValidUsername reports whether s is a well-formed GitHub username
+func ValidUsername(s string) bool {
+	return usernamePattern.MatchString(s)
+}
+
+// ParseList reads usernames from a plain text file, one per line, or a CSV
+// file whose first column holds the username. Blank lines, '#' comments and
+// a "username" or "login" header are skipped; "@user" and profile URLs are
+// accepted.
+func ParseList(r io.Reader) ([]Candidate, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]string
+	if strings.Contains(string(data), ",") {
+		cr := csv.NewReader(strings.NewReader(string(data)))
+		cr.FieldsPerRecord = -1
+		cr.Comment = '#'
+		cr.TrimLeadingSpace = true
+		lines, err = cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			lines = append(lines, []string{scanner.Text()})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates []Candidate
+	for i, fields := range lines {
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.TrimSpace(fields[0])
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		if i == 0 && (strings.EqualFold(name, "username") || strings.EqualFold(name, "login")) {
+			continue
+		}
+
+		name = strings.TrimPrefix(name, "@")
+		for _, prefix := range []string{"https://github.com/", "http://github.com/", "github.com/"} {
+			name = strings.TrimPrefix(name, prefix)
+		}
+		name = strings.TrimSuffix(name, "/")
+
+		if !ValidUsername(name) {
+			return nil, fmt.Errorf("line %d: '%s' is not a GitHub username", i+1, fields[0])
+		}
+		candidates = append(candidates, Candidate{Username: name})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+)
+
+func usernames(candidates []Candidate) string {
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.Username)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestParseListText(t *testing.T) {
+	input := `# people to watch
+torvalds
+
+@antirez
+https://github.com/rsc/
+`
+	got, err := ParseList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to parse list: %v", err)
+	}
+	if usernames(got) != "torvalds,antirez,rsc" {
+		t.Errorf("unexpected usernames: %s", usernames(got))
+	}
+}
+
+func TestParseListCSV(t *testing.T) {
+	input := "login,name,notes\ntorvalds,Linus Torvalds,\"kernel, git\"\nantirez, Salvatore,\n"
+	got, err := ParseList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if usernames(got) != "torvalds,antirez" {
+		t.Errorf("unexpected usernames: %s", usernames(got))
+	}
+}
+
+func TestParseListRejectsInvalidNames(t *testing.T) {
+	if _, err := ParseList(strings.NewReader("torvalds\nnot a user\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error naming line 2, got %v", err)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	removed := time.Now()
+	accounts := []account.Account{
+		{Username: "Torvalds"},
+		{Username: "karpathy", RemovedAt: &removed},
+	}
+	candidates := []Candidate{
+		{Username: "torvalds"},
+		{Username: "karpathy"},
+		{Username: "rsc", Detail: "412 contributions"},
+		{Username: "RSC"},
+	}
+
+	plan := NewPlan(accounts, candidates)
+
+	if usernames(plan.New) != "rsc" || plan.New[0].Detail != "412 contributions" {
+		t.Errorf("unexpected new accounts: %+v", plan.New)
+	}
+	if usernames(plan.Reactivate) != "karpathy" {
+		t.Errorf("unexpected reactivated accounts: %+v", plan.Reactivate)
+	}
+	if usernames(plan.Monitored) != "Torvalds" {
+		t.Errorf("expected stored username casing, got %+v", plan.Monitored)
+	}
+	if plan.Total() != 3 {
+		t.Errorf("expected 3 candidates, got %d", plan.Total())
+	}
+}
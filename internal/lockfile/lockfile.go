@@ -0,0 +1,94 @@
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Lock is an exclusive advisory lock on a file. The operating system
+// releases it if the holding process dies, so a crash never leaves a stale lock.
+type Lock struct {
+	file *os.File
+}
+
+// HeldError is returned when another process already holds the lock
+type HeldError struct {
+	Path string
+	PID  int
+}
+
+func (e *HeldError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("%s is locked by another process", e.Path)
+	}
+	return fmt.Sprintf("%s is locked by process %d", e.Path, e.PID)
+}
+
+// errWouldBlock is returned by lockFile when another process holds the lock
+var errWouldBlock = errors.New("lock held")
+
+// IsHeld reports whether err means the lock is held elsewhere
+func IsHeld(err error) bool {
+	var held *HeldError
+	return errors.As(err, &held)
+}
+
+// TryAcquire takes the lock at path without waiting, recording the current
+// PID in the file. It returns a *HeldError if another process holds it.
+func TryAcquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(file); err != nil {
+		defer file.Close()
+		if errors.Is(err, errWouldBlock) {
+			return nil, &HeldError{Path: path, PID: readPID(file)}
+		}
+		return nil, err
+	}
+
+	if err := writePID(file); err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, fmt.Errorf("failed to record PID in %s: %w", path, err)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Release gives up the lock, clearing the recorded PID first
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	truncateErr := l.file.Truncate(0)
+	unlockErr := unlockFile(l.file)
+	closeErr := l.file.Close()
+	l.file = nil
+	return errors.Join(truncateErr, unlockErr, closeErr)
+}
+
+func writePID(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+func readPID(file *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := file.ReadAt(buf, 0)
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	return pid
+}
@@ -0,0 +1,37 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTryAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fetch.lock")
+
+	lock, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("failed to acquire lock: %v", err)
+	}
+
+	_, err = TryAcquire(path)
+	if !IsHeld(err) {
+		t.Fatalf("expected lock to be held, got %v", err)
+	}
+	if pid := err.(*HeldError).PID; pid != os.Getpid() {
+		t.Errorf("expected holder pid %d, got %d", os.Getpid(), pid)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("failed to release lock: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+		t.Errorf("expected release to clear the recorded pid, got %q (%v)", data, err)
+	}
+
+	again, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("expected lock to be free after release, got %v", err)
+	}
+	again.Release()
+}
@@ -0,0 +1,192 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tracker holds the live state of a fetch for display
+type Tracker struct {
+	mu        sync.Mutex
+	total     int
+	done      int
+	failed    int
+	workers   []string
+	startedAt time.Time
+
+	rateRemaining int
+	rateLimit     int
+	rateReset     time.Time
+	waitUntil     time.Time
+}
+
+func NewTracker(total, workers int) *Tracker {
+	return &Tracker{
+		total:     total,
+		workers:   make([]string, workers),
+		startedAt: time.Now(),
+	}
+}
+
+// Start records that a worker picked up an account
+func (t *Tracker) Start(worker int, username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if worker >= 0 && worker < len(t.workers) {
+		t.workers[worker] = username
+	}
+}
+
+// Finish records that a worker completed its current account
+func (t *Tracker) Finish(worker int, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if worker >= 0 && worker < len(t.workers) {
+		t.workers[worker] = ""
+	}
+	t.done++
+	if failed {
+		t.failed++
+	}
+}
+
+// SetRateLimit updates the API budget shown in the view
+func (t *Tracker) SetRateLimit(remaining, limit int, reset time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rateRemaining = remaining
+	t.rateLimit = limit
+	t.rateReset = reset
+}
+
+// SetWaiting records that requests are paused until the rate limit resets
+func (t *Tracker) SetWaiting(until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waitUntil = until
+}
+
+// ETA estimates the time left from the average time per finished account
+func (t *Tracker) ETA(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.eta(now)
+}
+
+func (t *Tracker) eta(now time.Time) time.Duration {
+	if t.done == 0 {
+		return 0
+	}
+	perAccount := now.Sub(t.startedAt) / time.Duration(t.done)
+	return perAccount * time.Duration(t.total-t.done)
+}
+
+// Render draws the current state as a block of lines
+func (t *Tracker) Render(now time.Time) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	const barWidth = 30
+	pct := 0.0
+	if t.total > 0 {
+		pct = float64(t.done) / float64(t.total)
+	}
+	filled := int(pct * barWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	lines := []string{
+		fmt.Sprintf("Fetching %d accounts  [%s] %3.0f%%  %d/%d · %d failed",
+			t.total, bar, pct*100, t.done, t.total, t.failed),
+	}
+
+	status := "ETA calculating..."
+	if t.done > 0 {
+		status = fmt.Sprintf("ETA %s", t.eta(now).Round(time.Second))
+	}
+	if t.rateLimit > 0 {
+		status += fmt.Sprintf(" · API %d/%d remaining, resets %s",
+			t.rateRemaining, t.rateLimit, t.rateReset.Format("15:04"))
+	}
+	if now.Before(t.waitUntil) {
+		status += fmt.Sprintf(" · rate limit low, waiting %s", t.waitUntil.Sub(now).Round(time.Second))
+	}
+	lines = append(lines, status, "")
+
+	for i, username := range t.workers {
+		if username == "" {
+			username = "idle"
+		}
+		lines = append(lines, fmt.Sprintf("  worker %-2d %s", i+1, username))
+	}
+	return lines
+}
+
+// Display periodically redraws a Tracker in place on a terminal
+type Display struct {
+	tracker  *Tracker
+	out      io.Writer
+	interval time.Duration
+	refresh  func()
+	drawn    int
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewDisplay creates a display; refresh, if set, is called before each redraw
+// so callers can update values such as the rate limit
+func NewDisplay(tracker *Tracker, out io.Writer, refresh func()) *Display {
+	return &Display{
+		tracker:  tracker,
+		out:      out,
+		interval: 100 * time.Millisecond,
+		refresh:  refresh,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+func (d *Display) Start() {
+	go func() {
+		defer close(d.stopped)
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			d.draw()
+			select {
+			case <-d.stop:
+				d.draw()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop draws a final frame and returns once the display has finished
+func (d *Display) Stop() {
+	close(d.stop)
+	<-d.stopped
+}
+
+func (d *Display) draw() {
+	if d.refresh != nil {
+		d.refresh()
+	}
+	lines := d.tracker.Render(time.Now())
+
+	var sb strings.Builder
+	if d.drawn > 0 {
+		// Move back to the top of the previous frame
+		fmt.Fprintf(&sb, "\033[%dA", d.drawn)
+	}
+	for _, line := range lines {
+		sb.WriteString("\r\033[2K")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	d.drawn = len(lines)
+	io.WriteString(d.out, sb.String())
+}
@@ -0,0 +1,50 @@
+package progress
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackerRender(t *testing.T) {
+	tracker := NewTracker(4, 2)
+	tracker.Start(0, "torvalds")
+	tracker.Start(1, "antirez")
+	tracker.Finish(1, true)
+	tracker.SetRateLimit(4990, 5000, time.Date(2025, 1, 1, 15, 4, 0, 0, time.Local))
+
+	lines := tracker.Render(time.Now())
+
+	if !strings.Contains(lines[0], "1/4 · 1 failed") {
+		t.Errorf("unexpected progress line: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "API 4990/5000 remaining, resets 15:04") {
+		t.Errorf("unexpected status line: %s", lines[1])
+	}
+	if !strings.Contains(lines[3], "torvalds") || !strings.Contains(lines[4], "idle") {
+		t.Errorf("unexpected worker lines: %v", lines[3:])
+	}
+
+	now := time.Now()
+	tracker.SetWaiting(now.Add(90 * time.Second))
+	if lines := tracker.Render(now); !strings.Contains(lines[1], "rate limit low, waiting 1m30s") {
+		t.Errorf("expected the wait in the status line, got %s", lines[1])
+	}
+	if lines := tracker.Render(now.Add(2 * time.Minute)); strings.Contains(lines[1], "waiting") {
+		t.Errorf("expected the wait gone once over, got %s", lines[1])
+	}
+}
+
+func TestTrackerETA(t *testing.T) {
+	tracker := NewTracker(4, 1)
+	if tracker.ETA(time.Now()) != 0 {
+		t.Error("expected no ETA before any account finished")
+	}
+
+	tracker.startedAt = time.Now().Add(-10 * time.Second)
+	tracker.Finish(0, false)
+	eta := tracker.ETA(time.Now())
+	if eta < 29*time.Second || eta > 31*time.Second {
+		t.Errorf("expected ETA around 30s, got %v", eta)
+	}
+}
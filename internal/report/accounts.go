@@ -0,0 +1,116 @@
+package report
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/suggest"
+)
+
+// Account is one entry in the structured result of `ghmon accounts`
+type Account struct {
+	Username    string     `json:"username" yaml:"username"`
+	Type        string     `json:"type" yaml:"type"`
+	Name        string     `json:"name" yaml:"name"`
+	Bio         string     `json:"bio" yaml:"bio"`
+	AvatarURL   string     `json:"avatar_url" yaml:"avatar_url"`
+	Followers   int        `json:"followers" yaml:"followers"`
+	Following   int        `json:"following" yaml:"following"`
+	AddedAt     time.Time  `json:"added_at" yaml:"added_at"`
+	LastFetched *time.Time `json:"last_fetched" yaml:"last_fetched"`
+	Source      string     `json:"source" yaml:"source"`
+	Tags        []string   `json:"tags" yaml:"tags"`
+	MutedUntil  *time.Time `json:"muted_until" yaml:"muted_until"`
+}
+
+// BuildAccounts converts accounts, looking up each one's tags by account ID
+func BuildAccounts(accounts []account.Account, tags map[int64][]string) []Account {
+	out := make([]Account, 0, len(accounts))
+	now := time.Now()
+	for _, a := range accounts {
+		// An expired mute is left in the database until the next mute or
+		// unmute; only report ones still in effect
+		var mutedUntil *time.Time
+		if a.IsMuted(now) {
+			mutedUntil = a.MutedUntil
+		}
+		out = append(out, Account{
+			Username:    a.Username,
+			Type:        a.Type,
+			Name:        a.Name,
+			Bio:         a.Bio,
+			AvatarURL:   a.AvatarURL,
+			Followers:   a.Followers,
+			Following:   a.Following,
+			AddedAt:     a.AddedAt,
+			LastFetched: a.LastFetched,
+			Source:      a.Source,
+			Tags:        append([]string{}, tags[a.ID]...),
+			MutedUntil:  mutedUntil,
+		})
+	}
+	return out
+}
+
+// Tag is one entry in the structured result of `ghmon tag list`
+type Tag struct {
+	Tag      string `json:"tag" yaml:"tag"`
+	Accounts int    `json:"accounts" yaml:"accounts"`
+}
+
+func BuildTags(tags []account.TagCount) []Tag {
+	out := make([]Tag, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, Tag{Tag: t.Tag, Accounts: t.Accounts})
+	}
+	return out
+}
+
+// ProfileRefresh is one account in the structured result of
+// `ghmon accounts refresh`
+type ProfileRefresh struct {
+	Username string          `json:"username" yaml:"username"`
+	Changes  []ProfileChange `json:"changes" yaml:"changes"`
+	Error    string          `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type ProfileChange struct {
+	Field string `json:"field" yaml:"field"`
+	From  string `json:"from" yaml:"from"`
+	To    string `json:"to" yaml:"to"`
+}
+
+// BuildProfileRefresh converts an account's profile changes
+func BuildProfileRefresh(username string, changes []account.ProfileChange) ProfileRefresh {
+	out := ProfileRefresh{Username: username, Changes: []ProfileChange{}}
+	for _, c := range changes {
+		out.Changes = append(out.Changes, ProfileChange{Field: c.Field, From: c.From, To: c.To})
+	}
+	return out
+}
+
+// Suggestion is one entry in the structured result of `ghmon suggest`
+type Suggestion struct {
+	Username string             `json:"username" yaml:"username"`
+	Score    int                `json:"score" yaml:"score"`
+	Reasons  []SuggestionReason `json:"reasons" yaml:"reasons"`
+}
+
+type SuggestionReason struct {
+	Signal string `json:"signal" yaml:"signal"`
+	Detail string `json:"detail" yaml:"detail"`
+	Score  int    `json:"score" yaml:"score"`
+}
+
+// BuildSuggestions converts ranked suggestions
+func BuildSuggestions(suggestions []suggest.Suggestion) []Suggestion {
+	out := make([]Suggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		reasons := make([]SuggestionReason, 0, len(s.Reasons))
+		for _, r := range s.Reasons {
+			reasons = append(reasons, SuggestionReason{Signal: r.Signal, Detail: r.Detail, Score: r.Score})
+		}
+		out = append(out, Suggestion{Username: s.Username, Score: s.Score, Reasons: reasons})
+	}
+	return out
+}
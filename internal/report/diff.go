@@ -0,0 +1,154 @@
+package report
+
+import (
+	"sort"
+	"time"
+)
+
+// DigestDiff is the structured result of `ghmon digests diff`: how the
+// second digest differs from the first
+type DigestDiff struct {
+	From       DigestPeriod     `json:"from" yaml:"from"`
+	To         DigestPeriod     `json:"to" yaml:"to"`
+	Summary    SummaryDiff      `json:"summary" yaml:"summary"`
+	MostActive []ActiveChange   `json:"most_active" yaml:"most_active"`
+	Languages  []LanguageChange `json:"languages" yaml:"languages"`
+	// Trending repos that appear in only one of the digests
+	TrendingAdded   []string `json:"trending_added" yaml:"trending_added"`
+	TrendingRemoved []string `json:"trending_removed" yaml:"trending_removed"`
+}
+
+type DigestPeriod struct {
+	ID          int64     `json:"id" yaml:"id"`
+	PeriodStart time.Time `json:"period_start" yaml:"period_start"`
+	PeriodEnd   time.Time `json:"period_end" yaml:"period_end"`
+}
+
+type Change struct {
+	From  int `json:"from" yaml:"from"`
+	To    int `json:"to" yaml:"to"`
+	Delta int `json:"delta" yaml:"delta"`
+}
+
+func newChange(from, to int) Change {
+	return Change{From: from, To: to, Delta: to - from}
+}
+
+type SummaryDiff struct {
+	Accounts Change `json:"accounts" yaml:"accounts"`
+	Commits  Change `json:"commits" yaml:"commits"`
+	NewRepos Change `json:"new_repos" yaml:"new_repos"`
+	Stars    Change `json:"stars" yaml:"stars"`
+}
+
+// ActiveChange is one account's commit count in both digests
+type ActiveChange struct {
+	Username string `json:"username" yaml:"username"`
+	Change   `yaml:",inline"`
+}
+
+// LanguageChange is one language's share of activity in both digests
+type LanguageChange struct {
+	Language string  `json:"language" yaml:"language"`
+	From     float64 `json:"from" yaml:"from"`
+	To       float64 `json:"to" yaml:"to"`
+	Delta    float64 `json:"delta" yaml:"delta"`
+}
+
+// DiffDigests compares two digests. Accounts and languages are ordered by
+// the size of their change, largest first.
+func DiffDigests(from, to *Digest) *DigestDiff {
+	diff := &DigestDiff{
+		From: DigestPeriod{PeriodStart: from.PeriodStart, PeriodEnd: from.PeriodEnd},
+		To:   DigestPeriod{PeriodStart: to.PeriodStart, PeriodEnd: to.PeriodEnd},
+		Summary: SummaryDiff{
+			Accounts: newChange(from.Summary.Accounts, to.Summary.Accounts),
+			Commits:  newChange(from.Summary.Commits, to.Summary.Commits),
+			NewRepos: newChange(from.Summary.NewRepos, to.Summary.NewRepos),
+			Stars:    newChange(from.Summary.Stars, to.Summary.Stars),
+		},
+		MostActive:      []ActiveChange{},
+		Languages:       []LanguageChange{},
+		TrendingAdded:   []string{},
+		TrendingRemoved: []string{},
+	}
+
+	commits := make(map[string]*ActiveChange)
+	for _, a := range from.MostActive {
+		commits[a.Username] = &ActiveChange{Username: a.Username, Change: Change{From: a.Commits}}
+	}
+	for _, a := range to.MostActive {
+		if c, ok := commits[a.Username]; ok {
+			c.To = a.Commits
+		} else {
+			commits[a.Username] = &ActiveChange{Username: a.Username, Change: Change{To: a.Commits}}
+		}
+	}
+	for _, c := range commits {
+		c.Delta = c.To - c.From
+		diff.MostActive = append(diff.MostActive, *c)
+	}
+	sort.Slice(diff.MostActive, func(i, j int) bool {
+		a, b := diff.MostActive[i], diff.MostActive[j]
+		if abs(a.Delta) != abs(b.Delta) {
+			return abs(a.Delta) > abs(b.Delta)
+		}
+		return a.Username < b.Username
+	})
+
+	shares := make(map[string]*LanguageChange)
+	for _, l := range from.Languages {
+		shares[l.Language] = &LanguageChange{Language: l.Language, From: l.Percentage}
+	}
+	for _, l := range to.Languages {
+		if c, ok := shares[l.Language]; ok {
+			c.To = l.Percentage
+		} else {
+			shares[l.Language] = &LanguageChange{Language: l.Language, To: l.Percentage}
+		}
+	}
+	for _, c := range shares {
+		c.Delta = c.To - c.From
+		diff.Languages = append(diff.Languages, *c)
+	}
+	sort.Slice(diff.Languages, func(i, j int) bool {
+		a, b := diff.Languages[i], diff.Languages[j]
+		if absFloat(a.Delta) != absFloat(b.Delta) {
+			return absFloat(a.Delta) > absFloat(b.Delta)
+		}
+		return a.Language < b.Language
+	})
+
+	before := make(map[string]bool)
+	for _, t := range from.Trending {
+		before[t.FullName] = true
+	}
+	after := make(map[string]bool)
+	for _, t := range to.Trending {
+		after[t.FullName] = true
+		if !before[t.FullName] {
+			diff.TrendingAdded = append(diff.TrendingAdded, t.FullName)
+		}
+	}
+	for _, t := range from.Trending {
+		if !after[t.FullName] {
+			diff.TrendingRemoved = append(diff.TrendingRemoved, t.FullName)
+		}
+	}
+
+	return diff
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func absFloat(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
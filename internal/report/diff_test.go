@@ -0,0 +1,47 @@
+package report
+
+import (
+	"testing"
+)
+
+func TestDiffDigests(t *testing.T) {
+	from := &Digest{
+		Summary:    DigestSummary{Accounts: 3, Commits: 10, NewRepos: 1, Stars: 4},
+		MostActive: []ActiveAccount{{Username: "alice", Commits: 8}, {Username: "bob", Commits: 2}},
+		Languages:  []LanguageShare{{Language: "Go", Percentage: 60}, {Language: "C", Percentage: 40}},
+		Trending:   []TrendingRepo{{FullName: "golang/go"}},
+	}
+	to := &Digest{
+		Summary:    DigestSummary{Accounts: 3, Commits: 15, NewRepos: 0, Stars: 4},
+		MostActive: []ActiveAccount{{Username: "bob", Commits: 12}, {Username: "carol", Commits: 3}},
+		Languages:  []LanguageShare{{Language: "Go", Percentage: 50}, {Language: "Rust", Percentage: 50}},
+		Trending:   []TrendingRepo{{FullName: "rust-lang/rust"}},
+	}
+
+	diff := DiffDigests(from, to)
+
+	if diff.Summary.Commits != (Change{From: 10, To: 15, Delta: 5}) || diff.Summary.NewRepos.Delta != -1 {
+		t.Errorf("unexpected summary diff: %+v", diff.Summary)
+	}
+
+	if len(diff.MostActive) != 3 {
+		t.Fatalf("expected 3 accounts, got %+v", diff.MostActive)
+	}
+	if diff.MostActive[0].Username != "bob" || diff.MostActive[0].Delta != 10 {
+		t.Errorf("expected bob's change first, got %+v", diff.MostActive[0])
+	}
+	if diff.MostActive[1].Username != "alice" || diff.MostActive[1].To != 0 {
+		t.Errorf("expected alice dropping out second, got %+v", diff.MostActive[1])
+	}
+
+	if diff.Languages[0].Language != "Rust" && diff.Languages[0].Language != "C" {
+		t.Errorf("expected the largest language shift first, got %+v", diff.Languages[0])
+	}
+
+	if len(diff.TrendingAdded) != 1 || diff.TrendingAdded[0] != "rust-lang/rust" {
+		t.Errorf("unexpected trending added: %v", diff.TrendingAdded)
+	}
+	if len(diff.TrendingRemoved) != 1 || diff.TrendingRemoved[0] != "golang/go" {
+		t.Errorf("unexpected trending removed: %v", diff.TrendingRemoved)
+	}
+}
@@ -0,0 +1,288 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+// Digest is the structured result of `ghmon digest` and `ghmon export`
+type Digest struct {
+	PeriodStart time.Time `json:"period_start" yaml:"period_start"`
+	PeriodEnd   time.Time `json:"period_end" yaml:"period_end"`
+	// IncludeAutomated is set when MostActive ranks every commit rather
+	// than human commits only
+	IncludeAutomated bool            `json:"include_automated" yaml:"include_automated"`
+	Tags             []string        `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary          DigestSummary   `json:"summary" yaml:"summary"`
+	MostActive       []ActiveAccount `json:"most_active" yaml:"most_active"`
+	NewRepos         []Repo          `json:"new_repos" yaml:"new_repos"`
+	Organizations    []OrgActivity   `json:"organizations" yaml:"organizations"`
+	WatchedRepos     []WatchedRepo   `json:"watched_repos" yaml:"watched_repos"`
+	RecentStars      []StarredRepo   `json:"recent_stars" yaml:"recent_stars"`
+	Trending         []TrendingRepo  `json:"trending" yaml:"trending"`
+	Languages        []LanguageShare `json:"languages" yaml:"languages"`
+	SmartAnalysis    string          `json:"smart_analysis,omitempty" yaml:"smart_analysis,omitempty"`
+}
+
+type DigestSummary struct {
+	Accounts     int `json:"accounts" yaml:"accounts"`
+	Commits      int `json:"commits" yaml:"commits"`
+	HumanCommits int `json:"human_commits" yaml:"human_commits"`
+	NewRepos     int `json:"new_repos" yaml:"new_repos"`
+	Stars        int `json:"stars" yaml:"stars"`
+}
+
+// ActiveAccount is an account's commits in the period. Commits counts every
+// commit; HumanCommits leaves out the ones analysis.ClassifyCommit flags as
+// automated.
+type ActiveAccount struct {
+	Username     string `json:"username" yaml:"username"`
+	Commits      int    `json:"commits" yaml:"commits"`
+	HumanCommits int    `json:"human_commits" yaml:"human_commits"`
+}
+
+// Ranked returns the commit count the account is ranked by
+func (a ActiveAccount) Ranked(includeAutomated bool) int {
+	if includeAutomated {
+		return a.Commits
+	}
+	return a.HumanCommits
+}
+
+type Repo struct {
+	FullName    string    `json:"full_name" yaml:"full_name"`
+	Owner       string    `json:"owner" yaml:"owner"`
+	Description string    `json:"description" yaml:"description"`
+	Language    string    `json:"language" yaml:"language"`
+	Stars       int       `json:"stars" yaml:"stars"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// OrgActivity is a monitored organization's activity; organizations are
+// kept out of MostActive and NewRepos
+type OrgActivity struct {
+	Login    string `json:"login" yaml:"login"`
+	Commits  int    `json:"commits" yaml:"commits"`
+	NewRepos []Repo `json:"new_repos" yaml:"new_repos"`
+}
+
+// WatchedRepo is the activity in a repository watched with 'ghmon watch'
+type WatchedRepo struct {
+	FullName           string   `json:"full_name" yaml:"full_name"`
+	Description        string   `json:"description" yaml:"description"`
+	Language           string   `json:"language" yaml:"language"`
+	Stars              int      `json:"stars" yaml:"stars"`
+	StarsGained        int      `json:"stars_gained" yaml:"stars_gained"`
+	Pushes             int      `json:"pushes" yaml:"pushes"`
+	Commits            int      `json:"commits" yaml:"commits"`
+	Releases           []string `json:"releases" yaml:"releases"`
+	PullRequestsOpened int      `json:"pull_requests_opened" yaml:"pull_requests_opened"`
+	PullRequestsMerged int      `json:"pull_requests_merged" yaml:"pull_requests_merged"`
+	IssuesOpened       int      `json:"issues_opened" yaml:"issues_opened"`
+}
+
+// StarredRepo is a repository starred by one or more monitored accounts
+type StarredRepo struct {
+	FullName  string   `json:"full_name" yaml:"full_name"`
+	StarredBy []string `json:"starred_by" yaml:"starred_by"`
+}
+
+type TrendingRepo struct {
+	FullName    string   `json:"full_name" yaml:"full_name"`
+	Description string   `json:"description" yaml:"description"`
+	Language    string   `json:"language" yaml:"language"`
+	StarredBy   []string `json:"starred_by" yaml:"starred_by"`
+}
+
+type LanguageShare struct {
+	Language   string  `json:"language" yaml:"language"`
+	Count      int     `json:"count" yaml:"count"`
+	Percentage float64 `json:"percentage" yaml:"percentage"`
+}
+
+// DigestInput is the raw activity a Digest is built from
+type DigestInput struct {
+	Since        time.Time
+	End          time.Time
+	Accounts     []account.Account
+	CommitCounts map[int64]int
+	// HumanCommitCounts leaves automated commits out of CommitCounts; nil
+	// counts every commit as human
+	HumanCommitCounts map[int64]int
+	IncludeAutomated  bool
+	NewRepos          []activity.Repo
+	RecentStars       []activity.Star
+	Trending          []activity.TrendingRepo
+	Watched           []watch.Summary
+}
+
+// BuildDigest aggregates raw activity into a Digest. Lists are complete and
+// sorted; renderers decide how many entries to show.
+func BuildDigest(in DigestInput) *Digest {
+	accountMap := make(map[int64]*account.Account)
+	for i := range in.Accounts {
+		accountMap[in.Accounts[i].ID] = &in.Accounts[i]
+	}
+
+	d := &Digest{
+		PeriodStart:      in.Since,
+		PeriodEnd:        in.End,
+		IncludeAutomated: in.IncludeAutomated,
+		MostActive:       []ActiveAccount{},
+		NewRepos:         []Repo{},
+		Organizations:    []OrgActivity{},
+		WatchedRepos:     []WatchedRepo{},
+		RecentStars:      []StarredRepo{},
+		Trending:         []TrendingRepo{},
+		Languages:        []LanguageShare{},
+	}
+
+	orgs := make(map[int64]*OrgActivity)
+	org := func(acc *account.Account) *OrgActivity {
+		if orgs[acc.ID] == nil {
+			orgs[acc.ID] = &OrgActivity{Login: acc.Username, NewRepos: []Repo{}}
+		}
+		return orgs[acc.ID]
+	}
+
+	totalCommits, humanCommits := 0, 0
+	for accID, count := range in.CommitCounts {
+		human := count
+		if in.HumanCommitCounts != nil {
+			human = in.HumanCommitCounts[accID]
+		}
+		totalCommits += count
+		humanCommits += human
+
+		acc, ok := accountMap[accID]
+		a := ActiveAccount{Commits: count, HumanCommits: human}
+		switch {
+		case !ok:
+		case acc.IsOrganization():
+			org(acc).Commits = count
+		case a.Ranked(in.IncludeAutomated) > 0:
+			// Accounts with only automated commits aren't active
+			a.Username = acc.Username
+			d.MostActive = append(d.MostActive, a)
+		}
+	}
+	sort.Slice(d.MostActive, func(i, j int) bool {
+		a, b := d.MostActive[i], d.MostActive[j]
+		if a.Ranked(in.IncludeAutomated) != b.Ranked(in.IncludeAutomated) {
+			return a.Ranked(in.IncludeAutomated) > b.Ranked(in.IncludeAutomated)
+		}
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Username < b.Username
+	})
+
+	d.Summary = DigestSummary{
+		Accounts:     len(in.Accounts),
+		Commits:      totalCommits,
+		HumanCommits: humanCommits,
+		NewRepos:     len(in.NewRepos),
+		Stars:        len(in.RecentStars),
+	}
+
+	for _, r := range in.NewRepos {
+		owner := ""
+		acc, ok := accountMap[r.AccountID]
+		if ok {
+			owner = acc.Username
+		}
+		repo := Repo{
+			FullName:    r.FullName,
+			Owner:       owner,
+			Description: r.Description,
+			Language:    r.Language,
+			Stars:       r.Stars,
+			CreatedAt:   r.CreatedAt,
+		}
+		if ok && acc.IsOrganization() {
+			o := org(acc)
+			o.NewRepos = append(o.NewRepos, repo)
+			continue
+		}
+		d.NewRepos = append(d.NewRepos, repo)
+	}
+
+	for _, o := range orgs {
+		d.Organizations = append(d.Organizations, *o)
+	}
+	// Busiest first: commits plus new repos
+	sort.Slice(d.Organizations, func(i, j int) bool {
+		a, b := d.Organizations[i], d.Organizations[j]
+		if a.Commits+len(a.NewRepos) != b.Commits+len(b.NewRepos) {
+			return a.Commits+len(a.NewRepos) > b.Commits+len(b.NewRepos)
+		}
+		return a.Login < b.Login
+	})
+
+	for _, w := range in.Watched {
+		d.WatchedRepos = append(d.WatchedRepos, WatchedRepo{
+			FullName:           w.Repo.FullName,
+			Description:        w.Repo.Description,
+			Language:           w.Repo.Language,
+			Stars:              w.Repo.Stars,
+			StarsGained:        w.StarsGained,
+			Pushes:             w.Pushes,
+			Commits:            w.Commits,
+			Releases:           append([]string{}, w.Releases...),
+			PullRequestsOpened: w.PullRequestsOpened,
+			PullRequestsMerged: w.PullRequestsMerged,
+			IssuesOpened:       w.IssuesOpened,
+		})
+	}
+
+	starredBy := make(map[string][]string)
+	var order []string
+	for _, s := range in.RecentStars {
+		acc, ok := accountMap[s.AccountID]
+		if !ok {
+			continue
+		}
+		if _, seen := starredBy[s.RepoFullName]; !seen {
+			order = append(order, s.RepoFullName)
+		}
+		starredBy[s.RepoFullName] = append(starredBy[s.RepoFullName], acc.Username)
+	}
+	for _, repo := range order {
+		d.RecentStars = append(d.RecentStars, StarredRepo{FullName: repo, StarredBy: starredBy[repo]})
+	}
+	// Most-starred first; ties keep the most recent star first
+	sort.SliceStable(d.RecentStars, func(i, j int) bool {
+		return len(d.RecentStars[i].StarredBy) > len(d.RecentStars[j].StarredBy)
+	})
+
+	for _, t := range in.Trending {
+		d.Trending = append(d.Trending, TrendingRepo{
+			FullName:    t.RepoFullName,
+			Description: t.RepoDescription,
+			Language:    t.RepoLanguage,
+			StarredBy:   t.StarredBy,
+		})
+	}
+
+	var languages []string
+	for _, r := range in.NewRepos {
+		languages = append(languages, r.Language)
+	}
+	for _, s := range in.RecentStars {
+		languages = append(languages, s.RepoLanguage)
+	}
+	for _, l := range analysis.AnalyzeLanguages(languages) {
+		d.Languages = append(d.Languages, LanguageShare{
+			Language:   l.Language,
+			Count:      l.Count,
+			Percentage: l.Percentage,
+		})
+	}
+
+	return d
+}
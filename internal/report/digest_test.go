@@ -0,0 +1,151 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Now()
+	d := BuildDigest(DigestInput{
+		Since: now.AddDate(0, 0, -7),
+		End:   now,
+		Accounts: []account.Account{
+			{ID: 1, Username: "torvalds"},
+			{ID: 2, Username: "antirez"},
+		},
+		CommitCounts: map[int64]int{1: 5, 2: 7},
+		NewRepos: []activity.Repo{
+			{AccountID: 2, FullName: "antirez/kilo", Language: "C"},
+		},
+		RecentStars: []activity.Star{
+			{AccountID: 1, RepoFullName: "rust-lang/rust", RepoLanguage: "Rust"},
+			{AccountID: 1, RepoFullName: "golang/go", RepoLanguage: "Go"},
+			{AccountID: 2, RepoFullName: "golang/go", RepoLanguage: "Go"},
+		},
+	})
+
+	if d.Summary.Commits != 12 || d.Summary.Accounts != 2 || d.Summary.NewRepos != 1 || d.Summary.Stars != 3 {
+		t.Errorf("unexpected summary: %+v", d.Summary)
+	}
+	if d.MostActive[0].Username != "antirez" {
+		t.Errorf("expected antirez most active, got %s", d.MostActive[0].Username)
+	}
+	if d.NewRepos[0].Owner != "antirez" {
+		t.Errorf("expected repo owner antirez, got %s", d.NewRepos[0].Owner)
+	}
+	if d.RecentStars[0].FullName != "golang/go" || len(d.RecentStars[0].StarredBy) != 2 {
+		t.Errorf("expected golang/go starred twice first, got %+v", d.RecentStars[0])
+	}
+	if d.Languages[0].Language != "Go" {
+		t.Errorf("expected Go as top language, got %s", d.Languages[0].Language)
+	}
+	if d.Trending == nil || d.Organizations == nil || d.WatchedRepos == nil {
+		t.Error("expected empty lists to be non-nil so they encode as []")
+	}
+}
+
+func TestBuildDigestHumanCommits(t *testing.T) {
+	now := time.Now()
+	in := DigestInput{
+		Since: now.AddDate(0, 0, -7),
+		End:   now,
+		Accounts: []account.Account{
+			{ID: 1, Username: "torvalds"},
+			{ID: 2, Username: "antirez"},
+			{ID: 3, Username: "renovate[bot]"},
+		},
+		CommitCounts:      map[int64]int{1: 3, 2: 9, 3: 20},
+		HumanCommitCounts: map[int64]int{1: 3, 2: 1},
+	}
+
+	d := BuildDigest(in)
+	if d.Summary.Commits != 32 || d.Summary.HumanCommits != 4 {
+		t.Errorf("unexpected summary: %+v", d.Summary)
+	}
+	if len(d.MostActive) != 2 || d.MostActive[0].Username != "torvalds" || d.MostActive[1].Commits != 9 {
+		t.Errorf("expected torvalds ranked first and the bot left out, got %+v", d.MostActive)
+	}
+	if !strings.Contains(RenderMarkdown(d, now), "| [antirez](https://github.com/antirez) | 1 | 8 |") {
+		t.Error("expected markdown to show automated commits separately")
+	}
+
+	in.IncludeAutomated = true
+	d = BuildDigest(in)
+	if len(d.MostActive) != 3 || d.MostActive[0].Username != "renovate[bot]" || !d.IncludeAutomated {
+		t.Errorf("expected every commit to be ranked, got %+v", d.MostActive)
+	}
+}
+
+func TestBuildDigestOrganizations(t *testing.T) {
+	now := time.Now()
+	d := BuildDigest(DigestInput{
+		Since: now.AddDate(0, 0, -7),
+		End:   now,
+		Accounts: []account.Account{
+			{ID: 1, Username: "torvalds", Type: account.TypeUser},
+			{ID: 2, Username: "golang", Type: account.TypeOrganization},
+			{ID: 3, Username: "rust-lang", Type: account.TypeOrganization},
+		},
+		CommitCounts: map[int64]int{1: 5, 2: 40, 3: 1},
+		NewRepos: []activity.Repo{
+			{AccountID: 1, FullName: "torvalds/uemacs"},
+			{AccountID: 2, FullName: "golang/vuln"},
+		},
+	})
+
+	if len(d.MostActive) != 1 || d.MostActive[0].Username != "torvalds" {
+		t.Errorf("expected organizations kept out of most active, got %+v", d.MostActive)
+	}
+	if len(d.NewRepos) != 1 || d.NewRepos[0].FullName != "torvalds/uemacs" {
+		t.Errorf("expected organization repos kept out of new repos, got %+v", d.NewRepos)
+	}
+	if len(d.Organizations) != 2 || d.Organizations[0].Login != "golang" || d.Organizations[0].Commits != 40 ||
+		len(d.Organizations[0].NewRepos) != 1 || d.Organizations[1].NewRepos == nil {
+		t.Errorf("unexpected organizations: %+v", d.Organizations)
+	}
+	if d.Summary.Commits != 46 || d.Summary.NewRepos != 2 {
+		t.Errorf("expected the summary to include organizations, got %+v", d.Summary)
+	}
+	if md := RenderMarkdown(d, now); !strings.Contains(md, "### [golang](https://github.com/golang)") {
+		t.Error("expected an organizations section in the markdown")
+	}
+}
+
+func TestBuildDigestWatchedRepos(t *testing.T) {
+	now := time.Now()
+	d := BuildDigest(DigestInput{
+		Since: now.AddDate(0, 0, -7),
+		End:   now,
+		Watched: []watch.Summary{{
+			Repo:               watch.Repo{FullName: "golang/go", Stars: 120000},
+			Pushes:             2,
+			Commits:            4,
+			Releases:           []string{"go1.30"},
+			PullRequestsMerged: 3,
+			StarsGained:        100,
+		}},
+	})
+
+	if len(d.WatchedRepos) != 1 {
+		t.Fatalf("expected 1 watched repo, got %d", len(d.WatchedRepos))
+	}
+	w := d.WatchedRepos[0]
+	if w.FullName != "golang/go" || w.Commits != 4 || w.PullRequestsMerged != 3 || w.StarsGained != 100 {
+		t.Errorf("unexpected watched repo: %+v", w)
+	}
+	if d.Summary.Commits != 0 {
+		t.Errorf("expected watched repos kept out of the summary, got %+v", d.Summary)
+	}
+
+	md := RenderMarkdown(d, now)
+	if !strings.Contains(md, "## Watched Repositories") || !strings.Contains(md, "120000 (+100)") ||
+		!strings.Contains(md, "**golang/go releases:** go1.30") {
+		t.Errorf("expected a watched repositories section in the markdown, got:\n%s", md)
+	}
+}
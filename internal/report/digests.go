@@ -0,0 +1,32 @@
+package report
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/digest"
+)
+
+// SavedDigest is one entry in the structured result of `ghmon digests list`
+type SavedDigest struct {
+	ID               int64     `json:"id" yaml:"id"`
+	PeriodStart      time.Time `json:"period_start" yaml:"period_start"`
+	PeriodEnd        time.Time `json:"period_end" yaml:"period_end"`
+	Source           string    `json:"source" yaml:"source"`
+	HasSmartAnalysis bool      `json:"has_smart_analysis" yaml:"has_smart_analysis"`
+	CreatedAt        time.Time `json:"created_at" yaml:"created_at"`
+}
+
+func BuildSavedDigests(records []digest.Record) []SavedDigest {
+	out := make([]SavedDigest, 0, len(records))
+	for _, r := range records {
+		out = append(out, SavedDigest{
+			ID:               r.ID,
+			PeriodStart:      r.PeriodStart,
+			PeriodEnd:        r.PeriodEnd,
+			Source:           r.Source,
+			HasSmartAnalysis: r.SmartAnalysis != "",
+			CreatedAt:        r.CreatedAt,
+		})
+	}
+	return out
+}
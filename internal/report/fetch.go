@@ -0,0 +1,96 @@
+package report
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/fetchrun"
+)
+
+// FetchRun is the structured result of `ghmon fetch` and each entry of
+// `ghmon fetch history`
+type FetchRun struct {
+	ID             int64                `json:"id" yaml:"id"`
+	StartedAt      time.Time            `json:"started_at" yaml:"started_at"`
+	FinishedAt     *time.Time           `json:"finished_at" yaml:"finished_at"`
+	AccountsTotal  int                  `json:"accounts_total" yaml:"accounts_total"`
+	AccountsFailed int                  `json:"accounts_failed" yaml:"accounts_failed"`
+	Requests       int                  `json:"requests" yaml:"requests"`
+	ItemsInserted  int                  `json:"items_inserted" yaml:"items_inserted"`
+	Accounts       []FetchAccountResult `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	WatchedRepos   []FetchWatchedResult `json:"watched_repos,omitempty" yaml:"watched_repos,omitempty"`
+	RateLimit      *RateLimit           `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+}
+
+type FetchAccountResult struct {
+	Username   string `json:"username" yaml:"username"`
+	DurationMs int64  `json:"duration_ms" yaml:"duration_ms"`
+	Requests   int    `json:"requests" yaml:"requests"`
+	NewCommits int    `json:"new_commits" yaml:"new_commits"`
+	NewRepos   int    `json:"new_repos" yaml:"new_repos"`
+	NewStars   int    `json:"new_stars" yaml:"new_stars"`
+	// Errors maps endpoint (events, repos, stars) or "write" to its error message
+	Errors map[string]string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// FetchWatchedResult is what fetching one watched repository stored
+type FetchWatchedResult struct {
+	FullName    string `json:"full_name" yaml:"full_name"`
+	Requests    int    `json:"requests" yaml:"requests"`
+	NewEvents   int    `json:"new_events" yaml:"new_events"`
+	Stars       int    `json:"stars" yaml:"stars"`
+	StarsGained int    `json:"stars_gained" yaml:"stars_gained"`
+	// Errors maps endpoint (events, repos) or "write" to its error message
+	Errors map[string]string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Failed reports whether any endpoint or the write failed
+func (r *FetchWatchedResult) Failed() bool {
+	return len(r.Errors) > 0
+}
+
+type RateLimit struct {
+	Remaining int       `json:"remaining" yaml:"remaining"`
+	Limit     int       `json:"limit" yaml:"limit"`
+	Reset     time.Time `json:"reset" yaml:"reset"`
+}
+
+func BuildFetchRun(run *fetchrun.Run, results []fetchrun.AccountResult) *FetchRun {
+	out := &FetchRun{
+		ID:             run.ID,
+		StartedAt:      run.StartedAt,
+		FinishedAt:     run.FinishedAt,
+		AccountsTotal:  run.AccountsTotal,
+		AccountsFailed: run.AccountsFailed,
+		Requests:       run.Requests,
+		ItemsInserted:  run.Items,
+	}
+
+	for _, res := range results {
+		r := FetchAccountResult{
+			Username:   res.Username,
+			DurationMs: res.Duration.Milliseconds(),
+			Requests:   res.Requests,
+			NewCommits: res.Commits,
+			NewRepos:   res.Repos,
+			NewStars:   res.Stars,
+		}
+		if res.Failed() {
+			r.Errors = make(map[string]string)
+			if res.EventsError != "" {
+				r.Errors["events"] = res.EventsError
+			}
+			if res.ReposError != "" {
+				r.Errors["repos"] = res.ReposError
+			}
+			if res.StarsError != "" {
+				r.Errors["stars"] = res.StarsError
+			}
+			if res.WriteError != "" {
+				r.Errors["write"] = res.WriteError
+			}
+		}
+		out.Accounts = append(out.Accounts, r)
+	}
+
+	return out
+}
@@ -0,0 +1,44 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how command results are written
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case Text, JSON, YAML:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("unknown output format '%s' (expected text, json or yaml)", s)
+}
+
+// Write encodes v as JSON or YAML. Text output is rendered by each command.
+func Write(w io.Writer, format Format, v interface{}) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("format '%s' is not structured", format)
+}
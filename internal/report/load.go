@@ -0,0 +1,138 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/storage"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+// LoadDigest reads the activity for a period from store and aggregates it
+// into a digest. Most Active ranks human commits unless includeAutomated.
+func LoadDigest(store *storage.Store, since, end time.Time, includeAutomated bool) *Digest {
+	accounts, _ := store.Accounts.List()
+	commitCounts, _ := store.Commits.CountByAccount(since)
+	humanCounts, _ := store.Commits.HumanCountByAccount(since)
+	newRepos, _ := store.Repos.GetNewSince(since)
+	recentStars, _ := store.Stars.GetSince(since)
+	trendingRepos, _ := store.Stars.GetTrendingRepos(since, 2)
+
+	return BuildDigest(DigestInput{
+		Since:             since,
+		End:               end,
+		Accounts:          accounts,
+		CommitCounts:      commitCounts,
+		HumanCommitCounts: humanCounts,
+		IncludeAutomated:  includeAutomated,
+		NewRepos:          newRepos,
+		RecentStars:       recentStars,
+		Trending:          trendingRepos,
+		Watched:           loadWatched(store, since),
+	})
+}
+
+// usernames maps account IDs to usernames
+func usernames(accounts []account.Account) map[int64]string {
+	names := make(map[int64]string, len(accounts))
+	for _, a := range accounts {
+		names[a.ID] = a.Username
+	}
+	return names
+}
+
+// loadWatched summarizes the watched repositories, if store keeps any
+func loadWatched(store *storage.Store, since time.Time) []watch.Summary {
+	if store.Watched == nil {
+		return nil
+	}
+	watched, _ := store.Watched.Summarize(since, nil)
+	return watched
+}
+
+// LoadTaggedDigest is LoadDigest limited to accounts, the monitored accounts
+// carrying any of tags. Trending repos only count stars from those accounts.
+func LoadTaggedDigest(store *storage.Store, tags []string, accounts []account.Account, since, end time.Time, includeAutomated bool) *Digest {
+	allCounts, _ := store.Commits.CountByAccount(since)
+	allHuman, _ := store.Commits.HumanCountByAccount(since)
+
+	commitCounts := make(map[int64]int)
+	humanCounts := make(map[int64]int)
+	ids := make([]int64, 0, len(accounts))
+	for _, acc := range accounts {
+		ids = append(ids, acc.ID)
+		if n, ok := allCounts[acc.ID]; ok {
+			commitCounts[acc.ID] = n
+			humanCounts[acc.ID] = allHuman[acc.ID]
+		}
+	}
+	newRepos, _ := store.Repos.GetForAccounts(ids, since)
+	recentStars, _ := store.Stars.GetForAccounts(ids, since)
+
+	d := BuildDigest(DigestInput{
+		Since:             since,
+		End:               end,
+		Accounts:          accounts,
+		CommitCounts:      commitCounts,
+		HumanCommitCounts: humanCounts,
+		IncludeAutomated:  includeAutomated,
+		NewRepos:          newRepos,
+		RecentStars:       recentStars,
+		Trending:          trendingFromStars(recentStars, accounts, 2),
+		Watched:           loadWatched(store, since),
+	})
+	d.Tags = tags
+	return d
+}
+
+// trendingFromStars picks the repos starred by at least minStars of accounts,
+// like StarRepository.GetTrendingRepos does across every account
+func trendingFromStars(stars []activity.Star, accounts []account.Account, minStars int) []activity.TrendingRepo {
+	names := usernames(accounts)
+
+	byRepo := make(map[string]*activity.TrendingRepo)
+	var order []string
+	for _, s := range stars {
+		t, ok := byRepo[s.RepoFullName]
+		if !ok {
+			t = &activity.TrendingRepo{
+				RepoFullName:    s.RepoFullName,
+				RepoDescription: s.RepoDescription,
+				RepoLanguage:    s.RepoLanguage,
+			}
+			byRepo[s.RepoFullName] = t
+			order = append(order, s.RepoFullName)
+		}
+		t.StarredBy = append(t.StarredBy, names[s.AccountID])
+		t.StarCount++
+	}
+
+	var trending []activity.TrendingRepo
+	for _, name := range order {
+		if byRepo[name].StarCount >= minStars {
+			trending = append(trending, *byRepo[name])
+		}
+	}
+	sort.SliceStable(trending, func(i, j int) bool { return trending[i].StarCount > trending[j].StarCount })
+	if len(trending) > 10 {
+		trending = trending[:10]
+	}
+	return trending
+}
+
+// LoadUserActivity reads one account's activity for a period from store
+func LoadUserActivity(store *storage.Store, username string, since, end time.Time) (*UserActivity, error) {
+	acc, err := store.Accounts.Get(username)
+	if err != nil {
+		return nil, fmt.Errorf("account '%s' not found. Run 'ghmon add %s' first.", username, username)
+	}
+
+	commits, _ := store.Commits.GetForAccount(acc.ID, since)
+	repos, _ := store.Repos.GetForAccount(acc.ID, since)
+	stars, _ := store.Stars.GetForAccount(acc.ID, since)
+
+	return BuildUserActivity(acc, since, end, commits, repos, stars), nil
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/storage"
+)
+
+func seedStore(t *testing.T, now time.Time) *storage.Store {
+	store := storage.NewMemory()
+	torvalds, _ := store.Accounts.Add("torvalds", "Linus Torvalds", "", "")
+	antirez, _ := store.Accounts.Add("antirez", "", "", "")
+
+	store.Commits.Add(torvalds.ID, "torvalds/linux", "aaaaaaaaaa", "Linux 6.9", now.Add(-time.Hour))
+	store.Commits.Add(torvalds.ID, "torvalds/linux", "bbbbbbbbbb", "Merge branch", now.Add(-2*time.Hour))
+	store.Commits.Add(torvalds.ID, "torvalds/linux", "cccccccccc", "Too old", now.AddDate(0, 0, -30))
+	store.Commits.Add(antirez.ID, "antirez/kilo", "dddddddddd", "Fix", now.Add(-time.Hour))
+	store.Repos.Add(antirez.ID, "kilo", "antirez/kilo", "A text editor", "C", 10, now.Add(-time.Hour))
+	store.Stars.Add(torvalds.ID, "golang/go", "The Go language", "Go", 100, now.Add(-time.Hour))
+	store.Stars.Add(antirez.ID, "golang/go", "The Go language", "Go", 100, now.Add(-time.Hour))
+	return store
+}
+
+func TestLoadDigest(t *testing.T) {
+	now := time.Now()
+	store := seedStore(t, now)
+
+	d := LoadDigest(store, now.AddDate(0, 0, -7), now, false)
+
+	if d.Summary.Accounts != 2 || d.Summary.Commits != 3 || d.Summary.NewRepos != 1 || d.Summary.Stars != 2 {
+		t.Errorf("unexpected summary: %+v", d.Summary)
+	}
+	// The merge commit doesn't count as human, but breaks the tie
+	if d.MostActive[0].Username != "torvalds" || d.MostActive[0].Commits != 2 || d.MostActive[0].HumanCommits != 1 {
+		t.Errorf("expected torvalds most active, got %+v", d.MostActive)
+	}
+	if d.Summary.HumanCommits != 2 {
+		t.Errorf("expected 2 human commits, got %d", d.Summary.HumanCommits)
+	}
+	if len(d.Trending) != 1 || d.Trending[0].FullName != "golang/go" {
+		t.Errorf("expected golang/go trending, got %+v", d.Trending)
+	}
+
+	md := RenderMarkdown(d, now)
+	for _, want := range []string{
+		"# GitHub Activity Digest",
+		"- **Total commits:** 3 (1 automated)",
+		"| [torvalds](https://github.com/torvalds) | 1 | 1 |",
+		"- [antirez/kilo](https://github.com/antirez/kilo) - A text editor",
+		"- [golang/go](https://github.com/golang/go) - ★ by",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected markdown to contain %q", want)
+		}
+	}
+}
+
+func TestLoadTaggedDigest(t *testing.T) {
+	now := time.Now()
+	store := seedStore(t, now)
+	bellard, _ := store.Accounts.Add("bellard", "", "", "")
+	store.Stars.Add(bellard.ID, "golang/go", "The Go language", "Go", 100, now.Add(-time.Hour))
+	store.Stars.Add(bellard.ID, "qemu/qemu", "", "C", 100, now.Add(-time.Hour))
+	antirez, _ := store.Accounts.Get("antirez")
+
+	group := []account.Account{*antirez, *bellard}
+	d := LoadTaggedDigest(store, []string{"c"}, group, now.AddDate(0, 0, -7), now, false)
+
+	if d.Summary.Accounts != 2 || d.Summary.Commits != 1 || d.Summary.NewRepos != 1 || d.Summary.Stars != 3 {
+		t.Errorf("unexpected summary: %+v", d.Summary)
+	}
+	if len(d.MostActive) != 1 || d.MostActive[0].Username != "antirez" {
+		t.Errorf("expected only antirez active, got %+v", d.MostActive)
+	}
+	if len(d.Trending) != 1 || d.Trending[0].FullName != "golang/go" || len(d.Trending[0].StarredBy) != 2 {
+		t.Errorf("expected golang/go trending within the group, got %+v", d.Trending)
+	}
+	for _, s := range d.Trending[0].StarredBy {
+		if s == "torvalds" {
+			t.Errorf("expected stars outside the group to be ignored, got %v", d.Trending[0].StarredBy)
+		}
+	}
+	if !strings.Contains(RenderMarkdown(d, now), "**Tags:** c") {
+		t.Error("expected markdown to name the tags")
+	}
+}
+
+func TestLoadUserActivity(t *testing.T) {
+	now := time.Now()
+	store := seedStore(t, now)
+
+	u, err := LoadUserActivity(store, "torvalds", now.AddDate(0, 0, -7), now)
+	if err != nil {
+		t.Fatalf("failed to load user activity: %v", err)
+	}
+	if u.Name != "Linus Torvalds" || u.Summary.Commits != 2 || u.Summary.NewRepos != 0 || u.Summary.Stars != 1 {
+		t.Errorf("unexpected user activity: %+v", u.Summary)
+	}
+	if len(u.Commits) != 1 || u.Commits[0].Repo != "torvalds/linux" || u.Commits[0].Commits[0].SHA != "aaaaaaaaaa" {
+		t.Errorf("unexpected commits: %+v", u.Commits)
+	}
+
+	if _, err := LoadUserActivity(store, "nobody", now.AddDate(0, 0, -7), now); err == nil {
+		t.Error("expected error for unknown account")
+	}
+}
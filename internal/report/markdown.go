@@ -0,0 +1,158 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RenderMarkdown renders a digest as the markdown report written by
+// `ghmon export`
+func RenderMarkdown(d *Digest, generatedAt time.Time) string {
+	var sb strings.Builder
+
+	// Header
+	sb.WriteString("# GitHub Activity Digest\n\n")
+	sb.WriteString(fmt.Sprintf("**Period:** %s - %s\n\n",
+		d.PeriodStart.Format("January 2, 2006"),
+		d.PeriodEnd.Format("January 2, 2006")))
+	if len(d.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("**Tags:** %s\n\n", strings.Join(d.Tags, ", ")))
+	}
+
+	// Summary
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString(fmt.Sprintf("- **Accounts monitored:** %d\n", d.Summary.Accounts))
+	sb.WriteString(fmt.Sprintf("- **Total commits:** %d", d.Summary.Commits))
+	if automated := d.Summary.Commits - d.Summary.HumanCommits; automated > 0 {
+		sb.WriteString(fmt.Sprintf(" (%d automated)", automated))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("- **New repositories:** %d\n", d.Summary.NewRepos))
+	sb.WriteString(fmt.Sprintf("- **Stars given:** %d\n\n", d.Summary.Stars))
+
+	// Most Active
+	if len(d.MostActive) > 0 {
+		sb.WriteString("## Most Active\n\n")
+		if d.IncludeAutomated {
+			sb.WriteString("| Developer | Commits |\n")
+			sb.WriteString("|-----------|--------:|\n")
+		} else {
+			sb.WriteString("| Developer | Commits | Automated |\n")
+			sb.WriteString("|-----------|--------:|----------:|\n")
+		}
+
+		limit := 10
+		if len(d.MostActive) < limit {
+			limit = len(d.MostActive)
+		}
+		for i := 0; i < limit; i++ {
+			a := d.MostActive[i]
+			if d.IncludeAutomated {
+				sb.WriteString(fmt.Sprintf("| [%s](https://github.com/%s) | %d |\n", a.Username, a.Username, a.Commits))
+			} else {
+				sb.WriteString(fmt.Sprintf("| [%s](https://github.com/%s) | %d | %d |\n",
+					a.Username, a.Username, a.HumanCommits, a.Commits-a.HumanCommits))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// New Repositories
+	if len(d.NewRepos) > 0 {
+		sb.WriteString("## New Repositories\n\n")
+
+		limit := 10
+		if len(d.NewRepos) < limit {
+			limit = len(d.NewRepos)
+		}
+		for i := 0; i < limit; i++ {
+			repo := d.NewRepos[i]
+			desc := repo.Description
+			if desc == "" {
+				desc = "*No description*"
+			}
+			sb.WriteString(fmt.Sprintf("- [%s](https://github.com/%s) - %s\n",
+				repo.FullName, repo.FullName, desc))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Organizations
+	if len(d.Organizations) > 0 {
+		sb.WriteString("## Organizations\n\n")
+		for _, o := range d.Organizations {
+			sb.WriteString(fmt.Sprintf("### [%s](https://github.com/%s)\n\n", o.Login, o.Login))
+			sb.WriteString(fmt.Sprintf("- **Commits:** %d\n", o.Commits))
+			for _, repo := range o.NewRepos {
+				desc := repo.Description
+				if desc == "" {
+					desc = "*No description*"
+				}
+				sb.WriteString(fmt.Sprintf("- New: [%s](https://github.com/%s) - %s\n",
+					repo.FullName, repo.FullName, desc))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	// Watched Repositories
+	if len(d.WatchedRepos) > 0 {
+		sb.WriteString("## Watched Repositories\n\n")
+		sb.WriteString("| Repository | Commits | PRs opened | PRs merged | Issues opened | Stars |\n")
+		sb.WriteString("|------------|--------:|-----------:|-----------:|--------------:|------:|\n")
+		for _, w := range d.WatchedRepos {
+			sb.WriteString(fmt.Sprintf("| [%s](https://github.com/%s) | %d | %d | %d | %d | %d (%+d) |\n",
+				w.FullName, w.FullName, w.Commits, w.PullRequestsOpened, w.PullRequestsMerged,
+				w.IssuesOpened, w.Stars, w.StarsGained))
+		}
+		sb.WriteString("\n")
+
+		released := false
+		for _, w := range d.WatchedRepos {
+			if len(w.Releases) > 0 {
+				sb.WriteString(fmt.Sprintf("- **%s releases:** %s\n", w.FullName, strings.Join(w.Releases, ", ")))
+				released = true
+			}
+		}
+		if released {
+			sb.WriteString("\n")
+		}
+	}
+
+	// Trending Repos
+	if len(d.Trending) > 0 {
+		sb.WriteString("## Trending (Starred by Multiple Follows)\n\n")
+
+		for _, t := range d.Trending {
+			sb.WriteString(fmt.Sprintf("- [%s](https://github.com/%s) - ★ by %s\n",
+				t.FullName, t.FullName, strings.Join(t.StarredBy, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Languages
+	if len(d.Languages) > 0 {
+		sb.WriteString("## Languages\n\n")
+		sb.WriteString("| Language | Activity |\n")
+		sb.WriteString("|----------|--------:|\n")
+
+		for _, lang := range d.Languages {
+			sb.WriteString(fmt.Sprintf("| %s | %.0f%% |\n", lang.Language, lang.Percentage))
+		}
+		sb.WriteString("\n")
+	}
+
+	if d.SmartAnalysis != "" {
+		sb.WriteString("## Focus Areas (AI-generated)\n\n")
+		sb.WriteString(strings.TrimSpace(d.SmartAnalysis))
+		sb.WriteString("\n\n")
+	}
+
+	// Footer
+	sb.WriteString("---\n\n")
+	sb.WriteString(fmt.Sprintf("*Generated by [ghmon](https://github.com/julienpequegnot/ghmon) on %s*\n",
+		generatedAt.Format("2006-01-02 15:04")))
+
+	return sb.String()
+}
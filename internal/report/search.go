@@ -0,0 +1,34 @@
+package report
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/search"
+)
+
+// SearchHit is one entry in the structured result of `ghmon search`
+type SearchHit struct {
+	Type     string    `json:"type" yaml:"type"`
+	Username string    `json:"username" yaml:"username"`
+	Repo     string    `json:"repo" yaml:"repo"`
+	SHA      string    `json:"sha,omitempty" yaml:"sha,omitempty"`
+	Date     time.Time `json:"date" yaml:"date"`
+	// Snippet marks each matched term as **term**
+	Snippet string `json:"snippet" yaml:"snippet"`
+}
+
+func BuildSearchHits(hits []search.Hit) []SearchHit {
+	out := make([]SearchHit, 0, len(hits))
+	for i := range hits {
+		h := &hits[i]
+		out = append(out, SearchHit{
+			Type:     string(h.Kind),
+			Username: h.Username,
+			Repo:     h.Repo,
+			SHA:      h.SHA,
+			Date:     h.Date,
+			Snippet:  h.Highlight(func(s string) string { return "**" + s + "**" }),
+		})
+	}
+	return out
+}
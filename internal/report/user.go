@@ -0,0 +1,110 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+)
+
+// UserActivity is the structured result of `ghmon show`
+type UserActivity struct {
+	Username    string        `json:"username" yaml:"username"`
+	Name        string        `json:"name" yaml:"name"`
+	Bio         string        `json:"bio" yaml:"bio"`
+	PeriodStart time.Time     `json:"period_start" yaml:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end" yaml:"period_end"`
+	Summary     UserSummary   `json:"summary" yaml:"summary"`
+	Commits     []RepoCommits `json:"commits" yaml:"commits"`
+	NewRepos    []Repo        `json:"new_repos" yaml:"new_repos"`
+	Stars       []UserStar    `json:"stars" yaml:"stars"`
+}
+
+type UserSummary struct {
+	Commits  int `json:"commits" yaml:"commits"`
+	NewRepos int `json:"new_repos" yaml:"new_repos"`
+	Stars    int `json:"stars" yaml:"stars"`
+}
+
+// RepoCommits groups an account's commits by repository, newest first
+type RepoCommits struct {
+	Repo    string   `json:"repo" yaml:"repo"`
+	Commits []Commit `json:"commits" yaml:"commits"`
+}
+
+type Commit struct {
+	SHA         string    `json:"sha" yaml:"sha"`
+	Message     string    `json:"message" yaml:"message"`
+	CommittedAt time.Time `json:"committed_at" yaml:"committed_at"`
+}
+
+type UserStar struct {
+	FullName    string    `json:"full_name" yaml:"full_name"`
+	Description string    `json:"description" yaml:"description"`
+	Language    string    `json:"language" yaml:"language"`
+	Stars       int       `json:"stars" yaml:"stars"`
+	StarredAt   time.Time `json:"starred_at" yaml:"starred_at"`
+}
+
+// BuildUserActivity assembles one account's activity. commits, repos and
+// stars must already be limited to the account and period.
+func BuildUserActivity(acc *account.Account, since, end time.Time, commits []activity.Commit, repos []activity.Repo, stars []activity.Star) *UserActivity {
+	u := &UserActivity{
+		Username:    acc.Username,
+		Name:        acc.Name,
+		Bio:         acc.Bio,
+		PeriodStart: since,
+		PeriodEnd:   end,
+		Summary: UserSummary{
+			Commits:  len(commits),
+			NewRepos: len(repos),
+			Stars:    len(stars),
+		},
+		Commits:  []RepoCommits{},
+		NewRepos: []Repo{},
+		Stars:    []UserStar{},
+	}
+
+	byRepo := make(map[string]int)
+	for _, c := range commits {
+		idx, ok := byRepo[c.RepoName]
+		if !ok {
+			idx = len(u.Commits)
+			byRepo[c.RepoName] = idx
+			u.Commits = append(u.Commits, RepoCommits{Repo: c.RepoName})
+		}
+		u.Commits[idx].Commits = append(u.Commits[idx].Commits, Commit{
+			SHA:         c.SHA,
+			Message:     c.Message,
+			CommittedAt: c.CommittedAt,
+		})
+	}
+	// Busiest repositories first; ties keep the most recently active first
+	sort.SliceStable(u.Commits, func(i, j int) bool {
+		return len(u.Commits[i].Commits) > len(u.Commits[j].Commits)
+	})
+
+	for _, r := range repos {
+		u.NewRepos = append(u.NewRepos, Repo{
+			FullName:    r.FullName,
+			Owner:       acc.Username,
+			Description: r.Description,
+			Language:    r.Language,
+			Stars:       r.Stars,
+			CreatedAt:   r.CreatedAt,
+		})
+	}
+
+	for _, s := range stars {
+		u.Stars = append(u.Stars, UserStar{
+			FullName:    s.RepoFullName,
+			Description: s.RepoDescription,
+			Language:    s.RepoLanguage,
+			Stars:       s.RepoStars,
+			StarredAt:   s.StarredAt,
+		})
+	}
+
+	return u
+}
@@ -0,0 +1,33 @@
+package report
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+// Watch is one entry in the structured result of `ghmon watch` without
+// arguments
+type Watch struct {
+	FullName    string     `json:"full_name" yaml:"full_name"`
+	Description string     `json:"description" yaml:"description"`
+	Language    string     `json:"language" yaml:"language"`
+	Stars       int        `json:"stars" yaml:"stars"`
+	AddedAt     time.Time  `json:"added_at" yaml:"added_at"`
+	LastFetched *time.Time `json:"last_fetched" yaml:"last_fetched"`
+}
+
+func BuildWatches(repos []watch.Repo) []Watch {
+	out := make([]Watch, 0, len(repos))
+	for _, r := range repos {
+		out = append(out, Watch{
+			FullName:    r.FullName,
+			Description: r.Description,
+			Language:    r.Language,
+			Stars:       r.Stars,
+			AddedAt:     r.AddedAt,
+			LastFetched: r.LastFetched,
+		})
+	}
+	return out
+}
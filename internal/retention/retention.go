@@ -0,0 +1,165 @@
+package retention
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// Rule limits how long rows in one table are kept
+type Rule struct {
+	Table  string
+	MaxAge time.Duration
+}
+
+// table describes how to age out rows of one prunable table
+type table struct {
+	column string
+	// before runs ahead of the delete, in the same transaction, with the
+	// cutoff as its only argument: used to roll up or remove dependent rows
+	before []string
+}
+
+var tables = map[string]table{
+	"commits": {
+		column: "committed_at",
+		before: []string{`
+			INSERT INTO commit_rollups (account_id, repo_name, month, commits)
+			SELECT account_id, repo_name, substr(committed_at, 1, 7), COUNT(*)
+			FROM commits WHERE committed_at < ?
+			GROUP BY account_id, repo_name, substr(committed_at, 1, 7)
+			ON CONFLICT (account_id, repo_name, month) DO UPDATE SET commits = commits + excluded.commits
+		`},
+	},
+	"repos":   {column: "created_at"},
+	"stars":   {column: "starred_at"},
+	"digests": {column: "created_at"},
+	"fetch_runs": {
+		column: "started_at",
+		before: []string{`
+			DELETE FROM fetch_run_accounts
+			WHERE run_id IN (SELECT id FROM fetch_runs WHERE started_at < ?)
+		`},
+	},
+}
+
+// Tables lists the tables a retention rule may name
+func Tables() []string {
+	return []string{"commits", "repos", "stars", "digests", "fetch_runs"}
+}
+
+// TableResult is what pruning removed (or would remove) from one table
+type TableResult struct {
+	Table  string
+	Cutoff time.Time
+	Rows   int
+}
+
+// Result summarises a prune
+type Result struct {
+	Tables      []TableResult
+	DryRun      bool
+	BytesBefore int64
+	BytesAfter  int64
+}
+
+// Rows is the total number of rows pruned
+func (r *Result) Rows() int {
+	total := 0
+	for _, t := range r.Tables {
+		total += t.Rows
+	}
+	return total
+}
+
+// Reclaimed is the number of bytes the database shrank by
+func (r *Result) Reclaimed() int64 {
+	if r.BytesAfter >= r.BytesBefore {
+		return 0
+	}
+	return r.BytesBefore - r.BytesAfter
+}
+
+type Pruner struct {
+	db *database.DB
+}
+
+func NewPruner(db *database.DB) *Pruner {
+	return &Pruner{db: db}
+}
+
+// Prune deletes rows older than each rule's max age, rolling up commits into
+// monthly counts first, then vacuums the database. Pruned activity stays in
+// the daily rollups, so long-range counts still include it. Rules with a zero max age
+// are skipped. With dryRun set, it only counts what would be deleted.
+func (p *Pruner) Prune(rules []Rule, now time.Time, dryRun bool) (*Result, error) {
+	result := &Result{DryRun: dryRun}
+
+	size, err := p.db.Size()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database size: %w", err)
+	}
+	result.BytesBefore = size
+	result.BytesAfter = size
+
+	for _, rule := range rules {
+		if rule.MaxAge <= 0 {
+			continue
+		}
+		if _, ok := tables[rule.Table]; !ok {
+			return nil, fmt.Errorf("unknown retention table '%s'", rule.Table)
+		}
+		result.Tables = append(result.Tables, TableResult{Table: rule.Table, Cutoff: now.Add(-rule.MaxAge)})
+	}
+
+	if dryRun {
+		for i := range result.Tables {
+			t := &result.Tables[i]
+			err := p.db.QueryRow(
+				fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s < ?`, t.Table, tables[t.Table].column),
+				t.Cutoff,
+			).Scan(&t.Rows)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count %s: %w", t.Table, err)
+			}
+		}
+		return result, nil
+	}
+
+	err = p.db.WithTx(func(tx *database.Tx) error {
+		// Keeps the rollup delete triggers from subtracting what is pruned
+		if _, err := tx.Exec(`INSERT INTO pruning (started_at) VALUES (?)`, now); err != nil {
+			return fmt.Errorf("failed to start pruning: %w", err)
+		}
+		for i := range result.Tables {
+			t := &result.Tables[i]
+			spec := tables[t.Table]
+			for _, query := range spec.before {
+				if _, err := tx.Exec(query, t.Cutoff); err != nil {
+					return fmt.Errorf("failed to prepare %s: %w", t.Table, err)
+				}
+			}
+			res, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s < ?`, t.Table, spec.column), t.Cutoff)
+			if err != nil {
+				return fmt.Errorf("failed to prune %s: %w", t.Table, err)
+			}
+			n, _ := res.RowsAffected()
+			t.Rows = int(n)
+		}
+		_, err := tx.Exec(`DELETE FROM pruning`)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.db.Vacuum(); err != nil {
+		return nil, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if result.BytesAfter, err = p.db.Size(); err != nil {
+		return nil, fmt.Errorf("failed to read database size: %w", err)
+	}
+
+	return result, nil
+}
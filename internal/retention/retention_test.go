@@ -0,0 +1,112 @@
+package retention
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO accounts (id, username) VALUES (1, 'testuser')`); err != nil {
+		t.Fatalf("failed to create test account: %v", err)
+	}
+	return db
+}
+
+func count(t *testing.T, db *database.DB, query string) int {
+	var n int
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	return n
+}
+
+func TestPrune(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	old := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for i, at := range []time.Time{old, old.Add(time.Hour), now.Add(-time.Hour)} {
+		db.Exec(`INSERT INTO commits (account_id, repo_name, sha, message, committed_at) VALUES (1, 'a/b', ?, 'msg', ?)`,
+			string(rune('a'+i)), at)
+	}
+	db.Exec(`INSERT INTO stars (account_id, repo_full_name, starred_at) VALUES (1, 'x/old', ?), (1, 'x/new', ?)`, old, now)
+	db.Exec(`INSERT INTO fetch_runs (id, started_at) VALUES (1, ?)`, old)
+	db.Exec(`INSERT INTO fetch_run_accounts (run_id, account_id, username) VALUES (1, 1, 'testuser')`)
+
+	rules := []Rule{
+		{Table: "commits", MaxAge: 90 * 24 * time.Hour},
+		{Table: "stars", MaxAge: 90 * 24 * time.Hour},
+		{Table: "fetch_runs", MaxAge: 30 * 24 * time.Hour},
+		{Table: "repos"},
+	}
+
+	pruner := NewPruner(db)
+	result, err := pruner.Prune(rules, now, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if len(result.Tables) != 3 {
+		t.Errorf("expected rules without a max age to be skipped, got %d tables", len(result.Tables))
+	}
+	if result.Rows() != 4 {
+		t.Errorf("expected dry run to find 4 rows, got %d", result.Rows())
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM commits`); n != 3 {
+		t.Errorf("expected dry run to keep all commits, got %d", n)
+	}
+
+	result, err = pruner.Prune(rules, now, false)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if result.Rows() != 4 {
+		t.Errorf("expected 4 rows pruned, got %d", result.Rows())
+	}
+
+	if n := count(t, db, `SELECT COUNT(*) FROM commits`); n != 1 {
+		t.Errorf("expected 1 commit left, got %d", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM stars`); n != 1 {
+		t.Errorf("expected 1 star left, got %d", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM fetch_run_accounts`); n != 0 {
+		t.Errorf("expected fetch run results to be pruned with their run, got %d", n)
+	}
+	if n := count(t, db, `SELECT commits FROM commit_rollups WHERE account_id = 1 AND repo_name = 'a/b' AND month = '2024-03'`); n != 2 {
+		t.Errorf("expected pruned commits rolled up into 2024-03, got %d", n)
+	}
+	if n := count(t, db, `SELECT commits FROM daily_activity WHERE account_id = 1 AND day = '2024-03-10'`); n != 2 {
+		t.Errorf("expected pruned commits to stay in the daily rollup, got %d", n)
+	}
+	if n := count(t, db, `SELECT stars FROM daily_activity WHERE account_id = 1 AND day = '2024-03-10'`); n != 1 {
+		t.Errorf("expected pruned stars to stay in the daily rollup, got %d", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM pruning`); n != 0 {
+		t.Errorf("expected the pruning marker to be cleared, got %d", n)
+	}
+
+	// Other deletes still come off the rollups
+	db.Exec(`DELETE FROM stars`)
+	if n := count(t, db, `SELECT stars FROM daily_activity WHERE account_id = 1 AND day = '2025-06-15'`); n != 0 {
+		t.Errorf("expected a deleted star to leave the daily rollup, got %d", n)
+	}
+}
+
+func TestPruneUnknownTable(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := NewPruner(db).Prune([]Rule{{Table: "accounts", MaxAge: time.Hour}}, time.Now(), true)
+	if err == nil {
+		t.Error("expected error for unknown table")
+	}
+}
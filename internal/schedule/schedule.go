@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+)
+
+// Policy decides how often an account is fetched based on its recent activity
+type Policy struct {
+	MinInterval   time.Duration // interval for busy accounts
+	MaxInterval   time.Duration // interval for dormant accounts
+	BusyThreshold int           // items within Lookback that make an account busy
+	Lookback      time.Duration
+}
+
+// Entry is the next scheduled fetch for one account
+type Entry struct {
+	Account  account.Account
+	Activity int
+	Interval time.Duration
+	NextDue  time.Time
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MinInterval:   30 * time.Minute,
+		MaxInterval:   24 * time.Hour,
+		BusyThreshold: 20,
+		Lookback:      7 * 24 * time.Hour,
+	}
+}
+
+// Interval returns the fetch interval for an account with the given number of
+// activity items in the lookback window. Intervals scale geometrically from
+// MaxInterval for dormant accounts down to MinInterval for busy ones.
+func (p Policy) Interval(activity int) time.Duration {
+	if activity <= 0 {
+		return p.MaxInterval
+	}
+	if activity >= p.BusyThreshold {
+		return p.MinInterval
+	}
+
+	frac := float64(activity) / float64(p.BusyThreshold)
+	ratio := float64(p.MinInterval) / float64(p.MaxInterval)
+	interval := time.Duration(float64(p.MaxInterval) * math.Pow(ratio, frac))
+	return interval.Round(time.Minute)
+}
+
+// Plan computes the next due time for each account, soonest first. Accounts
+// that were never fetched are due immediately.
+func (p Policy) Plan(accounts []account.Account, activity map[int64]int) []Entry {
+	entries := make([]Entry, 0, len(accounts))
+	for _, acc := range accounts {
+		e := Entry{
+			Account:  acc,
+			Activity: activity[acc.ID],
+		}
+		e.Interval = p.Interval(e.Activity)
+		if acc.LastFetched != nil {
+			e.NextDue = acc.LastFetched.Add(e.Interval)
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].NextDue.Before(entries[j].NextDue)
+	})
+	return entries
+}
+
+// Due returns the entries whose next due time is at or before now
+func Due(entries []Entry, now time.Time) []Entry {
+	var due []Entry
+	for _, e := range entries {
+		if !e.NextDue.After(now) {
+			due = append(due, e)
+		}
+	}
+	return due
+}
+
+// NextWake returns the earliest due time among entries that are not yet due,
+// or the zero time if there are none
+func NextWake(entries []Entry, now time.Time) time.Time {
+	for _, e := range entries {
+		if e.NextDue.After(now) {
+			return e.NextDue
+		}
+	}
+	return time.Time{}
+}
+
+// Spacing returns how long to wait between account fetches so that the
+// remaining request budget lasts until the rate limit window resets, instead
+// of bursting through it at the start of the window
+func Spacing(remaining int, reset time.Time, perAccount int, now time.Time) time.Duration {
+	if reset.IsZero() || !reset.After(now) {
+		return 0
+	}
+
+	window := reset.Sub(now)
+	if remaining < perAccount {
+		return window
+	}
+
+	slots := remaining / perAccount
+	return window / time.Duration(slots)
+}
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+)
+
+func TestInterval(t *testing.T) {
+	p := DefaultPolicy()
+
+	if got := p.Interval(0); got != 24*time.Hour {
+		t.Errorf("expected dormant interval 24h, got %v", got)
+	}
+	if got := p.Interval(50); got != 30*time.Minute {
+		t.Errorf("expected busy interval 30m, got %v", got)
+	}
+
+	mid := p.Interval(10)
+	if mid <= 30*time.Minute || mid >= 24*time.Hour {
+		t.Errorf("expected interval between bounds, got %v", mid)
+	}
+	if p.Interval(5) <= mid {
+		t.Error("expected less active accounts to be fetched less often")
+	}
+}
+
+func TestPlanAndDue(t *testing.T) {
+	p := DefaultPolicy()
+	now := time.Now()
+	recent := now.Add(-10 * time.Minute)
+	old := now.Add(-2 * time.Hour)
+
+	accounts := []account.Account{
+		{ID: 1, Username: "busy-recent", LastFetched: &recent},
+		{ID: 2, Username: "busy-old", LastFetched: &old},
+		{ID: 3, Username: "dormant-old", LastFetched: &old},
+		{ID: 4, Username: "new"},
+	}
+	activity := map[int64]int{1: 40, 2: 40}
+
+	entries := p.Plan(accounts, activity)
+	if entries[0].Account.Username != "new" {
+		t.Errorf("expected never-fetched account first, got %s", entries[0].Account.Username)
+	}
+
+	due := Due(entries, now)
+	if len(due) != 2 {
+		t.Fatalf("expected 2 due accounts, got %d", len(due))
+	}
+	for _, e := range due {
+		if e.Account.Username != "new" && e.Account.Username != "busy-old" {
+			t.Errorf("unexpected due account %s", e.Account.Username)
+		}
+	}
+
+	wake := NextWake(entries, now)
+	if !wake.Equal(recent.Add(30 * time.Minute)) {
+		t.Errorf("expected next wake at busy-recent due time, got %v", wake)
+	}
+}
+
+func TestSpacing(t *testing.T) {
+	now := time.Now()
+
+	if got := Spacing(0, time.Time{}, 3, now); got != 0 {
+		t.Errorf("expected no spacing with unknown rate limit, got %v", got)
+	}
+
+	reset := now.Add(time.Hour)
+	if got := Spacing(3000, reset, 3, now); got != 3600*time.Millisecond {
+		t.Errorf("expected 3.6s spacing, got %v", got)
+	}
+	if got := Spacing(2, reset, 3, now); got != time.Hour {
+		t.Errorf("expected to wait for reset when budget exhausted, got %v", got)
+	}
+}
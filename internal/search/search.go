@@ -0,0 +1,181 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// Kind is the type of activity a hit came from
+type Kind string
+
+const (
+	KindCommit Kind = "commit"
+	KindRepo   Kind = "repo"
+	KindStar   Kind = "star"
+)
+
+// Kinds lists every searchable kind
+func Kinds() []Kind {
+	return []Kind{KindCommit, KindRepo, KindStar}
+}
+
+// ParseKind validates a --type value
+func ParseKind(s string) (Kind, error) {
+	for _, k := range Kinds() {
+		if string(k) == strings.ToLower(strings.TrimSpace(s)) {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown type '%s' (expected commit, repo or star)", s)
+}
+
+// Snippets wrap each matched term in these markers; they are control
+// characters so they never collide with the indexed text
+const (
+	MatchStart = "\x02"
+	MatchEnd   = "\x03"
+)
+
+// Query selects what to search for. Empty fields do not filter.
+type Query struct {
+	Text     string
+	Username string
+	Since    time.Time
+	Kinds    []Kind
+	Limit    int
+}
+
+// Hit is one matching commit, repository or star
+type Hit struct {
+	Kind     Kind
+	Username string
+	Repo     string
+	SHA      string
+	Date     time.Time
+	Snippet  string
+}
+
+// Highlight returns the snippet with each match passed through mark
+func (h *Hit) Highlight(mark func(string) string) string {
+	var b strings.Builder
+	rest := h.Snippet
+	for {
+		start := strings.Index(rest, MatchStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], MatchEnd)
+		if end < 0 {
+			break
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(mark(rest[start+len(MatchStart) : start+end]))
+		rest = rest[start+end+len(MatchEnd):]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// source describes how to search one kind of activity
+type source struct {
+	kind  Kind
+	query string
+}
+
+var sources = []source{
+	{KindCommit, `
+		SELECT a.username, c.repo_name, c.sha, c.committed_at,
+			snippet(commits_fts, 1, ?, ?, '…', 16)
+		FROM commits_fts
+		JOIN commits c ON c.id = commits_fts.rowid
+		JOIN accounts a ON a.id = c.account_id
+		WHERE commits_fts MATCH ? AND (? = '' OR a.username = ?) AND c.committed_at >= ?
+		ORDER BY c.committed_at DESC LIMIT ?
+	`},
+	{KindRepo, `
+		SELECT a.username, r.full_name, '', r.created_at,
+			snippet(repos_fts, -1, ?, ?, '…', 16)
+		FROM repos_fts
+		JOIN repos r ON r.id = repos_fts.rowid
+		JOIN accounts a ON a.id = r.account_id
+		WHERE repos_fts MATCH ? AND (? = '' OR a.username = ?) AND r.created_at >= ?
+		ORDER BY r.created_at DESC LIMIT ?
+	`},
+	{KindStar, `
+		SELECT a.username, s.repo_full_name, '', s.starred_at,
+			snippet(stars_fts, -1, ?, ?, '…', 16)
+		FROM stars_fts
+		JOIN stars s ON s.id = stars_fts.rowid
+		JOIN accounts a ON a.id = s.account_id
+		WHERE stars_fts MATCH ? AND (? = '' OR a.username = ?) AND s.starred_at >= ?
+		ORDER BY s.starred_at DESC LIMIT ?
+	`},
+}
+
+type Searcher struct {
+	db *database.DB
+}
+
+func NewSearcher(db *database.DB) *Searcher {
+	return &Searcher{db: db}
+}
+
+// Search returns the newest hits across the requested kinds
+func (s *Searcher) Search(q Query) ([]Hit, error) {
+	if strings.TrimSpace(q.Text) == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	if !s.db.HasFTS5() {
+		return nil, fmt.Errorf("full-text search needs SQLite with FTS5; build ghmon with -tags sqlite_fts5")
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	hits := []Hit{}
+	for _, src := range sources {
+		if len(q.Kinds) > 0 && !containsKind(q.Kinds, src.kind) {
+			continue
+		}
+
+		rows, err := s.db.Query(src.query, MatchStart, MatchEnd, q.Text, q.Username, q.Username, q.Since, limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search query '%s': %w", q.Text, err)
+		}
+		for rows.Next() {
+			h := Hit{Kind: src.kind}
+			if err := rows.Scan(&h.Username, &h.Repo, &h.SHA, &h.Date, &h.Snippet); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			hits = append(hits, h)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid search query '%s': %w", q.Text, err)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Date.After(hits[j].Date)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func containsKind(kinds []Kind, k Kind) bool {
+	for _, kind := range kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
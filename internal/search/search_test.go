@@ -0,0 +1,87 @@
+package search
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO accounts (id, username) VALUES (1, 'alice'), (2, 'bob')`); err != nil {
+		t.Fatalf("failed to create test accounts: %v", err)
+	}
+	return db
+}
+
+func TestSearch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	commits := activity.NewCommitRepository(db)
+	commits.Add(1, "alice/kernel", "a1", "Add io_uring support to the block layer", now.Add(-time.Hour))
+	commits.Add(2, "bob/web", "b1", "Fix login form", now.Add(-2*time.Hour))
+	commits.Add(2, "bob/web", "b2", "Switch server to io_uring", now.AddDate(0, -2, 0))
+	activity.NewRepoRepository(db).Add(2, "uring-bench", "bob/uring-bench", "Benchmarks for io_uring", "C", 3, now.Add(-3*time.Hour))
+	activity.NewStarRepository(db).Add(1, "axboe/liburing", "Library for io_uring", "C", 2000, now.Add(-4*time.Hour))
+
+	searcher := NewSearcher(db)
+	if !db.HasFTS5() {
+		if _, err := searcher.Search(Query{Text: "io_uring"}); err == nil || !strings.Contains(err.Error(), "sqlite_fts5") {
+			t.Errorf("expected search to need FTS5, got %v", err)
+		}
+		t.Skip("SQLite built without FTS5")
+	}
+
+	hits, err := searcher.Search(Query{Text: "io_uring"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(hits) != 4 {
+		t.Fatalf("expected 4 hits, got %d", len(hits))
+	}
+	if hits[0].Kind != KindCommit || hits[0].SHA != "a1" {
+		t.Errorf("expected newest hit first, got %+v", hits[0])
+	}
+	marked := hits[0].Highlight(func(s string) string { return "[" + s + "]" })
+	if !strings.Contains(marked, "[io_uring]") {
+		t.Errorf("expected highlighted match, got %q", marked)
+	}
+
+	hits, _ = searcher.Search(Query{Text: "io_uring", Username: "bob", Kinds: []Kind{KindCommit}})
+	if len(hits) != 1 || hits[0].SHA != "b2" {
+		t.Errorf("expected bob's commit only, got %+v", hits)
+	}
+
+	hits, _ = searcher.Search(Query{Text: "io_uring", Since: now.AddDate(0, -1, 0), Kinds: []Kind{KindCommit}})
+	if len(hits) != 1 {
+		t.Errorf("expected --since to exclude the old commit, got %d hits", len(hits))
+	}
+
+	// Deleted rows leave the index
+	db.Exec(`DELETE FROM stars`)
+	hits, _ = searcher.Search(Query{Text: "liburing"})
+	if len(hits) != 0 {
+		t.Errorf("expected deleted star to be unindexed, got %+v", hits)
+	}
+}
+
+func TestSearchInvalidQuery(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := NewSearcher(db).Search(Query{Text: "  "}); err == nil {
+		t.Error("expected error for empty query")
+	}
+	if _, err := NewSearcher(db).Search(Query{Text: `"unbalanced`}); err == nil {
+		t.Error("expected error for malformed query")
+	}
+}
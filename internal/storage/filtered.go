@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/ignore"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+// Filtered returns a view of store that leaves out the accounts and activity
+// matching m and, with hideMuted, muted accounts. Rules are applied when
+// reading, so changing them takes effect without refetching; which accounts
+// are hidden is worked out once, on the view's first read. Aggregates leave
+// hidden accounts out in the store's own queries and only fall back to
+// filtering raw rows when rules look at individual activity. Writes and
+// single account lookups go straight to store.
+func Filtered(store *Store, m *ignore.Matcher, hideMuted bool) *Store {
+	f := &view{store: store, m: m, hideMuted: hideMuted, now: time.Now}
+	filtered := &Store{
+		Accounts: &filteredAccounts{store.Accounts, f},
+		Commits:  &filteredCommits{store.Commits, f},
+		Repos:    &filteredRepos{store.Repos, f},
+		Stars:    &filteredStars{store.Stars, f},
+	}
+	if store.Watched != nil {
+		filtered.Watched = &filteredWatched{store.Watched, f}
+	}
+	return filtered
+}
+
+// view holds what the filtered stores share
+type view struct {
+	store     *Store
+	m         *ignore.Matcher
+	hideMuted bool
+	now       func() time.Time
+
+	once      sync.Once
+	hiddenIDs map[int64]bool
+}
+
+// hidden returns the IDs of the muted and ignored accounts
+func (f *view) hidden() map[int64]bool {
+	f.once.Do(func() {
+		f.hiddenIDs = make(map[int64]bool)
+		accounts, _ := f.store.Accounts.List()
+		now := f.now()
+		for _, a := range accounts {
+			if (f.hideMuted && a.IsMuted(now)) || f.m.IgnoresAuthor(a.Username) {
+				f.hiddenIDs[a.ID] = true
+			}
+		}
+	})
+	return f.hiddenIDs
+}
+
+// exclude adds the hidden accounts to the ones a caller excludes, for the
+// aggregate queries
+func (f *view) exclude(ids []int64) []int64 {
+	hidden := f.hidden()
+	out := make([]int64, 0, len(hidden)+len(ids))
+	for id := range hidden {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return append(out, ids...)
+}
+
+// excluded is exclude as a set, for filtering raw rows
+func (f *view) excluded(ids []int64) map[int64]bool {
+	set := make(map[int64]bool)
+	for _, id := range f.exclude(ids) {
+		set[id] = true
+	}
+	return set
+}
+
+// username looks an account up by ID, including accounts removed with their
+// history kept, like the SQL queries' join does
+func (f *view) username(accountID int64) string {
+	if a, err := f.store.Accounts.GetByID(accountID); err == nil {
+		return a.Username
+	}
+	return ""
+}
+
+type filteredAccounts struct {
+	Accounts
+	f *view
+}
+
+func (s *filteredAccounts) List() ([]account.Account, error) {
+	accounts, err := s.Accounts.List()
+	if err != nil {
+		return nil, err
+	}
+	hidden := s.f.hidden()
+	return filter(accounts, func(a account.Account) bool { return !hidden[a.ID] }), nil
+}
+
+type filteredCommits struct {
+	Commits
+	f *view
+}
+
+func (s *filteredCommits) keep(commits []activity.Commit, hidden map[int64]bool) []activity.Commit {
+	return filter(commits, func(c activity.Commit) bool { return !hidden[c.AccountID] && !s.f.m.IgnoresCommit(c) })
+}
+
+func (s *filteredCommits) GetForAccount(accountID int64, since time.Time) ([]activity.Commit, error) {
+	commits, err := s.Commits.GetForAccount(accountID, since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(commits, s.f.hidden()), nil
+}
+
+func (s *filteredCommits) GetAllSince(since time.Time) ([]activity.Commit, error) {
+	commits, err := s.Commits.GetAllSince(since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(commits, s.f.hidden()), nil
+}
+
+func (s *filteredCommits) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	if !s.f.m.HasCommitRules() {
+		return s.Commits.CountByAccount(since, s.f.exclude(exclude)...)
+	}
+
+	commits, err := s.Commits.GetAllSince(since)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]int)
+	for _, c := range s.keep(commits, s.f.excluded(exclude)) {
+		counts[c.AccountID]++
+	}
+	return counts, nil
+}
+
+func (s *filteredCommits) HumanCountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	if !s.f.m.HasCommitRules() {
+		return s.Commits.HumanCountByAccount(since, s.f.exclude(exclude)...)
+	}
+
+	commits, err := s.Commits.GetAllSince(since)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]int)
+	for _, c := range s.keep(commits, s.f.excluded(exclude)) {
+		if c.Automated == "" {
+			counts[c.AccountID]++
+		}
+	}
+	return counts, nil
+}
+
+func (s *filteredCommits) GetUserActivity(since time.Time, limit int, exclude ...int64) ([]activity.UserCommitActivity, error) {
+	if !s.f.m.HasCommitRules() {
+		return s.Commits.GetUserActivity(since, limit, s.f.exclude(exclude)...)
+	}
+
+	commits, err := s.Commits.GetAllSince(since)
+	if err != nil {
+		return nil, err
+	}
+	return userActivity(s.keep(commits, s.f.excluded(exclude)), s.f.username, limit), nil
+}
+
+type filteredRepos struct {
+	Repos
+	f *view
+}
+
+func (s *filteredRepos) keep(repos []activity.Repo, hidden map[int64]bool) []activity.Repo {
+	return filter(repos, func(r activity.Repo) bool { return !hidden[r.AccountID] && !s.f.m.IgnoresRepo(r) })
+}
+
+func (s *filteredRepos) GetNewSince(since time.Time) ([]activity.Repo, error) {
+	repos, err := s.Repos.GetNewSince(since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(repos, s.f.hidden()), nil
+}
+
+func (s *filteredRepos) GetForAccount(accountID int64, since time.Time) ([]activity.Repo, error) {
+	repos, err := s.Repos.GetForAccount(accountID, since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(repos, s.f.hidden()), nil
+}
+
+func (s *filteredRepos) GetForAccounts(accountIDs []int64, since time.Time) ([]activity.Repo, error) {
+	repos, err := s.Repos.GetForAccounts(accountIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(repos, s.f.hidden()), nil
+}
+
+func (s *filteredRepos) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	if !s.f.m.HasRepoRules() {
+		return s.Repos.CountByAccount(since, s.f.exclude(exclude)...)
+	}
+
+	repos, err := s.Repos.GetNewSince(since)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]int)
+	for _, r := range s.keep(repos, s.f.excluded(exclude)) {
+		counts[r.AccountID]++
+	}
+	return counts, nil
+}
+
+type filteredStars struct {
+	Stars
+	f *view
+}
+
+func (s *filteredStars) keep(stars []activity.Star, hidden map[int64]bool) []activity.Star {
+	return filter(stars, func(st activity.Star) bool { return !hidden[st.AccountID] && !s.f.m.IgnoresStar(st) })
+}
+
+func (s *filteredStars) GetSince(since time.Time) ([]activity.Star, error) {
+	stars, err := s.Stars.GetSince(since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(stars, s.f.hidden()), nil
+}
+
+func (s *filteredStars) GetForAccount(accountID int64, since time.Time) ([]activity.Star, error) {
+	stars, err := s.Stars.GetForAccount(accountID, since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(stars, s.f.hidden()), nil
+}
+
+func (s *filteredStars) GetForAccounts(accountIDs []int64, since time.Time) ([]activity.Star, error) {
+	stars, err := s.Stars.GetForAccounts(accountIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	return s.keep(stars, s.f.hidden()), nil
+}
+
+func (s *filteredStars) GetTrendingRepos(since time.Time, minStars int, exclude ...int64) ([]activity.TrendingRepo, error) {
+	if !s.f.m.HasStarRules() {
+		return s.Stars.GetTrendingRepos(since, minStars, s.f.exclude(exclude)...)
+	}
+
+	stars, err := s.Stars.GetSince(since)
+	if err != nil {
+		return nil, err
+	}
+	return trendingRepos(s.keep(stars, s.f.excluded(exclude)), s.f.username, minStars), nil
+}
+
+func (s *filteredStars) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	if !s.f.m.HasStarRules() {
+		return s.Stars.CountByAccount(since, s.f.exclude(exclude)...)
+	}
+
+	stars, err := s.Stars.GetSince(since)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]int)
+	for _, st := range s.keep(stars, s.f.excluded(exclude)) {
+		counts[st.AccountID]++
+	}
+	return counts, nil
+}
+
+type filteredWatched struct {
+	Watched
+	f *view
+}
+
+func (s *filteredWatched) Summarize(since time.Time, keep func(watch.Event) bool) ([]watch.Summary, error) {
+	summaries, err := s.Watched.Summarize(since, func(e watch.Event) bool {
+		return !s.f.m.IgnoresWatchedEvent(e) && (keep == nil || keep(e))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filter(summaries, func(sum watch.Summary) bool { return !s.f.m.IgnoresWatchedRepo(sum.Repo) }), nil
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+	"github.com/julienpequegnot/ghmon/internal/config"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/ignore"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+func TestFiltered(t *testing.T) {
+	m, err := ignore.Compile(config.IgnoreConfig{
+		Repos:     []string{"dotfiles"},
+		Messages:  []string{"^Merge branch"},
+		Authors:   []string{"*[bot]"},
+		Languages: []string{"Jupyter Notebook"},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile rules: %v", err)
+	}
+
+	for name, raw := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			alice, _ := raw.Accounts.Add("alice", "", "", "")
+			bob, _ := raw.Accounts.Add("bob", "", "", "")
+			bot, _ := raw.Accounts.Add("renovate[bot]", "", "", "")
+
+			now := time.Now()
+			raw.Commits.Add(alice.ID, "alice/app", "a1", "Add feature", now)
+			raw.Commits.Add(alice.ID, "alice/dotfiles", "a2", "Tweak vimrc", now)
+			raw.Commits.Add(alice.ID, "alice/app", "a3", "Merge branch 'main'", now)
+			raw.Commits.Add(bob.ID, "bob/app", "b1", "Fix bug", now)
+			raw.Commits.Add(bot.ID, "alice/app", "r1", "Update deps", now)
+
+			raw.Repos.Add(alice.ID, "notebooks", "alice/notebooks", "", "Jupyter Notebook", 0, now)
+			raw.Repos.Add(bob.ID, "tool", "bob/tool", "", "Go", 0, now)
+
+			raw.Stars.Add(alice.ID, "golang/go", "", "Go", 0, now)
+			raw.Stars.Add(bob.ID, "golang/go", "", "Go", 0, now)
+			raw.Stars.Add(bot.ID, "golang/go", "", "Go", 0, now)
+			raw.Stars.Add(bob.ID, "rsc/dotfiles", "", "", 0, now)
+
+			store := Filtered(raw, m, true)
+			since := now.Add(-time.Hour)
+
+			accounts, _ := store.Accounts.List()
+			if len(accounts) != 2 {
+				t.Errorf("expected the bot to be left out, got %+v", accounts)
+			}
+			if _, err := store.Accounts.Get("renovate[bot]"); err != nil {
+				t.Error("expected single account lookups to pass through")
+			}
+
+			counts, _ := store.Commits.CountByAccount(since)
+			if counts[alice.ID] != 1 || counts[bob.ID] != 1 || counts[bot.ID] != 0 {
+				t.Errorf("unexpected commit counts: %v", counts)
+			}
+			if human, _ := store.Commits.HumanCountByAccount(since); human[alice.ID] != 1 || human[bob.ID] != 1 || human[bot.ID] != 0 {
+				t.Errorf("unexpected human commit counts: %v", human)
+			}
+			ua, _ := store.Commits.GetUserActivity(since, 10)
+			if len(ua) != 2 {
+				t.Errorf("expected activity for alice and bob, got %+v", ua)
+			}
+			commits, _ := store.Commits.GetForAccount(alice.ID, since)
+			if len(commits) != 1 || commits[0].SHA != "a1" {
+				t.Errorf("unexpected commits for alice: %+v", commits)
+			}
+
+			repos, _ := store.Repos.GetNewSince(since)
+			if len(repos) != 1 || repos[0].FullName != "bob/tool" {
+				t.Errorf("expected the notebook repo to be left out, got %+v", repos)
+			}
+
+			stars, _ := store.Stars.GetSince(since)
+			if len(stars) != 2 {
+				t.Errorf("expected 2 stars, got %+v", stars)
+			}
+			trending, _ := store.Stars.GetTrendingRepos(since, 2)
+			if len(trending) != 1 || trending[0].StarCount != 2 {
+				t.Errorf("expected golang/go trending with 2 stars, got %+v", trending)
+			}
+
+			// Callers' exclusions add to the hidden accounts
+			if counts, _ := store.Commits.CountByAccount(since, bob.ID); counts[bob.ID] != 0 || counts[alice.ID] != 1 {
+				t.Errorf("expected bob excluded too, got %v", counts)
+			}
+
+			// Only author rules: the hidden accounts are left out by the
+			// store's own aggregates
+			authors, _ := ignore.Compile(config.IgnoreConfig{Authors: []string{"*[bot]"}})
+			byAuthor := Filtered(raw, authors, true)
+			if counts, _ := byAuthor.Commits.CountByAccount(since); counts[alice.ID] != 3 || counts[bot.ID] != 0 {
+				t.Errorf("expected the bot's commits left out, got %v", counts)
+			}
+			if trending, _ := byAuthor.Stars.GetTrendingRepos(since, 2); len(trending) != 1 || trending[0].StarCount != 2 {
+				t.Errorf("expected golang/go trending without the bot, got %+v", trending)
+			}
+			if counts, _ := byAuthor.Repos.CountByAccount(since); counts[alice.ID] != 1 || counts[bob.ID] != 1 {
+				t.Errorf("unexpected repo counts: %v", counts)
+			}
+
+			// Nothing to filter: the raw store answers directly
+			all := Filtered(raw, nil, true)
+			if counts, _ := all.Commits.CountByAccount(since); counts[alice.ID] != 3 || counts[bot.ID] != 1 {
+				t.Errorf("expected unfiltered counts, got %v", counts)
+			}
+		})
+	}
+}
+
+func TestFilteredMutes(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	raw := NewSQLite(db)
+	alice, _ := raw.Accounts.Add("alice", "", "", "")
+	bob, _ := raw.Accounts.Add("bob", "", "", "")
+	now := time.Now()
+	raw.Commits.Add(alice.ID, "alice/app", "a1", "Add feature", now)
+	raw.Commits.Add(bob.ID, "bob/app", "b1", "Fix bug", now)
+
+	account.NewRepository(db).Mute(alice.ID, now.Add(24*time.Hour))
+
+	store := Filtered(raw, nil, true)
+	counts, _ := store.Commits.CountByAccount(now.Add(-time.Hour))
+	if counts[alice.ID] != 0 || counts[bob.ID] != 1 {
+		t.Errorf("expected the muted account to be left out, got %v", counts)
+	}
+	if counts, _ := Filtered(raw, nil, false).Commits.CountByAccount(now.Add(-time.Hour)); counts[alice.ID] != 1 {
+		t.Errorf("expected mutes to be kept when not hiding muted accounts, got %v", counts)
+	}
+
+	// Mutes expire on their own
+	later := Filtered(raw, nil, true)
+	later.Commits.(*filteredCommits).f.now = func() time.Time { return now.Add(48 * time.Hour) }
+	counts, _ = later.Commits.CountByAccount(now.Add(-time.Hour))
+	if counts[alice.ID] != 1 {
+		t.Errorf("expected an expired mute to be ignored, got %v", counts)
+	}
+}
+
+func TestFilteredAuthorsAndWatched(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	raw := NewSQLite(db)
+	alice, _ := raw.Accounts.Add("alice", "", "", "")
+	now := time.Now()
+	commits := activity.NewCommitRepository(db)
+	commits.AddAuthored(alice.ID, "alice/app", "a1", "Add feature", analysis.CommitAuthor{Name: "Alice", Email: "alice@example.com"}, now)
+	commits.AddAuthored(alice.ID, "alice/app", "a2", "Bump deps", analysis.CommitAuthor{Name: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com"}, now)
+
+	watched := watch.NewRepository(db)
+	goRepo, _ := watched.Add("golang/go", "", "Go", 0)
+	dotfiles, _ := watched.Add("rsc/dotfiles", "", "", 0)
+	watched.AddEvent(goRepo.ID, watch.Event{EventID: "1", Type: watch.EventPullRequest, Action: "opened", Actor: "rsc", CreatedAt: now})
+	watched.AddEvent(goRepo.ID, watch.Event{EventID: "2", Type: watch.EventPullRequest, Action: "opened", Actor: "dependabot[bot]", CreatedAt: now})
+	watched.AddEvent(dotfiles.ID, watch.Event{EventID: "3", Type: watch.EventPush, Commits: 1, Actor: "rsc", CreatedAt: now})
+
+	m, _ := ignore.Compile(config.IgnoreConfig{Repos: []string{"dotfiles"}, Authors: []string{"dependabot[bot]"}})
+	store := Filtered(raw, m, true)
+	since := now.Add(-time.Hour)
+
+	// The bot's commit was pushed by alice, so it is matched by git author
+	if counts, _ := store.Commits.CountByAccount(since); counts[alice.ID] != 1 {
+		t.Errorf("expected the bot-authored commit left out, got %v", counts)
+	}
+
+	summaries, err := store.Watched.Summarize(since, nil)
+	if err != nil {
+		t.Fatalf("failed to summarize watched repos: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Repo.FullName != "golang/go" || summaries[0].PullRequestsOpened != 1 {
+		t.Errorf("expected the ignored repo and the bot's pull request left out, got %+v", summaries)
+	}
+}
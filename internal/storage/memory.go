@@ -0,0 +1,471 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+)
+
+// memory holds every table of an in-memory store behind one lock, so the
+// per-table views can join across them like the SQL queries do
+type memory struct {
+	mu       sync.Mutex
+	nextID   int64
+	accounts []account.Account
+	commits  []activity.Commit
+	repos    []activity.Repo
+	stars    []activity.Star
+}
+
+// NewMemory returns an empty store that keeps everything in memory. It
+// mirrors the SQLite store's semantics (ordering, duplicate handling) and is
+// meant for tests.
+func NewMemory() *Store {
+	m := &memory{}
+	return &Store{
+		Accounts: &memoryAccounts{m},
+		Commits:  &memoryCommits{m},
+		Repos:    &memoryRepos{m},
+		Stars:    &memoryStars{m},
+	}
+}
+
+func (m *memory) id() int64 {
+	m.nextID++
+	return m.nextID
+}
+
+func (m *memory) username(accountID int64) (string, bool) {
+	for _, a := range m.accounts {
+		if a.ID == accountID {
+			return a.Username, true
+		}
+	}
+	return "", false
+}
+
+// usernameOf is username without the found flag, for the shared helpers
+func (m *memory) usernameOf(accountID int64) string {
+	username, _ := m.username(accountID)
+	return username
+}
+
+type memoryAccounts struct{ m *memory }
+
+func (s *memoryAccounts) Add(username, name, avatarURL, bio string) (*account.Account, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	for _, a := range s.m.accounts {
+		if a.Username == username {
+			return nil, fmt.Errorf("account already exists or error: %s", username)
+		}
+	}
+	a := account.Account{
+		ID:        s.m.id(),
+		Username:  username,
+		Name:      name,
+		AvatarURL: avatarURL,
+		Bio:       bio,
+		AddedAt:   time.Now(),
+		Type:      account.TypeUser,
+		Source:    account.SourceManual,
+	}
+	s.m.accounts = append(s.m.accounts, a)
+	return &account.Account{ID: a.ID, Username: username, Name: name, Type: a.Type, Source: a.Source}, nil
+}
+
+func (s *memoryAccounts) Remove(username string) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	idx := -1
+	for i, a := range s.m.accounts {
+		if a.Username == username {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("account not found: %w", sql.ErrNoRows)
+	}
+	id := s.m.accounts[idx].ID
+	s.m.accounts = append(s.m.accounts[:idx], s.m.accounts[idx+1:]...)
+
+	s.m.commits = filter(s.m.commits, func(c activity.Commit) bool { return c.AccountID != id })
+	s.m.repos = filter(s.m.repos, func(r activity.Repo) bool { return r.AccountID != id })
+	s.m.stars = filter(s.m.stars, func(st activity.Star) bool { return st.AccountID != id })
+	return nil
+}
+
+func (s *memoryAccounts) List() ([]account.Account, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	accounts := append([]account.Account(nil), s.m.accounts...)
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Username < accounts[j].Username })
+	return accounts, nil
+}
+
+func (s *memoryAccounts) ListByStaleness() ([]account.Account, error) {
+	accounts, _ := s.List()
+	sort.SliceStable(accounts, func(i, j int) bool {
+		a, b := accounts[i].LastFetched, accounts[j].LastFetched
+		switch {
+		case a == nil || b == nil:
+			return a == nil && b != nil
+		default:
+			return a.Before(*b)
+		}
+	})
+	return accounts, nil
+}
+
+func (s *memoryAccounts) Get(username string) (*account.Account, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	for _, a := range s.m.accounts {
+		if a.Username == username {
+			return &a, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *memoryAccounts) GetByID(id int64) (*account.Account, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	for _, a := range s.m.accounts {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *memoryAccounts) UpdateLastFetched(id int64) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	now := time.Now()
+	for i := range s.m.accounts {
+		if s.m.accounts[i].ID == id {
+			s.m.accounts[i].LastFetched = &now
+		}
+	}
+	return nil
+}
+
+func (s *memoryAccounts) Exists(username string) bool {
+	_, err := s.Get(username)
+	return err == nil
+}
+
+func (s *memoryAccounts) Count() int {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	return len(s.m.accounts)
+}
+
+type memoryCommits struct{ m *memory }
+
+func (s *memoryCommits) Add(accountID int64, repoName, sha, message string, committedAt time.Time) (bool, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.username(accountID); !ok {
+		return false, fmt.Errorf("FOREIGN KEY constraint failed")
+	}
+	for _, c := range s.m.commits {
+		if c.AccountID == accountID && c.SHA == sha {
+			return false, nil
+		}
+	}
+	s.m.commits = append(s.m.commits, activity.Commit{
+		ID:          s.m.id(),
+		AccountID:   accountID,
+		RepoName:    repoName,
+		SHA:         sha,
+		Message:     message,
+		CommittedAt: committedAt,
+		Automated:   analysis.ClassifyCommit(analysis.CommitAuthor{}, message),
+	})
+	return true, nil
+}
+
+func (s *memoryCommits) since(since time.Time, keep func(activity.Commit) bool) []activity.Commit {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	var out []activity.Commit
+	for _, c := range s.m.commits {
+		if !c.CommittedAt.Before(since) && keep(c) {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CommittedAt.After(out[j].CommittedAt) })
+	return out
+}
+
+func (s *memoryCommits) GetForAccount(accountID int64, since time.Time) ([]activity.Commit, error) {
+	return s.since(since, func(c activity.Commit) bool { return c.AccountID == accountID }), nil
+}
+
+func (s *memoryCommits) GetAllSince(since time.Time) ([]activity.Commit, error) {
+	return s.since(since, func(activity.Commit) bool { return true }), nil
+}
+
+func (s *memoryCommits) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	for _, c := range s.since(since, func(c activity.Commit) bool { return !hasID(exclude, c.AccountID) }) {
+		counts[c.AccountID]++
+	}
+	return counts, nil
+}
+
+func (s *memoryCommits) HumanCountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	for _, c := range s.since(since, func(c activity.Commit) bool { return c.Automated == "" && !hasID(exclude, c.AccountID) }) {
+		counts[c.AccountID]++
+	}
+	return counts, nil
+}
+
+func (s *memoryCommits) GetUserActivity(since time.Time, limit int, exclude ...int64) ([]activity.UserCommitActivity, error) {
+	commits := s.since(since, func(c activity.Commit) bool { return !hasID(exclude, c.AccountID) })
+
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return userActivity(commits, s.m.usernameOf, limit), nil
+}
+
+// userActivity groups commits, newest first, by account like
+// CommitRepository.GetUserActivity
+func userActivity(commits []activity.Commit, username func(int64) string, limit int) []activity.UserCommitActivity {
+	byAccount := make(map[int64]*activity.UserCommitActivity)
+	var order []int64
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		ua, ok := byAccount[c.AccountID]
+		if !ok {
+			ua = &activity.UserCommitActivity{AccountID: c.AccountID, Username: username(c.AccountID)}
+			byAccount[c.AccountID] = ua
+			order = append(order, c.AccountID)
+		}
+		ua.Count++
+		if !contains(ua.Repos, c.RepoName) {
+			ua.Repos = append(ua.Repos, c.RepoName)
+		}
+	}
+
+	var activities []activity.UserCommitActivity
+	for _, id := range order {
+		activities = append(activities, *byAccount[id])
+	}
+	sort.SliceStable(activities, func(i, j int) bool { return activities[i].Count > activities[j].Count })
+	if limit >= 0 && len(activities) > limit {
+		activities = activities[:limit]
+	}
+	return activities
+}
+
+type memoryRepos struct{ m *memory }
+
+func (s *memoryRepos) Add(accountID int64, name, fullName, description, language string, stars int, createdAt time.Time) (bool, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.username(accountID); !ok {
+		return false, fmt.Errorf("FOREIGN KEY constraint failed")
+	}
+	for _, r := range s.m.repos {
+		if r.AccountID == accountID && r.FullName == fullName {
+			return false, nil
+		}
+	}
+	s.m.repos = append(s.m.repos, activity.Repo{
+		ID:          s.m.id(),
+		AccountID:   accountID,
+		Name:        name,
+		FullName:    fullName,
+		Description: description,
+		Language:    language,
+		Stars:       stars,
+		CreatedAt:   createdAt,
+	})
+	return true, nil
+}
+
+func (s *memoryRepos) GetNewSince(since time.Time) ([]activity.Repo, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	var out []activity.Repo
+	for _, r := range s.m.repos {
+		if !r.CreatedAt.Before(since) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *memoryRepos) GetForAccount(accountID int64, since time.Time) ([]activity.Repo, error) {
+	repos, _ := s.GetNewSince(since)
+	return filter(repos, func(r activity.Repo) bool { return r.AccountID == accountID }), nil
+}
+
+func (s *memoryRepos) GetForAccounts(accountIDs []int64, since time.Time) ([]activity.Repo, error) {
+	repos, _ := s.GetNewSince(since)
+	return filter(repos, func(r activity.Repo) bool { return hasID(accountIDs, r.AccountID) }), nil
+}
+
+func (s *memoryRepos) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	repos, _ := s.GetNewSince(since)
+	counts := make(map[int64]int)
+	for _, r := range filter(repos, func(r activity.Repo) bool { return !hasID(exclude, r.AccountID) }) {
+		counts[r.AccountID]++
+	}
+	return counts, nil
+}
+
+type memoryStars struct{ m *memory }
+
+func (s *memoryStars) Add(accountID int64, repoFullName, description, language string, stars int, starredAt time.Time) (bool, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.username(accountID); !ok {
+		return false, fmt.Errorf("FOREIGN KEY constraint failed")
+	}
+	for _, st := range s.m.stars {
+		if st.AccountID == accountID && st.RepoFullName == repoFullName {
+			return false, nil
+		}
+	}
+	s.m.stars = append(s.m.stars, activity.Star{
+		ID:              s.m.id(),
+		AccountID:       accountID,
+		RepoFullName:    repoFullName,
+		RepoDescription: description,
+		RepoLanguage:    language,
+		RepoStars:       stars,
+		StarredAt:       starredAt,
+	})
+	return true, nil
+}
+
+func (s *memoryStars) GetSince(since time.Time) ([]activity.Star, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	var out []activity.Star
+	for _, st := range s.m.stars {
+		if !st.StarredAt.Before(since) {
+			out = append(out, st)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StarredAt.After(out[j].StarredAt) })
+	return out, nil
+}
+
+func (s *memoryStars) GetForAccount(accountID int64, since time.Time) ([]activity.Star, error) {
+	stars, _ := s.GetSince(since)
+	return filter(stars, func(st activity.Star) bool { return st.AccountID == accountID }), nil
+}
+
+func (s *memoryStars) GetForAccounts(accountIDs []int64, since time.Time) ([]activity.Star, error) {
+	stars, _ := s.GetSince(since)
+	return filter(stars, func(st activity.Star) bool { return hasID(accountIDs, st.AccountID) }), nil
+}
+
+func (s *memoryStars) GetTrendingRepos(since time.Time, minStars int, exclude ...int64) ([]activity.TrendingRepo, error) {
+	stars, _ := s.GetSince(since)
+	stars = filter(stars, func(st activity.Star) bool { return !hasID(exclude, st.AccountID) })
+
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return trendingRepos(stars, s.m.usernameOf, minStars), nil
+}
+
+// trendingRepos picks the repos starred by at least minStars accounts like
+// StarRepository.GetTrendingRepos
+func trendingRepos(stars []activity.Star, username func(int64) string, minStars int) []activity.TrendingRepo {
+	byRepo := make(map[string]*activity.TrendingRepo)
+	var order []string
+	for _, st := range stars {
+		t, ok := byRepo[st.RepoFullName]
+		if !ok {
+			t = &activity.TrendingRepo{
+				RepoFullName:    st.RepoFullName,
+				RepoDescription: st.RepoDescription,
+				RepoLanguage:    st.RepoLanguage,
+			}
+			byRepo[st.RepoFullName] = t
+			order = append(order, st.RepoFullName)
+		}
+		name := username(st.AccountID)
+		if !contains(t.StarredBy, name) {
+			t.StarredBy = append(t.StarredBy, name)
+			t.StarCount++
+		}
+	}
+
+	var trending []activity.TrendingRepo
+	for _, name := range order {
+		if byRepo[name].StarCount >= minStars {
+			trending = append(trending, *byRepo[name])
+		}
+	}
+	sort.SliceStable(trending, func(i, j int) bool { return trending[i].StarCount > trending[j].StarCount })
+	if len(trending) > 10 {
+		trending = trending[:10]
+	}
+	return trending
+}
+
+func (s *memoryStars) CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error) {
+	stars, _ := s.GetSince(since)
+	counts := make(map[int64]int)
+	for _, st := range filter(stars, func(st activity.Star) bool { return !hasID(exclude, st.AccountID) }) {
+		counts[st.AccountID]++
+	}
+	return counts, nil
+}
+
+func filter[T any](items []T, keep func(T) bool) []T {
+	out := items[:0]
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func hasID(ids []int64, id int64) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
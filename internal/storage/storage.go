@@ -0,0 +1,83 @@
+// Package storage defines the interfaces command logic uses to read and write
+// accounts and activity, so it can run against SQLite or an in-memory store.
+package storage
+
+import (
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/watch"
+)
+
+// Accounts stores monitored accounts
+type Accounts interface {
+	Add(username, name, avatarURL, bio string) (*account.Account, error)
+	Remove(username string) error
+	List() ([]account.Account, error)
+	ListByStaleness() ([]account.Account, error)
+	Get(username string) (*account.Account, error)
+	GetByID(id int64) (*account.Account, error)
+	UpdateLastFetched(id int64) error
+	Exists(username string) bool
+	Count() int
+}
+
+// Commits stores commits by monitored accounts. The aggregates leave out the
+// accounts in exclude.
+type Commits interface {
+	Add(accountID int64, repoName, sha, message string, committedAt time.Time) (bool, error)
+	GetForAccount(accountID int64, since time.Time) ([]activity.Commit, error)
+	GetAllSince(since time.Time) ([]activity.Commit, error)
+	CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error)
+	HumanCountByAccount(since time.Time, exclude ...int64) (map[int64]int, error)
+	GetUserActivity(since time.Time, limit int, exclude ...int64) ([]activity.UserCommitActivity, error)
+}
+
+// Repos stores repositories created by monitored accounts. CountByAccount
+// leaves out the accounts in exclude.
+type Repos interface {
+	Add(accountID int64, name, fullName, description, language string, stars int, createdAt time.Time) (bool, error)
+	GetNewSince(since time.Time) ([]activity.Repo, error)
+	GetForAccount(accountID int64, since time.Time) ([]activity.Repo, error)
+	GetForAccounts(accountIDs []int64, since time.Time) ([]activity.Repo, error)
+	CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error)
+}
+
+// Stars stores repositories starred by monitored accounts. The aggregates
+// leave out the accounts in exclude.
+type Stars interface {
+	Add(accountID int64, repoFullName, description, language string, stars int, starredAt time.Time) (bool, error)
+	GetSince(since time.Time) ([]activity.Star, error)
+	GetForAccount(accountID int64, since time.Time) ([]activity.Star, error)
+	GetForAccounts(accountIDs []int64, since time.Time) ([]activity.Star, error)
+	GetTrendingRepos(since time.Time, minStars int, exclude ...int64) ([]activity.TrendingRepo, error)
+	CountByAccount(since time.Time, exclude ...int64) (map[int64]int, error)
+}
+
+// Watched reports on repositories watched with 'ghmon watch'
+type Watched interface {
+	Summarize(since time.Time, keep func(watch.Event) bool) ([]watch.Summary, error)
+}
+
+// Store bundles the account and activity stores. Watched is nil for stores
+// that don't keep watched repositories.
+type Store struct {
+	Accounts Accounts
+	Commits  Commits
+	Repos    Repos
+	Stars    Stars
+	Watched  Watched
+}
+
+// NewSQLite returns a store backed by the SQLite repositories
+func NewSQLite(db *database.DB) *Store {
+	return &Store{
+		Accounts: account.NewRepository(db),
+		Commits:  activity.NewCommitRepository(db),
+		Repos:    activity.NewRepoRepository(db),
+		Stars:    activity.NewStarRepository(db),
+		Watched:  watch.NewRepository(db),
+	}
+}
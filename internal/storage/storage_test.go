@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+)
+
+// stores runs each test against both implementations so the in-memory store
+// keeps matching SQLite
+func stores(t *testing.T) map[string]*Store {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return map[string]*Store{
+		"sqlite": NewSQLite(db),
+		"memory": NewMemory(),
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Accounts.Add("zed", "Zed", "", ""); err != nil {
+				t.Fatalf("failed to add account: %v", err)
+			}
+			alice, _ := store.Accounts.Add("alice", "Alice", "", "")
+			if _, err := store.Accounts.Add("alice", "", "", ""); err == nil {
+				t.Error("expected duplicate account to fail")
+			}
+
+			accounts, _ := store.Accounts.List()
+			if len(accounts) != 2 || accounts[0].Username != "alice" {
+				t.Errorf("expected accounts ordered by username, got %+v", accounts)
+			}
+
+			store.Accounts.UpdateLastFetched(alice.ID)
+			stale, _ := store.Accounts.ListByStaleness()
+			if stale[0].Username != "zed" {
+				t.Errorf("expected never-fetched account first, got %s", stale[0].Username)
+			}
+
+			if _, err := store.Accounts.Get("nobody"); err == nil {
+				t.Error("expected missing account to fail")
+			}
+
+			store.Commits.Add(alice.ID, "alice/x", "sha1", "msg", time.Now())
+			if err := store.Accounts.Remove("alice"); err != nil {
+				t.Fatalf("failed to remove account: %v", err)
+			}
+			if store.Accounts.Exists("alice") || store.Accounts.Count() != 1 {
+				t.Error("expected account to be removed")
+			}
+			commits, _ := store.Commits.GetAllSince(time.Time{})
+			if len(commits) != 0 {
+				t.Errorf("expected removed account's commits to be deleted, got %d", len(commits))
+			}
+		})
+	}
+}
+
+func TestActivity(t *testing.T) {
+	now := time.Now()
+	week := now.AddDate(0, 0, -7)
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			alice, _ := store.Accounts.Add("alice", "", "", "")
+			bob, _ := store.Accounts.Add("bob", "", "", "")
+
+			store.Commits.Add(alice.ID, "alice/a", "a1", "one", now.Add(-time.Hour))
+			store.Commits.Add(alice.ID, "alice/b", "a2", "two", now.Add(-2*time.Hour))
+			store.Commits.Add(bob.ID, "bob/a", "b1", "old", now.AddDate(0, 0, -30))
+			if inserted, _ := store.Commits.Add(alice.ID, "alice/a", "a1", "one", now); inserted {
+				t.Error("expected duplicate commit to be ignored")
+			}
+
+			counts, _ := store.Commits.CountByAccount(week)
+			if counts[alice.ID] != 2 || counts[bob.ID] != 0 {
+				t.Errorf("unexpected commit counts: %v", counts)
+			}
+			commits, _ := store.Commits.GetForAccount(alice.ID, week)
+			if len(commits) != 2 || commits[0].SHA != "a1" {
+				t.Errorf("expected alice's commits newest first, got %+v", commits)
+			}
+			activity, _ := store.Commits.GetUserActivity(week, 5)
+			if len(activity) != 1 || activity[0].Username != "alice" || activity[0].Count != 2 || len(activity[0].Repos) != 2 {
+				t.Errorf("unexpected user activity: %+v", activity)
+			}
+
+			store.Repos.Add(bob.ID, "new", "bob/new", "", "Go", 1, now.Add(-time.Hour))
+			store.Repos.Add(bob.ID, "old", "bob/old", "", "Go", 1, now.AddDate(-1, 0, 0))
+			repos, _ := store.Repos.GetNewSince(week)
+			if len(repos) != 1 || repos[0].FullName != "bob/new" {
+				t.Errorf("expected only the new repo, got %+v", repos)
+			}
+			if repos, _ := store.Repos.GetForAccount(alice.ID, week); len(repos) != 0 {
+				t.Errorf("expected no repos for alice, got %+v", repos)
+			}
+
+			store.Stars.Add(alice.ID, "golang/go", "", "Go", 100, now.Add(-time.Hour))
+			store.Stars.Add(bob.ID, "golang/go", "", "Go", 100, now.Add(-2*time.Hour))
+			store.Stars.Add(bob.ID, "rust-lang/rust", "", "Rust", 100, now.Add(-3*time.Hour))
+			trending, _ := store.Stars.GetTrendingRepos(week, 2)
+			if len(trending) != 1 || trending[0].RepoFullName != "golang/go" || trending[0].StarCount != 2 {
+				t.Errorf("unexpected trending repos: %+v", trending)
+			}
+			starCounts, _ := store.Stars.CountByAccount(week)
+			if starCounts[bob.ID] != 2 {
+				t.Errorf("expected 2 stars for bob, got %d", starCounts[bob.ID])
+			}
+
+			// Excluded accounts are left out of the aggregates
+			if counts, _ := store.Commits.CountByAccount(week, alice.ID); len(counts) != 0 {
+				t.Errorf("expected alice excluded, got %v", counts)
+			}
+			if activity, _ := store.Commits.GetUserActivity(week, 5, alice.ID); len(activity) != 0 {
+				t.Errorf("expected alice excluded, got %+v", activity)
+			}
+			if counts, _ := store.Repos.CountByAccount(week, bob.ID); len(counts) != 0 {
+				t.Errorf("expected bob excluded, got %v", counts)
+			}
+			if trending, _ := store.Stars.GetTrendingRepos(week, 2, alice.ID); len(trending) != 0 {
+				t.Errorf("expected nothing trending without alice, got %+v", trending)
+			}
+			if counts, _ := store.Stars.CountByAccount(week, alice.ID); counts[alice.ID] != 0 || counts[bob.ID] != 2 {
+				t.Errorf("expected only bob's stars, got %v", counts)
+			}
+			stars, _ := store.Stars.GetForAccount(bob.ID, week)
+			if len(stars) != 2 || stars[0].RepoFullName != "golang/go" {
+				t.Errorf("expected bob's stars newest first, got %+v", stars)
+			}
+
+			// Several accounts at once
+			stars, _ = store.Stars.GetForAccounts([]int64{alice.ID, bob.ID}, week)
+			if len(stars) != 3 || stars[0].AccountID != alice.ID {
+				t.Errorf("expected alice's and bob's stars newest first, got %+v", stars)
+			}
+			if repos, _ := store.Repos.GetForAccounts([]int64{alice.ID, bob.ID}, week); len(repos) != 1 || repos[0].FullName != "bob/new" {
+				t.Errorf("expected bob's new repo, got %+v", repos)
+			}
+			if stars, _ := store.Stars.GetForAccounts(nil, week); len(stars) != 0 {
+				t.Errorf("expected no stars for no accounts, got %+v", stars)
+			}
+		})
+	}
+}
+
+func TestHumanCommits(t *testing.T) {
+	now := time.Now()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			alice, _ := store.Accounts.Add("alice", "", "", "")
+			bob, _ := store.Accounts.Add("bob", "", "", "")
+			store.Commits.Add(alice.ID, "alice/a", "a1", "Add feature", now.Add(-time.Hour))
+			store.Commits.Add(alice.ID, "alice/a", "a2", "Merge branch 'main'", now.Add(-time.Hour))
+			store.Commits.Add(bob.ID, "bob/a", "b1", "v1.2.3", now.AddDate(0, 0, -2))
+
+			human, _ := store.Commits.HumanCountByAccount(now.AddDate(0, 0, -7))
+			if human[alice.ID] != 1 || human[bob.ID] != 0 {
+				t.Errorf("unexpected human commit counts: %v", human)
+			}
+			if human, _ := store.Commits.HumanCountByAccount(now.AddDate(0, 0, -7), alice.ID); len(human) != 0 {
+				t.Errorf("expected alice excluded, got %v", human)
+			}
+			commits, _ := store.Commits.GetForAccount(alice.ID, now.AddDate(0, 0, -7))
+			if len(commits) != 2 || commits[0].Automated == commits[1].Automated {
+				t.Errorf("expected one commit flagged as a merge, got %+v", commits)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Package suggest recommends accounts to monitor from what the monitored
+// accounts star, commit to and follow
+package suggest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+	"github.com/julienpequegnot/ghmon/internal/analysis"
+)
+
+// Signals a suggestion can come from
+const (
+	SignalTrendingOwner = "trending_owner"
+	SignalCoCommitter   = "co_committer"
+	SignalFollowedBy    = "followed_by"
+)
+
+// Suggestion is an account worth monitoring, with the reasons why. Score is
+// the sum of the reasons' scores.
+type Suggestion struct {
+	Username string
+	Score    int
+	Reasons  []Reason
+}
+
+type Reason struct {
+	Signal string
+	Detail string
+	Score  int
+}
+
+// Contributor is a contributor to a repository monitored accounts commit to
+type Contributor struct {
+	Login         string
+	Contributions int
+}
+
+// Input is the data suggestions are drawn from
+type Input struct {
+	// Accounts are every known account, including removed ones; none of them
+	// is suggested
+	Accounts []account.Account
+	// Stars and Commits are the monitored accounts' recent activity
+	Stars   []activity.Star
+	Commits []activity.Commit
+	// Contributors are the contributors of some of the repositories in
+	// Commits, by repository name (see SharedRepos)
+	Contributors map[string][]Contributor
+	// Following is each monitored account's following snapshot
+	Following map[int64][]account.Follow
+}
+
+// Rank scores every account the signals point at, best first:
+//
+//   - the owner of a repository starred by two or more monitored accounts
+//     scores one per account that starred it
+//   - a contributor to a repository monitored accounts commit to scores one
+//     per monitored account committing there
+//   - an account followed by two or more monitored accounts scores one per
+//     follower, and one more per follower that started following it since
+//     ghmon's first snapshot
+func Rank(in Input) []Suggestion {
+	known := make(map[string]bool)
+	usernames := make(map[int64]string)
+	for _, a := range in.Accounts {
+		known[strings.ToLower(a.Username)] = true
+		if a.RemovedAt == nil {
+			usernames[a.ID] = a.Username
+		}
+	}
+
+	byLogin := make(map[string]*Suggestion)
+	add := func(login string, r Reason) {
+		key := strings.ToLower(login)
+		if known[key] || analysis.IsBot(login) || r.Score <= 0 {
+			return
+		}
+		s, ok := byLogin[key]
+		if !ok {
+			s = &Suggestion{Username: login}
+			byLogin[key] = s
+		}
+		s.Score += r.Score
+		s.Reasons = append(s.Reasons, r)
+	}
+
+	// Removed accounts' kept history doesn't count
+	monitored := func(accountID int64) bool { _, ok := usernames[accountID]; return ok }
+	stars := filter(in.Stars, func(s activity.Star) bool { return monitored(s.AccountID) })
+	commits := filter(in.Commits, func(c activity.Commit) bool { return monitored(c.AccountID) })
+
+	for _, repo := range distinctAccounts(stars, func(s activity.Star) (string, int64) { return s.RepoFullName, s.AccountID }) {
+		if len(repo.accounts) < 2 {
+			continue
+		}
+		owner, _, ok := strings.Cut(repo.name, "/")
+		if !ok {
+			continue
+		}
+		add(owner, Reason{
+			Signal: SignalTrendingOwner,
+			Detail: fmt.Sprintf("owns %s, starred by %s", repo.name, names(repo.accounts, usernames)),
+			Score:  len(repo.accounts),
+		})
+	}
+
+	for _, repo := range distinctAccounts(commits, func(c activity.Commit) (string, int64) { return c.RepoName, c.AccountID }) {
+		for _, c := range in.Contributors[repo.name] {
+			add(c.Login, Reason{
+				Signal: SignalCoCommitter,
+				Detail: fmt.Sprintf("%d contributions to %s, alongside %s", c.Contributions, repo.name, names(repo.accounts, usernames)),
+				Score:  len(repo.accounts),
+			})
+		}
+	}
+
+	type followedBy struct {
+		login     string
+		followers []int64
+		started   int
+	}
+	followed := make(map[string]*followedBy)
+	var order []string
+	for _, accountID := range sortedIDs(in.Following) {
+		if !monitored(accountID) {
+			continue
+		}
+		for _, f := range in.Following[accountID] {
+			key := strings.ToLower(f.Login)
+			fb, ok := followed[key]
+			if !ok {
+				fb = &followedBy{login: f.Login}
+				followed[key] = fb
+				order = append(order, key)
+			}
+			fb.followers = append(fb.followers, accountID)
+			if f.New {
+				fb.started++
+			}
+		}
+	}
+	for _, key := range order {
+		fb := followed[key]
+		if len(fb.followers) < 2 {
+			continue
+		}
+		detail := "followed by " + names(fb.followers, usernames)
+		if fb.started > 0 {
+			detail += fmt.Sprintf(" (%d started recently)", fb.started)
+		}
+		add(fb.login, Reason{Signal: SignalFollowedBy, Detail: detail, Score: len(fb.followers) + fb.started})
+	}
+
+	suggestions := make([]Suggestion, 0, len(byLogin))
+	for _, s := range byLogin {
+		sort.SliceStable(s.Reasons, func(i, j int) bool { return s.Reasons[i].Score > s.Reasons[j].Score })
+		suggestions = append(suggestions, *s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return strings.ToLower(suggestions[i].Username) < strings.ToLower(suggestions[j].Username)
+	})
+	return suggestions
+}
+
+// SharedRepos picks up to limit repositories from commits whose contributors
+// are worth looking up: the ones the most monitored accounts commit to, then
+// the ones with the most commits
+func SharedRepos(commits []activity.Commit, limit int) []string {
+	counts := make(map[string]int)
+	for _, c := range commits {
+		counts[c.RepoName]++
+	}
+	repos := distinctAccounts(commits, func(c activity.Commit) (string, int64) { return c.RepoName, c.AccountID })
+	sort.SliceStable(repos, func(i, j int) bool {
+		if len(repos[i].accounts) != len(repos[j].accounts) {
+			return len(repos[i].accounts) > len(repos[j].accounts)
+		}
+		return counts[repos[i].name] > counts[repos[j].name]
+	})
+
+	var names []string
+	for _, r := range repos {
+		if len(names) == limit {
+			break
+		}
+		names = append(names, r.name)
+	}
+	return names
+}
+
+type repoAccounts struct {
+	name     string
+	accounts []int64
+}
+
+// distinctAccounts groups items by repository, listing each account once,
+// in the order repositories first appear
+func distinctAccounts[T any](items []T, key func(T) (string, int64)) []repoAccounts {
+	index := make(map[string]int)
+	seen := make(map[string]map[int64]bool)
+	var repos []repoAccounts
+	for _, item := range items {
+		name, accountID := key(item)
+		i, ok := index[name]
+		if !ok {
+			i = len(repos)
+			index[name] = i
+			seen[name] = make(map[int64]bool)
+			repos = append(repos, repoAccounts{name: name})
+		}
+		if !seen[name][accountID] {
+			seen[name][accountID] = true
+			repos[i].accounts = append(repos[i].accounts, accountID)
+		}
+	}
+	return repos
+}
+
+func filter[T any](items []T, keep func(T) bool) []T {
+	var out []T
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func sortedIDs(following map[int64][]account.Follow) []int64 {
+	ids := make([]int64, 0, len(following))
+	for id := range following {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// names lists up to three accounts by username, e.g. "rsc, robpike and 2 more"
+func names(ids []int64, usernames map[int64]string) string {
+	var list []string
+	for _, id := range ids {
+		if u := usernames[id]; u != "" {
+			list = append(list, u)
+		}
+	}
+	sort.Strings(list)
+	switch {
+	case len(list) > 3:
+		return fmt.Sprintf("%s and %d more", strings.Join(list[:3], ", "), len(list)-3)
+	case len(list) > 1:
+		return strings.Join(list[:len(list)-1], ", ") + " and " + list[len(list)-1]
+	}
+	return strings.Join(list, "")
+}
@@ -0,0 +1,91 @@
+package suggest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/account"
+	"github.com/julienpequegnot/ghmon/internal/activity"
+)
+
+func TestRank(t *testing.T) {
+	removed := time.Now()
+	in := Input{
+		Accounts: []account.Account{
+			{ID: 1, Username: "rsc"},
+			{ID: 2, Username: "bradfitz"},
+			{ID: 3, Username: "karpathy"},
+			{ID: 4, Username: "oldfriend", RemovedAt: &removed},
+		},
+		Stars: []activity.Star{
+			{AccountID: 1, RepoFullName: "tailscale/tailscale"},
+			{AccountID: 2, RepoFullName: "tailscale/tailscale"},
+			{AccountID: 2, RepoFullName: "tailscale/tailscale"},
+			{AccountID: 3, RepoFullName: "ggerganov/llama.cpp"},
+			{AccountID: 4, RepoFullName: "ggerganov/llama.cpp"},
+			{AccountID: 3, RepoFullName: "rsc/quote"},
+			{AccountID: 2, RepoFullName: "rsc/quote"},
+		},
+		Commits: []activity.Commit{
+			{AccountID: 1, RepoName: "golang/go"},
+			{AccountID: 2, RepoName: "golang/go"},
+			{AccountID: 3, RepoName: "karpathy/nanoGPT"},
+		},
+		Contributors: map[string][]Contributor{
+			"golang/go":        {{Login: "rsc", Contributions: 5000}, {Login: "ianlancetaylor", Contributions: 4000}, {Login: "dependabot[bot]", Contributions: 10}},
+			"karpathy/nanoGPT": {{Login: "ianlancetaylor", Contributions: 1}},
+		},
+		Following: map[int64][]account.Follow{
+			1: {{Login: "robpike"}, {Login: "IanLanceTaylor", New: true}},
+			2: {{Login: "robpike", New: true}, {Login: "ianlancetaylor"}},
+			3: {{Login: "robpike"}, {Login: "oldfriend"}},
+			4: {{Login: "ianlancetaylor"}},
+		},
+	}
+
+	suggestions := Rank(in)
+	scores := make(map[string]int)
+	for _, s := range suggestions {
+		scores[s.Username] = s.Score
+	}
+
+	// golang/go (2) + nanoGPT (1) + followed by 2, one recently (3)
+	if suggestions[0].Username != "ianlancetaylor" || suggestions[0].Score != 6 {
+		t.Errorf("expected ianlancetaylor first with 6, got %+v", suggestions[0])
+	}
+	if len(suggestions[0].Reasons) != 3 || suggestions[0].Reasons[0].Signal != SignalFollowedBy {
+		t.Errorf("expected 3 reasons, strongest first, got %+v", suggestions[0].Reasons)
+	}
+	if scores["robpike"] != 4 {
+		t.Errorf("expected robpike followed by 3, one recently, got %d", scores["robpike"])
+	}
+	if scores["tailscale"] != 2 {
+		t.Errorf("expected tailscale starred by 2 accounts, got %d", scores["tailscale"])
+	}
+	if !strings.Contains(suggestions[0].Reasons[1].Detail, "alongside bradfitz and rsc") {
+		t.Errorf("expected the co-committers to be named, got %q", suggestions[0].Reasons[1].Detail)
+	}
+
+	for _, login := range []string{"rsc", "oldfriend", "ggerganov", "dependabot[bot]"} {
+		if _, ok := scores[login]; ok {
+			t.Errorf("expected %s not to be suggested", login)
+		}
+	}
+}
+
+func TestSharedRepos(t *testing.T) {
+	commits := []activity.Commit{
+		{AccountID: 1, RepoName: "rsc/quote"},
+		{AccountID: 1, RepoName: "rsc/quote"},
+		{AccountID: 1, RepoName: "rsc/quote"},
+		{AccountID: 1, RepoName: "golang/go"},
+		{AccountID: 2, RepoName: "golang/go"},
+		{AccountID: 2, RepoName: "bradfitz/gitbrute"},
+	}
+
+	repos := SharedRepos(commits, 2)
+	if len(repos) != 2 || repos[0] != "golang/go" || repos[1] != "rsc/quote" {
+		t.Errorf("expected golang/go then rsc/quote, got %v", repos)
+	}
+}
@@ -0,0 +1,331 @@
+// Package watch stores repositories followed regardless of who contributes
+// to them, along with their events and star counts.
+package watch
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/github"
+)
+
+// Event types stored for watched repositories
+const (
+	EventPush        = "push"
+	EventRelease     = "release"
+	EventPullRequest = "pull_request"
+	EventIssue       = "issue"
+)
+
+var fullNamePattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?/[A-Za-z0-9._-]+$`)
+
+// Repo is a watched repository
+type Repo struct {
+	ID          int64
+	FullName    string
+	Description string
+	Language    string
+	Stars       int
+	AddedAt     time.Time
+	LastFetched *time.Time
+}
+
+// Event is one event in a watched repository. Action is "opened", "merged"
+// or "closed" for pull requests and issues and "published" for releases;
+// Title is the release tag or the pull request or issue title.
+type Event struct {
+	EventID   string
+	Type      string
+	Action    string
+	Actor     string
+	Number    int
+	Title     string
+	Commits   int
+	CreatedAt time.Time
+}
+
+// Summary is a watched repository's activity over a period
+type Summary struct {
+	Repo               Repo
+	Pushes             int
+	Commits            int
+	Releases           []string
+	PullRequestsOpened int
+	PullRequestsMerged int
+	IssuesOpened       int
+	StarsGained        int
+}
+
+// ParseFullName checks a repository is given as owner/repo, also accepting
+// a github.com URL
+func ParseFullName(s string) (string, error) {
+	name := strings.TrimSpace(s)
+	for _, prefix := range []string{"https://", "http://", "github.com/", "www.github.com/"} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	if !fullNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid repository '%s' (expected owner/repo)", s)
+	}
+	return name, nil
+}
+
+// EventFromGitHub converts a repository event into the event stored for a
+// watched repository. Events that digests don't report, such as comments or
+// pull requests being edited, are skipped.
+func EventFromGitHub(e github.Event) (Event, bool) {
+	ev := Event{EventID: e.ID, Actor: e.Actor.Login, CreatedAt: e.CreatedAt}
+
+	switch e.Type {
+	case "PushEvent":
+		payload, err := github.ParsePushPayload(e.Payload)
+		if err != nil {
+			return ev, false
+		}
+		ev.Type = EventPush
+		ev.Commits = len(payload.Commits)
+	case "ReleaseEvent":
+		payload, err := github.ParseReleasePayload(e.Payload)
+		if err != nil || payload.Action != "published" {
+			return ev, false
+		}
+		ev.Type, ev.Action, ev.Title = EventRelease, payload.Action, payload.Release.TagName
+	case "PullRequestEvent":
+		payload, err := github.ParsePullRequestPayload(e.Payload)
+		if err != nil {
+			return ev, false
+		}
+		ev.Type, ev.Action = EventPullRequest, payload.Action
+		ev.Number, ev.Title = payload.Number, payload.PullRequest.Title
+		if payload.Action == "closed" && payload.PullRequest.Merged {
+			ev.Action = "merged"
+		}
+		if ev.Action != "opened" && ev.Action != "merged" && ev.Action != "closed" {
+			return ev, false
+		}
+	case "IssuesEvent":
+		payload, err := github.ParseIssuesPayload(e.Payload)
+		if err != nil || (payload.Action != "opened" && payload.Action != "closed") {
+			return ev, false
+		}
+		ev.Type, ev.Action = EventIssue, payload.Action
+		ev.Number, ev.Title = payload.Issue.Number, payload.Issue.Title
+	default:
+		return ev, false
+	}
+	return ev, true
+}
+
+type Repository struct {
+	db database.Executor
+}
+
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// WithTx returns a repository that reads and writes through tx
+func (r *Repository) WithTx(tx *database.Tx) *Repository {
+	return &Repository{db: tx}
+}
+
+// Add starts watching a repository
+func (r *Repository) Add(fullName, description, language string, stars int) (*Repo, error) {
+	now := time.Now()
+	result, err := r.db.Exec(
+		`INSERT INTO watched_repos (full_name, description, language, stars, added_at) VALUES (?, ?, ?, ?, ?)`,
+		fullName, description, language, stars, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("repository already watched or error: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	return &Repo{ID: id, FullName: fullName, Description: description, Language: language, Stars: stars, AddedAt: now}, nil
+}
+
+// Remove stops watching a repository and deletes its events and star history
+func (r *Repository) Remove(fullName string) error {
+	repo, err := r.Get(fullName)
+	if err != nil {
+		return fmt.Errorf("repository not watched: %w", err)
+	}
+
+	for _, query := range []string{
+		"DELETE FROM watched_repo_events WHERE repo_id = ?",
+		"DELETE FROM watched_repo_stars WHERE repo_id = ?",
+		"DELETE FROM watched_repos WHERE id = ?",
+	} {
+		if _, err := r.db.Exec(query, repo.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const repoColumns = `id, full_name, description, language, stars, added_at, last_fetched`
+
+// Get returns a watched repository by its full name, ignoring case
+func (r *Repository) Get(fullName string) (*Repo, error) {
+	return scanRepo(r.db.QueryRow(`SELECT `+repoColumns+` FROM watched_repos WHERE full_name = ?`, fullName))
+}
+
+// Exists reports whether a repository is watched
+func (r *Repository) Exists(fullName string) bool {
+	var n int
+	r.db.QueryRow("SELECT COUNT(*) FROM watched_repos WHERE full_name = ?", fullName).Scan(&n)
+	return n > 0
+}
+
+// List returns the watched repositories, ordered by full name
+func (r *Repository) List() ([]Repo, error) {
+	rows, err := r.db.Query(`SELECT ` + repoColumns + ` FROM watched_repos ORDER BY full_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []Repo
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, *repo)
+	}
+	return repos, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRepo(s scanner) (*Repo, error) {
+	var repo Repo
+	var lastFetched sql.NullTime
+	if err := s.Scan(&repo.ID, &repo.FullName, &repo.Description, &repo.Language, &repo.Stars, &repo.AddedAt, &lastFetched); err != nil {
+		return nil, err
+	}
+	if lastFetched.Valid {
+		repo.LastFetched = &lastFetched.Time
+	}
+	return &repo, nil
+}
+
+// AddEvent stores an event and reports whether it was new; events already
+// stored are ignored
+func (r *Repository) AddEvent(repoID int64, e Event) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT OR IGNORE INTO watched_repo_events
+			(repo_id, event_id, type, action, actor, number, title, commits, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, repoID, e.EventID, e.Type, e.Action, e.Actor, e.Number, e.Title, e.Commits, e.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// UpdateRepo records a repository's current details and star count, keeping
+// the count so star growth can be reported for any period
+func (r *Repository) UpdateRepo(repoID int64, description, language string, stars int, at time.Time) error {
+	if _, err := r.db.Exec(
+		`UPDATE watched_repos SET description = ?, language = ?, stars = ? WHERE id = ?`,
+		description, language, stars, repoID,
+	); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO watched_repo_stars (repo_id, recorded_at, stars) VALUES (?, ?, ?)`,
+		repoID, at, stars,
+	)
+	return err
+}
+
+// UpdateLastFetched records that a repository was fetched
+func (r *Repository) UpdateLastFetched(repoID int64) error {
+	_, err := r.db.Exec(`UPDATE watched_repos SET last_fetched = ? WHERE id = ?`, time.Now(), repoID)
+	return err
+}
+
+// Summarize returns every watched repository's activity since a time,
+// ordered by full name, counting only the events keep accepts (all of them
+// when keep is nil). Stars gained compares the current count with the last
+// count recorded before the period, or the first one recorded in it.
+func (r *Repository) Summarize(since time.Time, keep func(Event) bool) ([]Summary, error) {
+	repos, err := r.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watched repositories: %w", err)
+	}
+
+	summaries := make([]Summary, len(repos))
+	index := make(map[int64]*Summary)
+	for i, repo := range repos {
+		summaries[i] = Summary{Repo: repo, Releases: []string{}}
+		index[repo.ID] = &summaries[i]
+	}
+
+	rows, err := r.db.Query(`
+		SELECT repo_id, type, action, actor, title, commits
+		FROM watched_repo_events
+		WHERE created_at >= ?
+		ORDER BY created_at DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watched repository events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var repoID int64
+		var e Event
+		if err := rows.Scan(&repoID, &e.Type, &e.Action, &e.Actor, &e.Title, &e.Commits); err != nil {
+			return nil, err
+		}
+		s, ok := index[repoID]
+		if !ok || (keep != nil && !keep(e)) {
+			continue
+		}
+		switch {
+		case e.Type == EventPush:
+			s.Pushes++
+			s.Commits += e.Commits
+		case e.Type == EventRelease:
+			s.Releases = append(s.Releases, e.Title)
+		case e.Type == EventPullRequest && e.Action == "opened":
+			s.PullRequestsOpened++
+		case e.Type == EventPullRequest && e.Action == "merged":
+			s.PullRequestsMerged++
+		case e.Type == EventIssue && e.Action == "opened":
+			s.IssuesOpened++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range summaries {
+		s := &summaries[i]
+		var baseline sql.NullInt64
+		err := r.db.QueryRow(`
+			SELECT COALESCE(
+				(SELECT stars FROM watched_repo_stars WHERE repo_id = ? AND recorded_at < ? ORDER BY recorded_at DESC LIMIT 1),
+				(SELECT stars FROM watched_repo_stars WHERE repo_id = ? AND recorded_at >= ? ORDER BY recorded_at LIMIT 1)
+			)
+		`, s.Repo.ID, since, s.Repo.ID, since).Scan(&baseline)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query star history: %w", err)
+		}
+		if baseline.Valid {
+			s.StarsGained = s.Repo.Stars - int(baseline.Int64)
+		}
+	}
+
+	return summaries, nil
+}
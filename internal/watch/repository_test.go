@@ -0,0 +1,154 @@
+package watch
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienpequegnot/ghmon/internal/database"
+	"github.com/julienpequegnot/ghmon/internal/github"
+)
+
+func setupTestDB(t *testing.T) *database.DB {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestParseFullName(t *testing.T) {
+	tests := map[string]string{
+		"golang/go":                      "golang/go",
+		" golang/go ":                    "golang/go",
+		"https://github.com/golang/go":   "golang/go",
+		"github.com/golang/go.git":       "golang/go",
+		"https://github.com/golang/go/":  "golang/go",
+		"charmbracelet/bubbletea-app.v2": "charmbracelet/bubbletea-app.v2",
+	}
+	for in, want := range tests {
+		got, err := ParseFullName(in)
+		if err != nil || got != want {
+			t.Errorf("ParseFullName(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"golang", "golang/go/issues", "-bad/repo", ""} {
+		if _, err := ParseFullName(in); err == nil {
+			t.Errorf("expected %q to be rejected", in)
+		}
+	}
+}
+
+func TestEventFromGitHub(t *testing.T) {
+	event := func(typ, payload string) github.Event {
+		return github.Event{ID: "1", Type: typ, Actor: github.EventActor{Login: "rsc"}, Payload: json.RawMessage(payload)}
+	}
+
+	tests := []struct {
+		event  github.Event
+		ok     bool
+		typ    string
+		action string
+	}{
+		{event("PushEvent", `{"commits": [{"sha": "a"}, {"sha": "b"}]}`), true, EventPush, ""},
+		{event("ReleaseEvent", `{"action": "published", "release": {"tag_name": "v1.0"}}`), true, EventRelease, "published"},
+		{event("ReleaseEvent", `{"action": "edited", "release": {"tag_name": "v1.0"}}`), false, "", ""},
+		{event("PullRequestEvent", `{"action": "opened", "number": 7, "pull_request": {"title": "Fix"}}`), true, EventPullRequest, "opened"},
+		{event("PullRequestEvent", `{"action": "closed", "number": 7, "pull_request": {"merged": true}}`), true, EventPullRequest, "merged"},
+		{event("PullRequestEvent", `{"action": "synchronize", "number": 7}`), false, "", ""},
+		{event("IssuesEvent", `{"action": "opened", "issue": {"number": 3, "title": "Bug"}}`), true, EventIssue, "opened"},
+		{event("WatchEvent", `{"action": "started"}`), false, "", ""},
+	}
+	for _, tt := range tests {
+		got, ok := EventFromGitHub(tt.event)
+		if ok != tt.ok {
+			t.Errorf("%s %s: expected ok=%v", tt.event.Type, tt.event.Payload, tt.ok)
+			continue
+		}
+		if ok && (got.Type != tt.typ || got.Action != tt.action || got.Actor != "rsc") {
+			t.Errorf("%s %s: got %+v", tt.event.Type, tt.event.Payload, got)
+		}
+	}
+
+	push, _ := EventFromGitHub(tests[0].event)
+	if push.Commits != 2 {
+		t.Errorf("expected 2 commits, got %d", push.Commits)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRepository(db)
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -7)
+
+	goRepo, err := repo.Add("golang/go", "The Go programming language", "Go", 120000)
+	if err != nil {
+		t.Fatalf("failed to watch repository: %v", err)
+	}
+	quiet, _ := repo.Add("rsc/quiet", "", "", 10)
+	if _, err := repo.Add("GOLANG/go", "", "", 0); err == nil {
+		t.Error("expected watching the same repository twice to fail")
+	}
+
+	// Star counts: 119900 before the period, 120000 now
+	repo.UpdateRepo(goRepo.ID, "The Go programming language", "Go", 119900, now.AddDate(0, 0, -10))
+	repo.UpdateRepo(goRepo.ID, "The Go programming language", "Go", 120000, now)
+	repo.UpdateRepo(quiet.ID, "", "", 10, now)
+
+	events := []Event{
+		{EventID: "1", Type: EventPush, Commits: 3, CreatedAt: now.Add(-time.Hour)},
+		{EventID: "2", Type: EventPush, Commits: 1, CreatedAt: now.Add(-2 * time.Hour)},
+		{EventID: "3", Type: EventRelease, Action: "published", Title: "go1.30", CreatedAt: now.Add(-3 * time.Hour)},
+		{EventID: "4", Type: EventPullRequest, Action: "opened", Number: 1, CreatedAt: now.Add(-4 * time.Hour)},
+		{EventID: "5", Type: EventPullRequest, Action: "merged", Number: 1, CreatedAt: now.Add(-5 * time.Hour)},
+		{EventID: "6", Type: EventIssue, Action: "opened", Number: 2, CreatedAt: now.Add(-6 * time.Hour)},
+		// Before the period
+		{EventID: "7", Type: EventPush, Commits: 50, CreatedAt: now.AddDate(0, 0, -8)},
+	}
+	for _, e := range events {
+		if _, err := repo.AddEvent(goRepo.ID, e); err != nil {
+			t.Fatalf("failed to add event: %v", err)
+		}
+	}
+	if added, _ := repo.AddEvent(goRepo.ID, events[0]); added {
+		t.Error("expected a duplicate event to be ignored")
+	}
+
+	summaries, err := repo.Summarize(since, nil)
+	if err != nil {
+		t.Fatalf("failed to summarize: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 summaries, got %d", len(summaries))
+	}
+
+	s := summaries[0]
+	if s.Repo.FullName != "golang/go" || s.Pushes != 2 || s.Commits != 4 {
+		t.Errorf("unexpected pushes: %+v", s)
+	}
+	if len(s.Releases) != 1 || s.Releases[0] != "go1.30" {
+		t.Errorf("unexpected releases: %v", s.Releases)
+	}
+	if s.PullRequestsOpened != 1 || s.PullRequestsMerged != 1 || s.IssuesOpened != 1 {
+		t.Errorf("unexpected pull requests or issues: %+v", s)
+	}
+	if s.StarsGained != 100 {
+		t.Errorf("expected 100 stars gained, got %d", s.StarsGained)
+	}
+
+	if q := summaries[1]; q.Pushes != 0 || q.StarsGained != 0 || q.Releases == nil {
+		t.Errorf("expected an empty summary for the quiet repository, got %+v", q)
+	}
+
+	if err := repo.Remove("golang/go"); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	if repo.Exists("golang/go") {
+		t.Error("expected repository to be removed")
+	}
+}